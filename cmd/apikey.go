@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"github.com/urfave/cli/v2"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func apiKeyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "apikey",
+		Usage: "Manage partner API keys",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create an API key and print it once",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Required: true, Usage: "partner name"},
+					&cli.IntFlag{Name: "quota", Value: 0, Usage: "daily request quota, 0 is unlimited"},
+				},
+				Action: withAPIKeyService(func(c *cli.Context, keys service.APIKeyService) error {
+					key, plain, err := keys.CreateAPIKey(c.Context, c.String("name"), c.Int("quota"))
+					if err != nil {
+						return err
+					}
+					fmt.Printf("id:    %d\nname:  %s\nquota: %d\nkey:   %s\n", key.ID, key.Name, key.DailyQuota, plain)
+					return nil
+				}),
+			},
+			{
+				Name:  "list",
+				Usage: "List API keys with today's usage",
+				Action: withAPIKeyService(func(c *cli.Context, keys service.APIKeyService) error {
+					list, err := keys.ListAPIKeys(c.Context)
+					if err != nil {
+						return err
+					}
+
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "ID\tNAME\tPREFIX\tQUOTA\tTODAY\tLAST USED\tREVOKED")
+					for _, k := range list {
+						fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%s\n",
+							k.ID, k.Name, k.Prefix, k.DailyQuota, k.RequestsToday, formatTime(k.LastUsedAt), formatTime(k.RevokedAt))
+					}
+					return w.Flush()
+				}),
+			},
+			{
+				Name:  "revoke",
+				Usage: "Revoke an API key",
+				Flags: []cli.Flag{
+					&cli.Int64Flag{Name: "id", Required: true, Usage: "API key ID"},
+				},
+				Action: withAPIKeyService(func(c *cli.Context, keys service.APIKeyService) error {
+					if err := keys.RevokeAPIKey(c.Context, c.Int64("id")); err != nil {
+						return err
+					}
+					fmt.Printf("API key %d revoked\n", c.Int64("id"))
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func withAPIKeyService(action func(c *cli.Context, keys service.APIKeyService) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		ctx := context.Background()
+
+		cfg, err := configs.Load(c.String("config"))
+		if err != nil {
+			return err
+		}
+
+		logger, _, _, err := newLogger(cfg)
+		if err != nil {
+			return err
+		}
+		defer logger.Sync()
+
+		stores, err := openStorages(ctx, cfg.DB, logger)
+		if err != nil {
+			return err
+		}
+		defer stores.close()
+
+		return action(c, service.NewAPIKeyService(stores.APIKeys))
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
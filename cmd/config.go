@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Inspect the configuration",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "print",
+				Usage: "Validate and print the effective configuration as environment variables",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "show-secrets", Usage: "print secrets instead of [REDACTED]"},
+				},
+				Action: func(c *cli.Context) error {
+					cfg, err := configs.Load(c.String("config"))
+					if err != nil {
+						return err
+					}
+					return cfg.Print(os.Stdout, c.Bool("show-secrets"))
+				},
+			},
+		},
+	}
+}
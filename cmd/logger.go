@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// newLogger builds the logger configured by cfg.Log. The returned redactor
+// masks the secrets of cfg and can be given rotated ones.
+func newLogger(cfg *configs.Config) (*zap.Logger, zap.AtomicLevel, *logging.Redactor, error) {
+	redactor := logging.NewRedactor(cfg.Secrets()...)
+	logger, level, err := logging.NewLogger(logging.Options{
+		Level:              cfg.Log.Level,
+		Format:             cfg.Log.Format,
+		SamplingInitial:    cfg.Log.SamplingInitial,
+		SamplingThereafter: cfg.Log.SamplingThereafter,
+	}, redactor)
+	return logger, level, redactor, err
+}
@@ -2,20 +2,34 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	_ "github.com/lib/pq"
 	_ "github.com/serjnester/weather-subscription-service/docs"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/internal/bot"
+	"github.com/serjnester/weather-subscription-service/internal/certs"
+	"github.com/serjnester/weather-subscription-service/internal/clients/email"
+	"github.com/serjnester/weather-subscription-service/internal/clients/telegram"
 	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/clients/webhook"
 
 	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/dispatcher"
 	"github.com/serjnester/weather-subscription-service/internal/handlers"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
+	"github.com/serjnester/weather-subscription-service/internal/rpc"
+	"github.com/serjnester/weather-subscription-service/internal/scheduler"
 	"github.com/serjnester/weather-subscription-service/internal/service"
-	"github.com/serjnester/weather-subscription-service/internal/storage"
-	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	"github.com/serjnester/weather-subscription-service/internal/tokens"
+	"github.com/serjnester/weather-subscription-service/internal/tracing"
 	"go.uber.org/zap"
 	"log"
-	"net/http"
+	"net/mail"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 )
@@ -24,38 +38,198 @@ func main() {
 	app := &cli.App{
 		Name:  "serve",
 		Usage: "Weather Service",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				EnvVars: []string{"CONFIG_FILE"},
+				Usage:   "YAML or TOML config file; environment variables take precedence",
+			},
+		},
+		Commands: []*cli.Command{
+			apiKeyCommand(),
+			configCommand(),
+		},
 		Action: func(c *cli.Context) error {
-			ctx := context.Background()
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
 
-			cfg, err := configs.Load()
+			cfg, err := configs.Load(c.String("config"))
 			if err != nil {
 				log.Fatalf("config load error: %v", err)
 			}
 
-			logger, err := logging.NewLogger()
+			logger, logLevel, redactor, err := newLogger(cfg)
 			if err != nil {
 				log.Fatalf("logger init error: %v", err)
 			}
 			defer logger.Sync()
-			dbConn, err := storage.NewDBConn(ctx, cfg.DB, logger)
+			zap.ReplaceGlobals(logger)
+
+			shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+			if err != nil {
+				logger.Fatal("tracing init error", zap.Error(err))
+			}
+			defer func() {
+				// ctx is already cancelled here; flush with a fresh deadline.
+				flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+				defer cancel()
+				if err := shutdownTracing(flushCtx); err != nil {
+					logger.Error("tracing shutdown error", zap.Error(err))
+				}
+			}()
+
+			stores, err := openStorages(ctx, cfg.DB, logger)
 			if err != nil {
 				logger.Fatal("db init error", zap.Error(err))
 			}
-			defer dbConn.Close()
+			defer stores.close()
+
+			tokenManager, err := tokens.NewManager(cfg.Tokens)
+			if err != nil {
+				logger.Fatal("tokens init error", zap.Error(err))
+			}
 
 			weatherClient := weatherapi.NewClient(cfg.WeatherAPI.BaseURL, cfg.WeatherAPI.Key)
-			subscriptionStorage := storage.NewSubscriptionStorage(storage.New(dbConn))
-			weatherService := service.NewService(subscriptionStorage, weatherClient)
 
-			router := handlers.NewRouter(handlers.RouterParams{Config: *cfg})
+			// Secrets read from *_FILE variables are handed to the
+			// components using them when the files change.
+			secretWatcher := configs.NewSecretWatcher(cfg.SecretFiles(), cfg.SecretReloadInterval, logger)
+			secretWatcher.OnChange("DB_PASSWORD", stores.setPassword)
+			secretWatcher.OnChange("WEATHER_API_KEY", weatherClient.SetKey)
+
+			suppressionService := service.NewSuppressionService(stores.Suppressions)
+			webhookClient := webhook.NewClient(cfg.Webhook.Timeout, cfg.Webhook.AllowPrivate)
+			channels := notification.NewChannels(
+				notification.NewWebhookChannel(
+					webhookClient,
+					stores.WebhookAttempts,
+					notification.RetryPolicy{
+						MaxAttempts:    cfg.Webhook.MaxAttempts,
+						InitialBackoff: cfg.Webhook.InitialBackoff,
+						MaxBackoff:     cfg.Webhook.MaxBackoff,
+					},
+					logger,
+				),
+			)
+
+			var emailChannel notification.Channel
+			if cfg.Email.SMTPHost != "" {
+				from, err := mail.ParseAddress(cfg.Email.From)
+				if err != nil {
+					logger.Fatal("invalid email sender address", zap.Error(err))
+				}
+				emailClient := email.NewClient(cfg.Email.SMTPHost, cfg.Email.SMTPPort, cfg.Email.SMTPUsername, cfg.Email.SMTPPassword, *from, cfg.Email.SMTPTimeout)
+				emailChannel = notification.NewEmailChannel(emailClient)
+				channels[enums.ChannelEmail] = emailChannel
+				secretWatcher.OnChange("EMAIL_SMTP_PASSWORD", emailClient.SetPassword)
+			}
+
+			renderer, err := notification.NewTemplateRenderer(cfg.Notification.TemplatesDir)
+			if err != nil {
+				logger.Fatal("notification templates init error", zap.Error(err))
+			}
+
+			weatherService := service.NewService(stores.Subscriptions, weatherClient,
+				service.WithWebhooks(webhookClient, cfg.Webhook.AllowInsecure),
+				service.WithPrivateWebhooks(cfg.Webhook.AllowPrivate),
+				service.WithDeliveries(stores.Deliveries),
+				service.WithSuppressions(stores.Suppressions),
+				service.WithTokens(tokenManager),
+				service.WithUnitOfWork(stores.uow),
+				service.WithEmail(emailChannel, renderer, cfg.Notification.BaseURL),
+			)
+			apiKeyService := service.NewAPIKeyService(stores.APIKeys)
+			weatherHub := service.NewWeatherHub(weatherClient, cfg.Stream.PollInterval, cfg.Stream.MaxCities, logger)
+
+			if cfg.Telegram.BotToken != "" {
+				telegramClient := telegram.NewClient(cfg.Telegram.APIURL, cfg.Telegram.BotToken)
+				channels[enums.ChannelTelegram] = notification.NewTelegramChannel(telegramClient)
+
+				telegramBot := bot.NewTelegramBot(telegramClient, weatherService, logger, cfg.Telegram.PollTimeout)
+				go func() {
+					if err := telegramBot.Run(ctx); err != nil {
+						logger.Error("telegram bot stopped", zap.Error(err))
+					}
+				}()
+			}
+
+			if cfg.Scheduler.Enabled {
+				weatherScheduler := scheduler.NewScheduler(
+					stores.Schedule,
+					dispatcher.NewDispatcher(dispatcher.Params{
+						Weather:      weatherClient,
+						Renderer:     renderer,
+						Channels:     channels,
+						Deliveries:   stores.Deliveries,
+						Suppressions: stores.Suppressions,
+						Tokens:       tokenManager,
+						Logger:       logger,
+						BaseURL:      cfg.Notification.BaseURL,
+						Concurrency:  cfg.Scheduler.Concurrency,
+					}),
+					logger,
+					cfg.Scheduler,
+				)
+				go func() {
+					if err := weatherScheduler.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+						logger.Error("scheduler stopped", zap.Error(err))
+					}
+				}()
+			}
+
+			if cfg.SecretReloadInterval > 0 {
+				secretWatcher.Prune()
+				if keys := secretWatcher.Keys(); len(keys) > 0 {
+					// Rotated secrets are masked in logs like the initial ones.
+					for _, key := range keys {
+						secretWatcher.OnChange(key, redactor.AddSecret)
+					}
+					go func() {
+						if err := secretWatcher.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+							logger.Error("secret watcher stopped", zap.Error(err))
+						}
+					}()
+				}
+			}
+
+			router := handlers.NewRouter(handlers.RouterParams{
+				Config:        *cfg,
+				APIKeys:       apiKeyService,
+				Notifications: renderer,
+				Logger:        logger,
+			})
 			handlers.RegisterHandlers(router, handlers.RegisterHandlersParams{
-				MainHandler: handlers.NewHandler(weatherService),
+				MainHandler:        handlers.NewHandler(weatherService),
+				V2Handler:          handlers.NewHandlerV2(weatherService),
+				V1Deprecation:      cfg.API,
+				StreamHandler:      handlers.NewStreamHandler(weatherHub, cfg.Stream.HeartbeatInterval),
+				FeedHandler:        handlers.NewFeedHandler(weatherService, cfg.Feed.Days, cfg.Feed.MaxAge, cfg.Notification.BaseURL),
+				EmailEventsHandler: handlers.NewEmailEventsHandler(suppressionService),
+				EmailEventsToken:   cfg.Email.EventsToken,
+				AdminHandler:       handlers.NewAdminHandler(apiKeyService, service.NewDeliveryService(stores.Deliveries), suppressionService, logLevel),
+				AdminToken:         cfg.Auth.AdminToken,
 			})
 
-			addr := fmt.Sprintf(":%s", cfg.Port)
-			logger.Info("starting HTTP server", zap.String("addr", addr))
+			var tlsConfig *tls.Config
+			if cfg.HTTP.TLS() {
+				certReloader, err := certs.NewReloader(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile, logger)
+				if err != nil {
+					logger.Fatal("tls init error", zap.Error(err))
+				}
+				tlsConfig = certReloader.TLSConfig()
+			}
+
+			servers := []server{
+				newHTTPServer(fmt.Sprintf(":%s", cfg.Port), router, cfg.HTTP, tlsConfig),
+			}
+			if cfg.GRPC.Port != "" {
+				servers = append(servers, newGRPCServer(
+					fmt.Sprintf(":%s", cfg.GRPC.Port),
+					rpc.NewGRPCServer(weatherService, apiKeyService, cfg.Auth.AdminToken, logger, cfg.Env != configs.EnvTypeProd),
+				))
+			}
 
-			if err := http.ListenAndServe(addr, router); err != nil {
+			if err := serve(ctx, logger, cfg.ShutdownTimeout, servers...); err != nil {
 				logger.Fatal("server error", zap.Error(err))
 			}
 
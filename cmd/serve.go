@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// server is a listener that is started and stopped together with the
+// others in serve.
+type server struct {
+	name     string
+	addr     string
+	run      func() error
+	shutdown func(ctx context.Context) error
+}
+
+// newHTTPServer serves HTTPS when tlsConfig is set; its certificates come
+// from tlsConfig.GetCertificate.
+func newHTTPServer(addr string, handler http.Handler, cfg configs.HTTP, tlsConfig *tls.Config) server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	name := "http"
+	if tlsConfig != nil {
+		name = "https"
+	}
+	return server{
+		name: name,
+		addr: addr,
+		run: func() error {
+			var err error
+			if tlsConfig != nil {
+				err = srv.ListenAndServeTLS("", "")
+			} else {
+				err = srv.ListenAndServe()
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		shutdown: func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			if errors.Is(err, context.DeadlineExceeded) {
+				// Long-lived streams (SSE) do not finish on their own.
+				return srv.Close()
+			}
+			return err
+		},
+	}
+}
+
+func newGRPCServer(addr string, srv *grpc.Server) server {
+	return server{
+		name: "grpc",
+		addr: addr,
+		run: func() error {
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			return srv.Serve(lis)
+		},
+		shutdown: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// serve runs servers until ctx is done or one of them fails, then shuts
+// all of them down within timeout.
+func serve(ctx context.Context, logger *zap.Logger, timeout time.Duration, servers ...server) error {
+	errCh := make(chan error, len(servers))
+	for _, s := range servers {
+		logger.Info("starting server", zap.String("server", s.name), zap.String("addr", s.addr))
+		go func() {
+			if err := s.run(); err != nil {
+				errCh <- fmt.Errorf("%s server: %w", s.name, err)
+			}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	case runErr = <-errCh:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.shutdown(shutdownCtx); err != nil {
+				logger.Warn("server shutdown error", zap.String("server", s.name), zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	return runErr
+}
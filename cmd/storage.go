@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/serjnester/weather-subscription-service/internal/storage/sqlite"
+	"go.uber.org/zap"
+)
+
+// storages is the storage layer of the configured database driver.
+type storages struct {
+	storage.Stores
+	uow storage.UnitOfWork
+	// close releases the database.
+	close func() error
+	// setPassword hands a rotated DB_PASSWORD to new connections.
+	setPassword func(password string)
+}
+
+func openStorages(ctx context.Context, cfg configs.DB, logger *zap.Logger) (*storages, error) {
+	switch cfg.Driver {
+	case configs.DBDriverSQLite:
+		logger.Info("using sqlite database", zap.String("db_path", cfg.Path))
+		db, err := sqlite.Open(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		return &storages{
+			Stores:      sqlite.NewStores(sqlite.New(db)),
+			uow:         sqlite.NewUnitOfWork(db),
+			close:       db.Close,
+			setPassword: func(string) {},
+		}, nil
+	case configs.DBDriverMemory:
+		logger.Warn("using in-memory storage, data is lost on restart")
+		db := storage.NewMemoryDB()
+
+		return &storages{
+			Stores:      storage.NewMemoryStores(db),
+			uow:         storage.NewMemoryUnitOfWork(db),
+			close:       func() error { return nil },
+			setPassword: func(string) {},
+		}, nil
+	}
+
+	connector := storage.NewConnector(cfg)
+	db, err := storage.NewDBConn(ctx, connector, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storages{
+		Stores:      storage.NewStores(storage.New(db)),
+		uow:         storage.NewUnitOfWork(db),
+		close:       db.Close,
+		setPassword: connector.SetPassword,
+	}, nil
+}
@@ -15,9 +15,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/confirm/{token}": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Confirms a subscription using the token sent in the confirmation email.",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Lists partner API keys with today's usage. Key values are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Creates a partner API key. The plaintext key is returned only in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,105 +68,218 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "admin"
                 ],
-                "summary": "Confirm email subscription",
+                "summary": "Create API key",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Confirmation token",
-                        "name": "token",
+                        "description": "Key name and daily quota (0 is unlimited)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Subscription confirmed successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.MessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid token",
+                        "description": "Invalid ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Token not found",
+                        "description": "API key not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/subscribe": {
-            "post": {
-                "description": "Subscribe an email to receive weather updates for a specific city with chosen frequency.",
-                "consumes": [
-                    "application/x-www-form-urlencoded"
+        "/admin/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Lists scheduled deliveries, newest first. Pass the last returned id as before_id to get the next page.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "admin"
                 ],
-                "summary": "Subscribe to weather updates",
+                "summary": "List deliveries",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Email address to subscribe",
-                        "name": "email",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "City for weather updates",
-                        "name": "city",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "query"
                     },
                     {
                         "enum": [
-                            "hourly",
-                            "daily"
+                            "sent",
+                            "failed"
                         ],
                         "type": "string",
-                        "description": "Frequency of updates (hourly or daily)",
-                        "name": "frequency",
-                        "in": "formData",
-                        "required": true
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only deliveries with a smaller ID",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 500",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Subscription successful. Confirmation email sent.",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Delivery"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Invalid filter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Email already subscribed",
+                    "401": {
+                        "description": "Invalid admin token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/unsubscribe/{token}": {
+        "/admin/log-level": {
             "get": {
-                "description": "Unsubscribes an email from weather updates using the token sent in emails.",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the log level",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LogLevelResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Takes effect immediately and lasts until the next restart.",
                 "consumes": [
                     "application/json"
                 ],
@@ -131,156 +287,1671 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscription"
+                    "admin"
                 ],
-                "summary": "Unsubscribe from weather updates",
+                "summary": "Change the log level",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Unsubscribe token",
-                        "name": "token",
-                        "in": "path",
-                        "required": true
+                        "description": "debug, info, warn, error, dpanic, panic or fatal",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.setLogLevelRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Unsubscribed successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.LogLevelResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid token",
+                        "description": "Invalid level",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Token not found",
+                    "401": {
+                        "description": "Invalid admin token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/weather": {
+        "/admin/suppressions": {
             "get": {
-                "description": "Returns the current weather forecast for the specified city using WeatherAPI.com.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Lists addresses that no longer receive email because of a hard bounce or a complaint, newest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "weather"
+                    "admin"
                 ],
-                "summary": "Get current weather for a city",
+                "summary": "List suppressed email addresses",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "City name for weather forecast",
-                        "name": "city",
-                        "in": "query",
-                        "required": true
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size, at most 1000",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Weather"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Suppression"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Invalid limit",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "City not found",
+                    "401": {
+                        "description": "Invalid admin token",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/health/liveness": {
-            "get": {
-                "description": "Check if server is up and running",
-                "consumes": [
-                    "application/json"
+        "/admin/suppressions/{email}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Resumes email to the address for its existing subscriptions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "admin"
+                ],
+                "summary": "Remove an address from the suppression list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Liveness Probe",
-                "operationId": "livenessProbe",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.HealthProbe"
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Address is not suppressed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/health/readiness": {
+        "/api/confirm/{token}": {
             "get": {
-                "description": "Check if server is ready to accept requests",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Shows the subscription and a button confirming it. Opening the link has no effect, so that mail scanners cannot confirm on the user's behalf.",
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "health"
+                    "subscription"
+                ],
+                "summary": "Confirmation page",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Confirmation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Readiness Probe",
-                "operationId": "readinessProbe",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Confirmation page",
                         "schema": {
-                            "$ref": "#/definitions/handlers.HealthProbe"
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "handlers.HealthProbe": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.Weather": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "humidity": {
-                    "type": "integer"
-                },
+            },
+            "post": {
+                "description": "Confirms a subscription using the token sent in the confirmation email. Browsers submitting the confirmation page get an HTML page.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Confirm email subscription",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Confirmation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription confirmed successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/subscribe": {
+            "post": {
+                "description": "Subscribe an email to receive weather updates for a specific city with chosen frequency.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Subscribe to weather updates",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address to subscribe",
+                        "name": "email",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "City for weather updates",
+                        "name": "city",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "hourly",
+                            "daily"
+                        ],
+                        "type": "string",
+                        "description": "Frequency of updates (hourly or daily)",
+                        "name": "frequency",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription successful. Confirmation email sent.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already subscribed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/unsubscribe/{token}": {
+            "get": {
+                "description": "Shows the subscription and a button cancelling it. Opening the link has no effect, so that mail scanners cannot unsubscribe on the user's behalf.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Unsubscribe page",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unsubscribe page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Unsubscribes from weather updates using the token sent in emails. Also serves one-click unsubscribe (RFC 8058) from mail clients. Browsers submitting the unsubscribe page get an HTML page.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Unsubscribe from weather updates",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "One-Click"
+                        ],
+                        "type": "string",
+                        "description": "One-click unsubscribe marker",
+                        "name": "List-Unsubscribe",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unsubscribed successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/confirm/{token}": {
+            "get": {
+                "description": "Shows the subscription and a button confirming it. Opening the link has no effect, so that mail scanners cannot confirm on the user's behalf.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Confirmation page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Confirmation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Confirms a subscription using the token sent in the confirmation email. Browsers submitting the confirmation page get an HTML page.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Confirm email subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Confirmation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/email/events": {
+            "post": {
+                "description": "Accepts notifications from the mail provider or a mailbox forwarder. The body is either JSON,\na single {\"type\": \"bounce|complaint\", \"email\", \"bounce_type\": \"hard|soft\", \"status\", \"detail\"} object or an array of them,\nor a delivery status notification (RFC 3464) as message/delivery-status, multipart/report or message/rfc822.\nHard bounces and complaints suppress the address; soft bounces are ignored.\nAuthenticated with Authorization: Bearer $EMAIL_EVENTS_TOKEN or ?token=, for providers that only accept a URL.",
+                "consumes": [
+                    "application/json",
+                    "message/delivery-status",
+                    "multipart/report",
+                    "message/rfc822"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email"
+                ],
+                "summary": "Receive bounce and complaint notifications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Events token, if not sent in the Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.EmailEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported or malformed notification",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid events token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/subscribe": {
+            "post": {
+                "description": "Subscribe an email to receive weather updates for a specific city with chosen frequency. Accepts a form or a JSON body.",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Subscribe to weather updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email address to subscribe",
+                        "name": "email",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "City for weather updates",
+                        "name": "city",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "hourly",
+                            "daily"
+                        ],
+                        "type": "string",
+                        "description": "Frequency of updates (hourly or daily)",
+                        "name": "frequency",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Unit system for notifications",
+                        "name": "units",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "en",
+                        "description": "Language of notifications, e.g. uk",
+                        "name": "lang",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already subscribed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Email address is suppressed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/subscriptions/{token}": {
+            "get": {
+                "description": "Returns the subscription identified by the token sent in emails, including when the next update is due and when the last one was delivered.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Get subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubscriptionV2"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/unsubscribe/{token}": {
+            "get": {
+                "description": "Shows the subscription and a button cancelling it. Opening the link has no effect, so that mail scanners cannot unsubscribe on the user's behalf.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Unsubscribe page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Unsubscribe page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Unsubscribes from weather updates using the token sent in emails. Also serves one-click unsubscribe (RFC 8058) from mail clients. Browsers submitting the unsubscribe page get an HTML page.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Unsubscribe from weather updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unsubscribe token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "One-Click"
+                        ],
+                        "type": "string",
+                        "description": "One-click unsubscribe marker",
+                        "name": "List-Unsubscribe",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/weather": {
+            "get": {
+                "security": [
+                    {
+                        "APIKey": []
+                    }
+                ],
+                "description": "Returns the current weather for the specified city, including location, feels-like temperature and wind.\nTemperatures are in °C or °F and wind speed in km/h or mph depending on units.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get current weather for a city",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name for weather forecast",
+                        "name": "city",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Unit system",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "en",
+                        "description": "Language of the description, e.g. uk",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WeatherV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "API key required or invalid",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "City not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "API key daily quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/weather/stream": {
+            "get": {
+                "description": "Server-Sent Events stream. A \"weather\" event carrying WeatherV2 is sent with the current weather and then\nwhenever it changes; \"heartbeat\" events keep idle connections alive.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Stream live weather for a city",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name",
+                        "name": "city",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Unit system",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "en",
+                        "description": "Language of the description, e.g. uk",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WeatherV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "City not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many cities are being streamed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/webhooks": {
+            "post": {
+                "description": "Registers an HTTPS endpoint for weather updates. Before the subscription is stored the endpoint receives a signed\n\"verification\" event and must echo its challenge, either as the response body or as {\"challenge\": \"...\"}.\nDeliveries are signed with X-Webhook-Signature: v1=hex(HMAC-SHA256(secret, X-Webhook-Timestamp + \".\" + body)).\nThe token unsubscribes via POST /api/v2/unsubscribe/{token}. Neither the token nor the secret can be retrieved later.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscription"
+                ],
+                "summary": "Subscribe a webhook to weather updates",
+                "parameters": [
+                    {
+                        "description": "Webhook subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscribeWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WebhookSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or webhook URL",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Webhook already subscribed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Webhook endpoint verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/weather": {
+            "get": {
+                "description": "Returns the current weather forecast for the specified city using WeatherAPI.com.\nNo API key is needed by default; a deployment that lists this route in AUTH_API_KEY_REQUIRED_ROUTES\nrequires one in X-API-Key and answers 401 or 429 like /api/v2/weather.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get current weather for a city",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name for weather forecast",
+                        "name": "city",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Unit system",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "en",
+                        "description": "Language of the description, e.g. uk",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WeatherV1"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "City not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/weather/stream": {
+            "get": {
+                "description": "Server-Sent Events stream. A \"weather\" event carrying WeatherV2 is sent with the current weather and then\nwhenever it changes; \"heartbeat\" events keep idle connections alive.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Stream live weather for a city",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name",
+                        "name": "city",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Unit system",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "en",
+                        "description": "Language of the description, e.g. uk",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WeatherV2"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "City not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many cities are being streamed",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/dev/notifications/{kind}": {
+            "get": {
+                "description": "Renders a notification template with sample data. Not available in prod.",
+                "produces": [
+                    "text/html",
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "dev"
+                ],
+                "summary": "Preview a notification",
+                "parameters": [
+                    {
+                        "enum": [
+                            "confirmation",
+                            "hourly_update",
+                            "daily_digest",
+                            "alert",
+                            "goodbye"
+                        ],
+                        "type": "string",
+                        "description": "Notification kind",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "en",
+                        "description": "Template locale",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Unit system",
+                        "name": "units",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "html",
+                            "text",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "html",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.NotificationPreview"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown notification kind",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{file}": {
+            "get": {
+                "description": "Daily forecasts for the subscription's city as an iCalendar (all-day events) or RSS 2.0 feed.\nA manage token authorizes access; confirm and unsubscribe tokens from emails are refused.\nSupports If-None-Match and If-Modified-Since.",
+                "produces": [
+                    "text/calendar",
+                    "application/rss+xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Forecast feed for a subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Manage token with .ics or .rss extension",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/liveness": {
+            "get": {
+                "description": "Check if server is up and running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness Probe",
+                "operationId": "livenessProbe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthProbe"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/readiness": {
+            "get": {
+                "description": "Check if server is ready to accept requests",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness Probe",
+                "operationId": "readinessProbe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthProbe"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "enums.Channel": {
+            "type": "string",
+            "enum": [
+                "email",
+                "telegram",
+                "webhook"
+            ],
+            "x-enum-varnames": [
+                "ChannelEmail",
+                "ChannelTelegram",
+                "ChannelWebhook"
+            ]
+        },
+        "enums.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "sent",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryStatusSent",
+                "DeliveryStatusFailed"
+            ]
+        },
+        "enums.Frequency": {
+            "type": "string",
+            "enum": [
+                "daily",
+                "hourly"
+            ],
+            "x-enum-varnames": [
+                "FrequencyDaily",
+                "FrequencyHourly"
+            ]
+        },
+        "enums.SuppressionReason": {
+            "type": "string",
+            "enum": [
+                "hard_bounce",
+                "complaint"
+            ],
+            "x-enum-varnames": [
+                "SuppressionReasonHardBounce",
+                "SuppressionReasonComplaint"
+            ]
+        },
+        "handlers.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "daily_quota": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Key is the plaintext API key. It is shown only once.",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "requests_today": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.EmailEventsResponse": {
+            "type": "object",
+            "properties": {
+                "received": {
+                    "type": "integer"
+                },
+                "suppressed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.ErrorBody": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/handlers.ErrorBody"
+                }
+            }
+        },
+        "handlers.HealthProbe": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "info"
+                }
+            }
+        },
+        "handlers.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.NotificationPreview": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SubscriptionV2": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "telegram",
+                        "webhook"
+                    ]
+                },
+                "city": {
+                    "type": "string"
+                },
+                "confirmed": {
+                    "type": "boolean"
+                },
+                "contact": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "hourly",
+                        "daily"
+                    ]
+                },
+                "lang": {
+                    "type": "string"
+                },
+                "last_sent_at": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "string",
+                    "enum": [
+                        "metric",
+                        "imperial"
+                    ]
+                }
+            }
+        },
+        "handlers.WeatherV1": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "humidity": {
+                    "type": "integer"
+                },
+                "temperature": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.WeatherV2": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "feels_like": {
+                    "type": "number"
+                },
+                "humidity": {
+                    "type": "integer"
+                },
+                "lang": {
+                    "type": "string"
+                },
                 "temperature": {
                     "type": "number"
+                },
+                "units": {
+                    "type": "string",
+                    "enum": [
+                        "metric",
+                        "imperial"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wind_speed": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.WebhookSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "daily_quota": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.setLogLevelRequest": {
+            "type": "object",
+            "required": [
+                "level"
+            ],
+            "properties": {
+                "level": {
+                    "type": "string",
+                    "example": "debug"
+                }
+            }
+        },
+        "handlers.subscribeWebhookRequest": {
+            "type": "object",
+            "required": [
+                "city",
+                "frequency",
+                "url"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "enum": [
+                        "hourly",
+                        "daily"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.Frequency"
+                        }
+                    ]
+                },
+                "lang": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "daily_quota": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "requests_today": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Delivery": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "$ref": "#/definitions/enums.Channel"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "provider_message_id": {
+                    "type": "string"
+                },
+                "scheduled_for": {
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/enums.DeliveryStatus"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Suppression": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "reason": {
+                    "$ref": "#/definitions/enums.SuppressionReason"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "APIKey": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "AdminToken": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
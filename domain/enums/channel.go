@@ -0,0 +1,16 @@
+package enums
+
+// Channel is the delivery channel of a subscription. It defines how the
+// subscription contact is interpreted: an email address, a Telegram chat id
+// or a webhook URL.
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelTelegram Channel = "telegram"
+	ChannelWebhook  Channel = "webhook"
+)
+
+func (c Channel) String() string {
+	return string(c)
+}
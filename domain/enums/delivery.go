@@ -0,0 +1,17 @@
+package enums
+
+// DeliveryStatus is the outcome of a scheduled delivery.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent   DeliveryStatus = "sent"
+	DeliveryStatusFailed DeliveryStatus = "failed"
+)
+
+func (s DeliveryStatus) String() string {
+	return string(s)
+}
+
+func (s DeliveryStatus) IsValid() bool {
+	return s == DeliveryStatusSent || s == DeliveryStatusFailed
+}
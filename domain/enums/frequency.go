@@ -10,3 +10,7 @@ const (
 func (f Frequency) String() string {
 	return string(f)
 }
+
+func (f Frequency) IsValid() bool {
+	return f == FrequencyDaily || f == FrequencyHourly
+}
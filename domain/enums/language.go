@@ -0,0 +1,32 @@
+package enums
+
+import "strings"
+
+// Language is a weatherapi.com condition text language code.
+type Language string
+
+const LanguageEnglish Language = "en"
+
+var knownLanguages = map[Language]bool{
+	LanguageEnglish: true,
+	"ar":            true, "bn": true, "bg": true, "zh": true, "zh_tw": true, "cs": true,
+	"da": true, "nl": true, "fi": true, "fr": true, "de": true, "el": true,
+	"hi": true, "hu": true, "it": true, "ja": true, "jv": true, "ko": true,
+	"zh_cmn": true, "mr": true, "pl": true, "pt": true, "pa": true, "ro": true,
+	"ru": true, "sr": true, "si": true, "sk": true, "es": true, "sv": true,
+	"ta": true, "te": true, "tr": true, "uk": true, "ur": true, "vi": true,
+	"zh_wuu": true, "zh_hsn": true, "zh_yue": true, "zu": true,
+}
+
+func ParseLanguage(v string) (Language, bool) {
+	l := Language(strings.ToLower(strings.ReplaceAll(v, "-", "_")))
+	return l, knownLanguages[l]
+}
+
+func (l Language) String() string {
+	return string(l)
+}
+
+func (l Language) IsValid() bool {
+	return knownLanguages[l]
+}
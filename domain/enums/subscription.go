@@ -0,0 +1,15 @@
+package enums
+
+// SubscriptionStatus tells whether a subscription receives updates.
+type SubscriptionStatus string
+
+const (
+	// SubscriptionStatusPending subscriptions wait for the email
+	// confirmation link to be followed.
+	SubscriptionStatusPending SubscriptionStatus = "pending"
+	SubscriptionStatusActive  SubscriptionStatus = "active"
+)
+
+func (s SubscriptionStatus) String() string {
+	return string(s)
+}
@@ -0,0 +1,30 @@
+package enums
+
+// SuppressionReason explains why an email address no longer receives mail.
+type SuppressionReason string
+
+const (
+	SuppressionReasonHardBounce SuppressionReason = "hard_bounce"
+	SuppressionReasonComplaint  SuppressionReason = "complaint"
+)
+
+func (r SuppressionReason) String() string {
+	return string(r)
+}
+
+// EmailEventType is the kind of a delivery problem reported for an email
+// address.
+type EmailEventType string
+
+const (
+	EmailEventBounce    EmailEventType = "bounce"
+	EmailEventComplaint EmailEventType = "complaint"
+)
+
+func (t EmailEventType) String() string {
+	return string(t)
+}
+
+func (t EmailEventType) IsValid() bool {
+	return t == EmailEventBounce || t == EmailEventComplaint
+}
@@ -0,0 +1,20 @@
+package enums
+
+// TokenPurpose is the action a subscription token authorizes.
+type TokenPurpose string
+
+const (
+	TokenPurposeConfirm     TokenPurpose = "confirm"
+	TokenPurposeUnsubscribe TokenPurpose = "unsubscribe"
+	// TokenPurposeManage authorizes viewing the subscription and its feeds
+	// and unsubscribing.
+	TokenPurposeManage TokenPurpose = "manage"
+)
+
+func (p TokenPurpose) String() string {
+	return string(p)
+}
+
+func (p TokenPurpose) IsValid() bool {
+	return p == TokenPurposeConfirm || p == TokenPurposeUnsubscribe || p == TokenPurposeManage
+}
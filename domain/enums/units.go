@@ -0,0 +1,30 @@
+package enums
+
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+func (u Units) String() string {
+	return string(u)
+}
+
+func (u Units) IsValid() bool {
+	return u == UnitsMetric || u == UnitsImperial
+}
+
+func (u Units) TemperatureSymbol() string {
+	if u == UnitsImperial {
+		return "°F"
+	}
+	return "°C"
+}
+
+func (u Units) SpeedSymbol() string {
+	if u == UnitsImperial {
+		return "mph"
+	}
+	return "km/h"
+}
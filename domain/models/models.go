@@ -1,17 +1,210 @@
 package models
 
-import "github.com/serjnester/weather-subscription-service/domain/enums"
+import (
+	"math"
+	"time"
 
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+// Weather temperatures and wind speed are expressed in Units,
+// the description is in Lang.
 type Weather struct {
-	Temperature float64 `json:"temperature"`
-	Description string  `json:"description"`
-	Humidity    int     `json:"humidity"`
+	City        string         `json:"city"`
+	Country     string         `json:"country"`
+	Temperature float64        `json:"temperature"`
+	FeelsLike   float64        `json:"feels_like"`
+	Description string         `json:"description"`
+	Humidity    int            `json:"humidity"`
+	WindSpeed   float64        `json:"wind_speed"`
+	Units       enums.Units    `json:"units"`
+	Lang        enums.Language `json:"lang"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// In returns w converted to units. Values are rounded to one decimal place,
+// as reported by weatherapi.com.
+func (w Weather) In(units enums.Units) Weather {
+	if w.Units == "" || w.Units == units {
+		return w
+	}
+
+	switch units {
+	case enums.UnitsImperial:
+		w.Temperature = round1(w.Temperature*9/5 + 32)
+		w.FeelsLike = round1(w.FeelsLike*9/5 + 32)
+		w.WindSpeed = round1(w.WindSpeed / kmPerMile)
+	case enums.UnitsMetric:
+		w.Temperature = round1((w.Temperature - 32) * 5 / 9)
+		w.FeelsLike = round1((w.FeelsLike - 32) * 5 / 9)
+		w.WindSpeed = round1(w.WindSpeed * kmPerMile)
+	default:
+		return w
+	}
+	w.Units = units
+	return w
+}
+
+const kmPerMile = 1.609344
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// Forecast is a multi-day forecast for a city; Days starts with today in
+// the city's local time.
+type Forecast struct {
+	City      string          `json:"city"`
+	Country   string          `json:"country"`
+	Units     enums.Units     `json:"units"`
+	Lang      enums.Language  `json:"lang"`
+	Days      []DailyForecast `json:"days"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// DailyForecast Date holds a calendar date at midnight UTC.
+type DailyForecast struct {
+	Date         time.Time `json:"date"`
+	MinTemp      float64   `json:"min_temp"`
+	MaxTemp      float64   `json:"max_temp"`
+	AvgTemp      float64   `json:"avg_temp"`
+	Description  string    `json:"description"`
+	Humidity     int       `json:"humidity"`
+	MaxWindSpeed float64   `json:"max_wind_speed"`
+	ChanceOfRain int       `json:"chance_of_rain"`
+	Sunrise      string    `json:"sunrise"`
+	Sunset       string    `json:"sunset"`
+}
+
+// Preferences control how weather is presented to a reader.
+type Preferences struct {
+	Units enums.Units    `json:"units"`
+	Lang  enums.Language `json:"lang"`
+}
+
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Units: enums.UnitsMetric,
+		Lang:  enums.LanguageEnglish,
+	}
+}
+
+// WithDefaults fills unset fields from DefaultPreferences.
+func (p Preferences) WithDefaults() Preferences {
+	def := DefaultPreferences()
+	if p.Units == "" {
+		p.Units = def.Units
+	}
+	if p.Lang == "" {
+		p.Lang = def.Lang
+	}
+	return p
 }
 
+// Subscription Contact is interpreted according to Channel: an email
+// address for email, a chat id for Telegram, an endpoint URL for webhooks.
 type Subscription struct {
-	Email     string          `json:"email"`
+	ID        int64           `json:"id"`
+	Channel   enums.Channel   `json:"channel"`
+	Contact   string          `json:"contact"`
 	City      string          `json:"city"`
 	Frequency enums.Frequency `json:"frequency"`
+	Units     enums.Units     `json:"units"`
+	Lang      enums.Language  `json:"lang"`
 	Token     string          `json:"token"`
 	Confirmed bool            `json:"confirmed"`
+	// WebhookSecret signs webhook deliveries. Empty for other channels.
+	WebhookSecret string `json:"-"`
+	// NextRunAt is when the next periodic update is due.
+	NextRunAt time.Time `json:"next_run_at"`
+	// LastSentAt is when the last update was delivered, if ever. It is only
+	// loaded where documented.
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	// CreatedAt is when the subscription was made, set by the storage.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s Subscription) Preferences() Preferences {
+	return Preferences{Units: s.Units, Lang: s.Lang}.WithDefaults()
+}
+
+// Status is derived from Confirmed: only email subscriptions start out
+// pending.
+func (s Subscription) Status() enums.SubscriptionStatus {
+	if s.Confirmed {
+		return enums.SubscriptionStatusActive
+	}
+	return enums.SubscriptionStatusPending
+}
+
+type APIKey struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	DailyQuota    int        `json:"daily_quota"`
+	RequestsToday int64      `json:"requests_today"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (k APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// WebhookAttempt records a single HTTP attempt to deliver a webhook.
+// StatusCode is zero when no response was received.
+type WebhookAttempt struct {
+	ID             int64         `json:"id"`
+	SubscriptionID int64         `json:"subscription_id"`
+	DeliveryID     string        `json:"delivery_id"`
+	Attempt        int           `json:"attempt"`
+	StatusCode     int           `json:"status_code,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	Duration       time.Duration `json:"duration"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// Delivery records one scheduled update sent, or attempted, to a
+// subscription. SentAt and ProviderMessageID are set for sent deliveries,
+// Error for failed ones.
+type Delivery struct {
+	ID                int64                `json:"id"`
+	SubscriptionID    int64                `json:"subscription_id"`
+	Channel           enums.Channel        `json:"channel"`
+	ScheduledFor      time.Time            `json:"scheduled_for"`
+	SentAt            *time.Time           `json:"sent_at,omitempty"`
+	Status            enums.DeliveryStatus `json:"status"`
+	ProviderMessageID string               `json:"provider_message_id,omitempty"`
+	Error             string               `json:"error,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+}
+
+// DeliveryFilter selects deliveries, newest first. Zero fields do not
+// filter; BeforeID continues a listing after its last ID.
+type DeliveryFilter struct {
+	SubscriptionID int64
+	Status         enums.DeliveryStatus
+	BeforeID       int64
+	Limit          int
+}
+
+// Suppression blocks all email to an address, regardless of its
+// subscriptions.
+type Suppression struct {
+	Email     string                  `json:"email"`
+	Reason    enums.SuppressionReason `json:"reason"`
+	Detail    string                  `json:"detail,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// EmailEvent is a bounce or complaint reported for an address by the mail
+// provider or the receiving server. Permanent marks hard bounces.
+type EmailEvent struct {
+	Type      enums.EmailEventType `json:"type"`
+	Email     string               `json:"email"`
+	Permanent bool                 `json:"permanent"`
+	// Status is the enhanced status code (RFC 3463), e.g. 5.1.1.
+	Status string `json:"status,omitempty"`
+	Detail string `json:"detail,omitempty"`
 }
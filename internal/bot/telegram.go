@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/telegram"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const retryDelay = 5 * time.Second
+
+const helpText = `Weather bot commands:
+/subscribe <city> <hourly|daily> - receive weather updates
+/unsubscribe [city] - stop updates for a city, or for all cities
+/weather <city> - current weather`
+
+// TelegramBot maps Telegram commands onto service.Service. Subscriptions
+// created here use the chat id as their contact.
+type TelegramBot struct {
+	client      telegram.BotClient
+	service     service.Service
+	logger      *zap.Logger
+	pollTimeout time.Duration
+}
+
+func NewTelegramBot(client telegram.BotClient, svc service.Service, logger *zap.Logger, pollTimeout time.Duration) *TelegramBot {
+	return &TelegramBot{
+		client:      client,
+		service:     svc,
+		logger:      logger,
+		pollTimeout: pollTimeout,
+	}
+}
+
+// Run long-polls for updates until ctx is done.
+func (b *TelegramBot) Run(ctx context.Context) error {
+	var offset int64
+
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset, b.pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			b.logger.Warn("telegram get updates error", zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.Text == "" {
+				continue
+			}
+
+			reply := b.Handle(ctx, *u.Message)
+			if _, err := b.client.SendMessage(ctx, u.Message.Chat.ID, reply); err != nil {
+				b.logger.Warn("telegram send reply error", zap.Int64("chat_id", u.Message.Chat.ID), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Handle executes a single command and returns the reply text.
+func (b *TelegramBot) Handle(ctx context.Context, msg telegram.Message) string {
+	command, args := parseCommand(msg.Text)
+	chatID := strconv.FormatInt(msg.Chat.ID, 10)
+	prefs := models.DefaultPreferences()
+	if msg.From != nil {
+		if lang, ok := enums.ParseLanguage(msg.From.LanguageCode); ok {
+			prefs.Lang = lang
+		}
+	}
+
+	switch command {
+	case "/start", "/help":
+		return helpText
+	case "/subscribe":
+		return b.subscribe(ctx, chatID, args, prefs)
+	case "/unsubscribe":
+		return b.unsubscribe(ctx, chatID, args)
+	case "/weather":
+		return b.weather(ctx, args, prefs)
+	default:
+		return "Unknown command.\n\n" + helpText
+	}
+}
+
+func (b *TelegramBot) subscribe(ctx context.Context, chatID string, args []string, prefs models.Preferences) string {
+	if len(args) < 2 {
+		return "Usage: /subscribe <city> <hourly|daily>"
+	}
+	frequency := enums.Frequency(strings.ToLower(args[len(args)-1]))
+	if !frequency.IsValid() {
+		return "Frequency must be hourly or daily."
+	}
+	city := strings.Join(args[:len(args)-1], " ")
+
+	if _, err := b.service.WeatherForecast(ctx, city, prefs); err != nil {
+		return b.failure(err, "subscribe")
+	}
+
+	_, err := b.service.Subscribe(ctx, models.Subscription{
+		Channel:   enums.ChannelTelegram,
+		Contact:   chatID,
+		City:      city,
+		Frequency: frequency,
+		Units:     prefs.Units,
+		Lang:      prefs.Lang,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrAlreadySubscribed) {
+			return fmt.Sprintf("You are already subscribed to %s.", city)
+		}
+		return b.failure(err, "subscribe")
+	}
+
+	return fmt.Sprintf("Subscribed to %s weather updates for %s.", frequency, city)
+}
+
+func (b *TelegramBot) unsubscribe(ctx context.Context, chatID string, args []string) string {
+	city := strings.Join(args, " ")
+
+	subs, err := b.service.SubscriptionsByContact(ctx, enums.ChannelTelegram, chatID)
+	if err != nil {
+		return b.failure(err, "unsubscribe")
+	}
+
+	var removed []string
+	for _, sub := range subs {
+		if city != "" && !strings.EqualFold(sub.City, city) {
+			continue
+		}
+		if err := b.service.Unsubscribe(ctx, b.service.IssueToken(sub, enums.TokenPurposeUnsubscribe)); err != nil && !errors.Is(err, service.ErrTokenNotFound) {
+			return b.failure(err, "unsubscribe")
+		}
+		removed = append(removed, sub.City)
+	}
+
+	if len(removed) == 0 {
+		return "You have no matching subscriptions."
+	}
+	return "Unsubscribed from " + strings.Join(removed, ", ") + "."
+}
+
+func (b *TelegramBot) weather(ctx context.Context, args []string, prefs models.Preferences) string {
+	if len(args) == 0 {
+		return "Usage: /weather <city>"
+	}
+
+	w, err := b.service.WeatherForecast(ctx, strings.Join(args, " "), prefs)
+	if err != nil {
+		return b.failure(err, "weather")
+	}
+
+	return fmt.Sprintf("%s, %s: %s, %.1f%s (feels like %.1f%s), humidity %d%%, wind %.1f %s.",
+		w.City, w.Country, w.Description,
+		w.Temperature, w.Units.TemperatureSymbol(),
+		w.FeelsLike, w.Units.TemperatureSymbol(),
+		w.Humidity,
+		w.WindSpeed, w.Units.SpeedSymbol(),
+	)
+}
+
+func (b *TelegramBot) failure(err error, command string) string {
+	if errors.Is(err, weatherapi.ErrCityNotFound) {
+		return "City not found."
+	}
+	b.logger.Error("telegram command error", zap.String("command", command), zap.Error(err))
+	return "Something went wrong, please try again later."
+}
+
+// parseCommand splits "/cmd@BotName arg1 arg2" into "/cmd" and its arguments.
+func parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	return command, fields[1:]
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/telegram"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testToken = "123:test"
+
+// fakeBotAPI is a local stand-in for api.telegram.org. It serves the queued
+// messages once through getUpdates and records every sendMessage call.
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	pending []telegram.Update
+	sent    []sentMessage
+	replies chan struct{}
+}
+
+type sentMessage struct {
+	ChatID int64
+	Text   string
+}
+
+func newFakeBotAPI(t *testing.T, texts ...string) (*fakeBotAPI, *httptest.Server) {
+	api := &fakeBotAPI{replies: make(chan struct{}, len(texts))}
+	for i, text := range texts {
+		api.pending = append(api.pending, telegram.Update{
+			UpdateID: int64(100 + i),
+			Message: &telegram.Message{
+				MessageID: int64(i + 1),
+				Chat:      telegram.Chat{ID: 42},
+				From:      &telegram.User{ID: 7, LanguageCode: "uk"},
+				Text:      text,
+			},
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/bot" + testToken + "/getUpdates":
+			offset, _ := strconv.ParseInt(r.Form.Get("offset"), 10, 64)
+			var updates []telegram.Update
+			for _, u := range api.pending {
+				if u.UpdateID >= offset {
+					updates = append(updates, u)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": updates})
+		case "/bot" + testToken + "/sendMessage":
+			chatID, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+			api.sent = append(api.sent, sentMessage{ChatID: chatID, Text: r.Form.Get("text")})
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{"message_id": len(api.sent)}})
+			api.replies <- struct{}{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return api, server
+}
+
+func (a *fakeBotAPI) waitReplies(t *testing.T, n int) []sentMessage {
+	for i := 0; i < n; i++ {
+		select {
+		case <-a.replies:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for reply %d", i+1)
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]sentMessage(nil), a.sent...)
+}
+
+func TestTelegramBot_Run(t *testing.T) {
+	var (
+		subscribed   models.Subscription
+		unsubscribed []string
+	)
+	svc := &service.MockService{
+		WeatherForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			if city == "Atlantis" {
+				return models.Weather{}, weatherapi.ErrCityNotFound
+			}
+			return models.Weather{
+				City: city, Country: "Ukraine", Temperature: 22.5, FeelsLike: 21.8,
+				Description: "Хмарно", Humidity: 60, WindSpeed: 15, Units: prefs.Units, Lang: prefs.Lang,
+			}, nil
+		},
+		SubscribeFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+			subscribed = sub
+			return sub, nil
+		},
+		SubscriptionsByContactFn: func(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+			require.Equal(t, enums.ChannelTelegram, channel)
+			require.Equal(t, "42", contact)
+			return []models.Subscription{
+				{City: "Kyiv", Token: "t1"},
+				{City: "Lviv", Token: "t2"},
+			}, nil
+		},
+		IssueTokenFn: func(sub models.Subscription, purpose enums.TokenPurpose) string {
+			require.Equal(t, enums.TokenPurposeUnsubscribe, purpose)
+			return "unsubscribe-" + sub.Token
+		},
+		UnsubscribeFn: func(ctx context.Context, token string) error {
+			unsubscribed = append(unsubscribed, token)
+			return nil
+		},
+	}
+
+	api, server := newFakeBotAPI(t,
+		"/start",
+		"/subscribe New York daily",
+		"/subscribe Atlantis hourly",
+		"/weather@WeatherBot Kyiv",
+		"/unsubscribe lviv",
+	)
+
+	b := NewTelegramBot(telegram.NewClient(server.URL, testToken), svc, zap.NewNop(), time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	sent := api.waitReplies(t, 5)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	require.Len(t, sent, 5)
+	for _, m := range sent {
+		require.Equal(t, int64(42), m.ChatID)
+	}
+	require.Contains(t, sent[0].Text, "/subscribe <city>")
+	require.Equal(t, "Subscribed to daily weather updates for New York.", sent[1].Text)
+	require.Equal(t, "City not found.", sent[2].Text)
+	require.Equal(t, "Kyiv, Ukraine: Хмарно, 22.5°C (feels like 21.8°C), humidity 60%, wind 15.0 km/h.", sent[3].Text)
+	require.Equal(t, "Unsubscribed from Lviv.", sent[4].Text)
+
+	require.Equal(t, models.Subscription{
+		Channel:   enums.ChannelTelegram,
+		Contact:   "42",
+		City:      "New York",
+		Frequency: enums.FrequencyDaily,
+		Units:     enums.UnitsMetric,
+		Lang:      "uk",
+	}, subscribed)
+	require.Equal(t, []string{"unsubscribe-t2"}, unsubscribed)
+}
@@ -0,0 +1,208 @@
+package bounces
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported bounce notification format")
+
+// maxDepth limits nesting of message/rfc822 and multipart parts.
+const maxDepth = 5
+
+// Parse reads bounce and complaint notifications from body according to
+// contentType: the generic JSON format or a delivery status notification
+// (RFC 3464), either bare or wrapped in a report or a forwarded message.
+func Parse(contentType string, body io.Reader) ([]models.EmailEvent, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if mediaType == "application/json" {
+		return ParseJSON(body)
+	}
+	return ParseDSN(contentType, body)
+}
+
+// jsonEvent is the generic notification format:
+//
+//	{"type": "bounce", "email": "user@example.com", "bounce_type": "hard", "status": "5.1.1", "detail": "..."}
+//
+// A body may hold a single event or an array of them.
+type jsonEvent struct {
+	Type       enums.EmailEventType `json:"type"`
+	Email      string               `json:"email"`
+	BounceType string               `json:"bounce_type"`
+	Status     string               `json:"status"`
+	Detail     string               `json:"detail"`
+}
+
+func ParseJSON(body io.Reader) ([]models.EmailEvent, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []jsonEvent
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single jsonEvent
+		err = json.Unmarshal(trimmed, &single)
+		raw = []jsonEvent{single}
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	events := make([]models.EmailEvent, 0, len(raw))
+	for _, e := range raw {
+		if !e.Type.IsValid() || e.Email == "" {
+			return nil, fmt.Errorf("%w: event requires a valid type and email", ErrUnsupportedFormat)
+		}
+		events = append(events, models.EmailEvent{
+			Type:  e.Type,
+			Email: e.Email,
+			// Without a bounce type, a 5.x.x status is the only sign of a
+			// permanent failure.
+			Permanent: e.Type == enums.EmailEventBounce &&
+				(e.BounceType == "hard" || (e.BounceType == "" && strings.HasPrefix(e.Status, "5."))),
+			Status: e.Status,
+			Detail: e.Detail,
+		})
+	}
+	return events, nil
+}
+
+// ParseDSN extracts failed and delayed recipients from a delivery status
+// notification. contentType is one of message/delivery-status,
+// multipart/report or message/rfc822.
+func ParseDSN(contentType string, body io.Reader) ([]models.EmailEvent, error) {
+	return parseDSN(contentType, body, 0)
+}
+
+func parseDSN(contentType string, body io.Reader, depth int) ([]models.EmailEvent, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: message nested too deeply", ErrUnsupportedFormat)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	switch {
+	case mediaType == "message/delivery-status":
+		return parseDeliveryStatus(body)
+
+	case mediaType == "message/rfc822":
+		msg, err := mail.ReadMessage(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+		}
+		return parseDSN(msg.Header.Get("Content-Type"), msg.Body, depth+1)
+
+	case strings.HasPrefix(mediaType, "multipart/"):
+		var events []models.EmailEvent
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+			}
+
+			partType := part.Header.Get("Content-Type")
+			if !isStatusPart(partType, mediaType == "multipart/report") {
+				continue
+			}
+			partEvents, err := parseDSN(partType, part, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, partEvents...)
+		}
+		return events, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, mediaType)
+}
+
+// isStatusPart selects the parts that may carry delivery status. Inside a
+// report, a message/rfc822 part is the returned original and is skipped
+// along with the human-readable explanation; elsewhere it may be a
+// forwarded report.
+func isStatusPart(contentType string, inReport bool) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "message/delivery-status", strings.HasPrefix(mediaType, "multipart/"):
+		return true
+	case mediaType == "message/rfc822":
+		return !inReport
+	}
+	return false
+}
+
+// parseDeliveryStatus reads the per-message fields followed by one block
+// of per-recipient fields per recipient (RFC 3464, section 2.1).
+func parseDeliveryStatus(body io.Reader) ([]models.EmailEvent, error) {
+	r := textproto.NewReader(bufio.NewReader(body))
+
+	var events []models.EmailEvent
+	for {
+		fields, err := r.ReadMIMEHeader()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+		}
+
+		if event, ok := recipientEvent(fields); ok {
+			events = append(events, event)
+		}
+		if err != nil {
+			return events, nil
+		}
+	}
+}
+
+func recipientEvent(fields textproto.MIMEHeader) (models.EmailEvent, bool) {
+	recipient := fields.Get("Final-Recipient")
+	if recipient == "" {
+		recipient = fields.Get("Original-Recipient")
+	}
+	// The address type is rfc822 for email: "rfc822; user@example.com".
+	addrType, addr, ok := strings.Cut(recipient, ";")
+	if !ok || !strings.EqualFold(strings.TrimSpace(addrType), "rfc822") {
+		return models.EmailEvent{}, false
+	}
+
+	action := strings.ToLower(strings.TrimSpace(fields.Get("Action")))
+	if action != "failed" && action != "delayed" {
+		return models.EmailEvent{}, false
+	}
+
+	status := strings.TrimSpace(fields.Get("Status"))
+	return models.EmailEvent{
+		Type:      enums.EmailEventBounce,
+		Email:     strings.TrimSpace(addr),
+		Permanent: action == "failed" && strings.HasPrefix(status, "5."),
+		Status:    status,
+		Detail:    strings.TrimSpace(fields.Get("Diagnostic-Code")),
+	}, true
+}
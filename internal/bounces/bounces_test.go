@@ -0,0 +1,135 @@
+package bounces
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []models.EmailEvent
+		wantErr bool
+	}{
+		{
+			name: "single hard bounce",
+			body: `{"type":"bounce","email":"a@example.com","bounce_type":"hard","status":"5.1.1","detail":"no such user"}`,
+			want: []models.EmailEvent{
+				{Type: enums.EmailEventBounce, Email: "a@example.com", Permanent: true, Status: "5.1.1", Detail: "no such user"},
+			},
+		},
+		{
+			name: "array with soft bounce, status-only bounce and complaint",
+			body: `[
+				{"type":"bounce","email":"a@example.com","bounce_type":"soft","status":"5.2.2"},
+				{"type":"bounce","email":"b@example.com","status":"5.1.1"},
+				{"type":"complaint","email":"c@example.com"}
+			]`,
+			want: []models.EmailEvent{
+				{Type: enums.EmailEventBounce, Email: "a@example.com", Status: "5.2.2"},
+				{Type: enums.EmailEventBounce, Email: "b@example.com", Permanent: true, Status: "5.1.1"},
+				{Type: enums.EmailEventComplaint, Email: "c@example.com"},
+			},
+		},
+		{
+			name:    "unknown type",
+			body:    `{"type":"open","email":"a@example.com"}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing email",
+			body:    `{"type":"bounce"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			body:    `bounce a@example.com`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse("application/json; charset=utf-8", strings.NewReader(tt.body))
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnsupportedFormat)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+const deliveryStatus = "Reporting-MTA: dns; mx.example.net\r\n" +
+	"Arrival-Date: Tue, 20 May 2025 08:00:03 +0000\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; gone@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 User unknown\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; full@example.com\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.2.2\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; ok@example.com\r\n" +
+	"Action: delivered\r\n" +
+	"Status: 2.0.0\r\n"
+
+var wantDSNEvents = []models.EmailEvent{
+	{Type: enums.EmailEventBounce, Email: "gone@example.com", Permanent: true, Status: "5.1.1", Detail: "smtp; 550 5.1.1 User unknown"},
+	{Type: enums.EmailEventBounce, Email: "full@example.com", Status: "4.2.2"},
+}
+
+const report = "--b1\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--b1\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	deliveryStatus +
+	"--b1\r\n" +
+	"Content-Type: message/rfc822\r\n" +
+	"\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; original@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"--b1--\r\n"
+
+func TestParseDSN(t *testing.T) {
+	t.Run("bare delivery status", func(t *testing.T) {
+		got, err := Parse("message/delivery-status", strings.NewReader(deliveryStatus))
+		require.NoError(t, err)
+		require.Equal(t, wantDSNEvents, got)
+	})
+
+	t.Run("report skips the returned message", func(t *testing.T) {
+		got, err := Parse(`multipart/report; report-type=delivery-status; boundary="b1"`, strings.NewReader(report))
+		require.NoError(t, err)
+		require.Equal(t, wantDSNEvents, got)
+	})
+
+	t.Run("full message", func(t *testing.T) {
+		msg := "From: MAILER-DAEMON@mx.example.net\r\n" +
+			"Subject: Undelivered Mail Returned to Sender\r\n" +
+			"Content-Type: multipart/report; report-type=delivery-status; boundary=\"b1\"\r\n" +
+			"\r\n" + report
+		got, err := Parse("message/rfc822", strings.NewReader(msg))
+		require.NoError(t, err)
+		require.Equal(t, wantDSNEvents, got)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := Parse("text/plain", strings.NewReader("hello"))
+		require.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
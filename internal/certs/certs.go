@@ -0,0 +1,100 @@
+package certs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+// checkInterval limits how often the files are checked for changes.
+const checkInterval = 10 * time.Second
+
+// Reloader serves a certificate and key pair from disk and picks up a
+// renewed pair without a restart: the files are checked for changes on
+// handshakes, at most once per checkInterval. A pair that fails to load
+// keeps the previous certificate in use.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+	now       func() time.Time
+}
+
+func NewReloader(certFile, keyFile string, logger *zap.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+		now:      time.Now,
+	}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, fmt.Errorf("[certs.NewReloader] %w", err)
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, fmt.Errorf("[certs.NewReloader] %w", err)
+	}
+	return r, nil
+}
+
+// GetCertificate is meant for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.checkedAt) >= checkInterval {
+		r.checkedAt = now
+		modTime, err := r.latestModTime()
+		if err != nil {
+			r.logger.Error("failed to check TLS certificate", zap.Error(err))
+		} else if !modTime.Equal(r.modTime) {
+			if err := r.load(modTime); err != nil {
+				r.logger.Error("failed to reload TLS certificate", zap.Error(err))
+			} else {
+				r.logger.Info("TLS certificate reloaded")
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// TLSConfig returns a server configuration using the reloaded pair.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+func (r *Reloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// latestModTime changes whenever either file is replaced.
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,78 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writePair writes a self-signed certificate for name and returns its
+// DER bytes.
+func writePair(t *testing.T, certFile, keyFile, name string, modTime time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return der
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	modTime := time.Now().Add(-time.Hour)
+	first := writePair(t, certFile, keyFile, "first", modTime)
+
+	r, err := NewReloader(certFile, keyFile, zap.NewNop())
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, first, cert.Certificate[0])
+
+	second := writePair(t, certFile, keyFile, "second", modTime.Add(time.Minute))
+
+	cert, _ = r.GetCertificate(nil)
+	require.Equal(t, first, cert.Certificate[0], "files are not checked within the interval")
+
+	now = now.Add(checkInterval)
+	cert, _ = r.GetCertificate(nil)
+	require.Equal(t, second, cert.Certificate[0])
+
+	// A broken pair keeps the previous certificate.
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	now = now.Add(checkInterval)
+	cert, _ = r.GetCertificate(nil)
+	require.Equal(t, second, cert.Certificate[0])
+}
+
+func TestNewReloader_InvalidPair(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewReloader(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"), zap.NewNop())
+	require.Error(t, err)
+}
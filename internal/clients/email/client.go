@@ -0,0 +1,201 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Mail is a message with a plain text and an HTML alternative. Headers are
+// added verbatim, e.g. List-Unsubscribe.
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+type Sender interface {
+	// Send returns the Message-ID of the sent mail.
+	Send(ctx context.Context, m Mail) (string, error)
+}
+
+var _ Sender = (*Client)(nil)
+
+// Client sends mail through an SMTP relay, upgrading the connection with
+// STARTTLS when the server offers it.
+type Client struct {
+	addr     string
+	host     string
+	username string
+	password atomic.Pointer[string]
+	from     mail.Address
+	timeout  time.Duration
+}
+
+// NewClient creates a client for the relay at host:port. Authentication is
+// skipped when username is empty. Each message, from dialing to QUIT, must
+// be sent within timeout or by the context deadline, whichever is earlier.
+func NewClient(host, port, username, password string, from mail.Address, timeout time.Duration) *Client {
+	c := &Client{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		from:     from,
+		timeout:  timeout,
+	}
+	c.SetPassword(password)
+	return c
+}
+
+// SetPassword replaces the relay password for subsequent messages, e.g.
+// after rotation.
+func (c *Client) SetPassword(password string) {
+	c.password.Store(&password)
+}
+
+func (c *Client) Send(ctx context.Context, m Mail) (string, error) {
+	id := c.newMessageID()
+	body, err := c.build(m, id, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] build: %w", err)
+	}
+
+	// A stalled relay would otherwise hold the sender forever: the SMTP
+	// session does not watch ctx once the connection is established.
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	d := net.Dialer{Deadline: deadline}
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] dial: %w", err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("[EmailClient.Send] %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("[EmailClient.Send] %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.host}); err != nil {
+			return "", fmt.Errorf("[EmailClient.Send] starttls: %w", err)
+		}
+	}
+	if c.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.username, *c.password.Load(), c.host)); err != nil {
+			return "", fmt.Errorf("[EmailClient.Send] auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.from.Address); err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] mail from: %w", err)
+	}
+	if err := client.Rcpt(m.To); err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] rcpt to: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("[EmailClient.Send] data: %w", err)
+	}
+
+	return id, client.Quit()
+}
+
+// build renders m as a multipart/alternative message.
+func (c *Client) build(m Mail, id string, date time.Time) ([]byte, error) {
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+	for _, alt := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		if alt.body == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alt.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(alt.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	writeHeader := func(name, value string) {
+		// Header values must not smuggle in further headers.
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		fmt.Fprintf(&msg, "%s: %s\r\n", name, value)
+	}
+	writeHeader("From", c.from.String())
+	writeHeader("To", m.To)
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader("Date", date.Format(time.RFC1123Z))
+	writeHeader("Message-ID", id)
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(textproto.CanonicalMIMEHeaderKey(name), m.Headers[name])
+	}
+
+	msg.WriteString("\r\n")
+	msg.Write(parts.Bytes())
+	return msg.Bytes(), nil
+}
+
+func (c *Client) newMessageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	domain := "localhost"
+	if _, d, ok := strings.Cut(c.from.Address, "@"); ok {
+		domain = d
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,11 @@
+package email
+
+import "context"
+
+type MockSender struct {
+	SendFn func(ctx context.Context, m Mail) (string, error)
+}
+
+func (m *MockSender) Send(ctx context.Context, mail Mail) (string, error) {
+	return m.SendFn(ctx, mail)
+}
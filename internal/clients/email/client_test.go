@@ -0,0 +1,158 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Build(t *testing.T) {
+	c := NewClient("smtp.example.com", "587", "", "", mail.Address{Name: "Weather", Address: "weather@example.com"}, time.Minute)
+
+	raw, err := c.build(Mail{
+		To:      "user@example.com",
+		Subject: "Погода: Kyiv",
+		Text:    "22°C, cloudy",
+		HTML:    "<p>22°C, cloudy</p>",
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<https://weather.example.com/api/v2/unsubscribe/t>",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click\r\nBcc: victim@example.com",
+		},
+	}, "<id@example.com>", time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	require.Equal(t, `"Weather" <weather@example.com>`, msg.Header.Get("From"))
+	require.Equal(t, "<id@example.com>", msg.Header.Get("Message-ID"))
+	require.Equal(t, "<https://weather.example.com/api/v2/unsubscribe/t>", msg.Header.Get("List-Unsubscribe"))
+	require.Equal(t, "List-Unsubscribe=One-ClickBcc: victim@example.com", msg.Header.Get("List-Unsubscribe-Post"))
+	require.Empty(t, msg.Header.Get("Bcc"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, "Погода: Kyiv", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b, err := io.ReadAll(part)
+		require.NoError(t, err)
+		bodies = append(bodies, string(b))
+	}
+	require.Equal(t, []string{"22°C, cloudy", "<p>22°C, cloudy</p>"}, bodies)
+}
+
+func TestClient_Send(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go serveOneSMTP(t, ln, received)
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	c := NewClient(host, port, "", "", mail.Address{Address: "weather@example.com"}, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := c.Send(ctx, Mail{To: "user@example.com", Subject: "Hi", Text: "hello"})
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(id, "@example.com>"))
+
+	data := <-received
+	require.Contains(t, data, "MAIL FROM:<weather@example.com>")
+	require.Contains(t, data, "RCPT TO:<user@example.com>")
+	require.Contains(t, data, "Message-ID: "+id)
+}
+
+func TestClient_Send_Timeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// The relay accepts the connection but never greets.
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	c := NewClient(host, port, "", "", mail.Address{Address: "weather@example.com"}, 100*time.Millisecond)
+
+	start := time.Now()
+	_, err = c.Send(context.Background(), Mail{To: "user@example.com", Subject: "Hi", Text: "hello"})
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+// serveOneSMTP is a minimal SMTP server accepting a single message; it
+// reports the whole client transcript.
+func serveOneSMTP(t *testing.T, ln net.Listener, received chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var transcript strings.Builder
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			received <- transcript.String()
+			return
+		}
+		transcript.WriteString(line)
+
+		if inData {
+			if line == ".\r\n" {
+				inData = false
+				reply("250 queued")
+			}
+			continue
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case cmd == "DATA":
+			inData = true
+			reply("354 go ahead")
+		case cmd == "QUIT":
+			reply("221 bye")
+			received <- transcript.String()
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
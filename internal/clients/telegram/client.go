@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"strconv"
+	"time"
+)
+
+type BotClient interface {
+	GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error)
+	SendMessage(ctx context.Context, chatID int64, text string) (int64, error)
+}
+
+var _ BotClient = (*Client)(nil)
+
+// Client is a minimal Telegram Bot API client.
+type Client struct {
+	baseURL string
+	token   string
+	resty   *resty.Client
+}
+
+// NewClient creates a client for the Bot API at baseURL, normally
+// https://api.telegram.org.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		token:   token,
+		resty:   resty.New(),
+	}
+}
+
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	From      *User  `json:"from"`
+	Text      string `json:"text"`
+}
+
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+type User struct {
+	ID           int64  `json:"id"`
+	LanguageCode string `json:"language_code"`
+}
+
+type apiResponse[T any] struct {
+	OK          bool   `json:"ok"`
+	Result      T      `json:"result"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+// GetUpdates long-polls for updates with ids >= offset.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	var resp apiResponse[[]Update]
+
+	err := c.call(ctx, "getUpdates", map[string]string{
+		"offset":          strconv.FormatInt(offset, 10),
+		"timeout":         strconv.Itoa(int(timeout.Seconds())),
+		"allowed_updates": `["message"]`,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) (int64, error) {
+	var resp apiResponse[Message]
+
+	err := c.call(ctx, "sendMessage", map[string]string{
+		"chat_id": strconv.FormatInt(chatID, 10),
+		"text":    text,
+	}, &resp)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Result.MessageID, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params map[string]string, result interface {
+	failed() (bool, int, string)
+}) error {
+	_, err := c.resty.R().SetContext(ctx).
+		SetFormData(params).
+		SetResult(result).
+		SetError(result).
+		Post(fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method))
+	if err != nil {
+		return fmt.Errorf("telegram %s request error: %w", method, err)
+	}
+
+	if failed, code, description := result.failed(); failed {
+		return fmt.Errorf("telegram %s returned error %d: %s", method, code, description)
+	}
+
+	return nil
+}
+
+func (r *apiResponse[T]) failed() (bool, int, string) {
+	return !r.OK, r.ErrorCode, r.Description
+}
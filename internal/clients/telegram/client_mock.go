@@ -0,0 +1,19 @@
+package telegram
+
+import (
+	"context"
+	"time"
+)
+
+type MockBotClient struct {
+	GetUpdatesFn  func(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error)
+	SendMessageFn func(ctx context.Context, chatID int64, text string) (int64, error)
+}
+
+func (m *MockBotClient) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	return m.GetUpdatesFn(ctx, offset, timeout)
+}
+
+func (m *MockBotClient) SendMessage(ctx context.Context, chatID int64, text string) (int64, error) {
+	return m.SendMessageFn(ctx, chatID, text)
+}
@@ -5,11 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
 	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/tracing"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
 )
 
 type WeatherClient interface {
-	GetForecast(ctx context.Context, city string) (models.Weather, error)
+	GetForecast(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error)
+	// GetDailyForecast returns the forecast for today and the following
+	// days-1 days.
+	GetDailyForecast(ctx context.Context, city string, days int, prefs models.Preferences) (models.Forecast, error)
 }
 
 var _ WeatherClient = (*Client)(nil)
@@ -20,30 +29,67 @@ const apiErrCodeCityNotFound = 1006
 
 type Client struct {
 	baseURL string
-	key     string
+	key     atomic.Pointer[string]
 	resty   *resty.Client
 }
 
 func NewClient(baseURL, key string) *Client {
-	return &Client{
+	c := &Client{
 		baseURL: baseURL,
-		key:     key,
-		resty:   resty.New(),
+		resty:   tracing.InstrumentResty(resty.New()),
 	}
+	c.SetKey(key)
+	return c
+}
+
+// SetKey replaces the API key for subsequent requests, e.g. after
+// rotation.
+func (c *Client) SetKey(key string) {
+	c.key.Store(&key)
 }
 
 type weatherAPIResponse struct {
 	Location struct {
-		Name string `json:"name"`
+		Name    string `json:"name"`
+		Country string `json:"country"`
 	} `json:"location"`
 	Current struct {
-		TempC     float64 `json:"temp_c"`
-		Humidity  int     `json:"humidity"`
-		Condition struct {
+		LastUpdatedEpoch int64   `json:"last_updated_epoch"`
+		TempC            float64 `json:"temp_c"`
+		TempF            float64 `json:"temp_f"`
+		FeelsLikeC       float64 `json:"feelslike_c"`
+		FeelsLikeF       float64 `json:"feelslike_f"`
+		Humidity         int     `json:"humidity"`
+		Condition        struct {
 			Text string `json:"text"`
 		} `json:"condition"`
 		WindKph float64 `json:"wind_kph"`
+		WindMph float64 `json:"wind_mph"`
 	} `json:"current"`
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC          float64 `json:"maxtemp_c"`
+				MaxTempF          float64 `json:"maxtemp_f"`
+				MinTempC          float64 `json:"mintemp_c"`
+				MinTempF          float64 `json:"mintemp_f"`
+				AvgTempC          float64 `json:"avgtemp_c"`
+				AvgTempF          float64 `json:"avgtemp_f"`
+				MaxWindKph        float64 `json:"maxwind_kph"`
+				MaxWindMph        float64 `json:"maxwind_mph"`
+				AvgHumidity       float64 `json:"avghumidity"`
+				DailyChanceOfRain int     `json:"daily_chance_of_rain"`
+				Condition         struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"day"`
+			Astro struct {
+				Sunrise string `json:"sunrise"`
+				Sunset  string `json:"sunset"`
+			} `json:"astro"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
 }
 
 type weatherAPIErr struct {
@@ -53,33 +99,122 @@ type weatherAPIErr struct {
 	} `json:"error"`
 }
 
-func (c *Client) GetForecast(ctx context.Context, city string) (models.Weather, error) {
+// GetForecast returns the current weather in the requested units.
+// The condition text is localized by weatherapi.com when Lang is not English.
+func (c *Client) GetForecast(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+	prefs = prefs.WithDefaults()
+
+	apiResp, err := c.forecast(ctx, city, 1, prefs)
+	if err != nil {
+		return models.Weather{}, err
+	}
+
+	weather := models.Weather{
+		City:        apiResp.Location.Name,
+		Country:     apiResp.Location.Country,
+		Temperature: apiResp.Current.TempC,
+		FeelsLike:   apiResp.Current.FeelsLikeC,
+		Description: apiResp.Current.Condition.Text,
+		Humidity:    apiResp.Current.Humidity,
+		WindSpeed:   apiResp.Current.WindKph,
+		Units:       prefs.Units,
+		Lang:        prefs.Lang,
+	}
+	if prefs.Units == enums.UnitsImperial {
+		weather.Temperature = apiResp.Current.TempF
+		weather.FeelsLike = apiResp.Current.FeelsLikeF
+		weather.WindSpeed = apiResp.Current.WindMph
+	}
+	if apiResp.Current.LastUpdatedEpoch > 0 {
+		weather.UpdatedAt = time.Unix(apiResp.Current.LastUpdatedEpoch, 0).UTC()
+	}
+
+	return weather, nil
+}
+
+func (c *Client) GetDailyForecast(ctx context.Context, city string, days int, prefs models.Preferences) (models.Forecast, error) {
+	prefs = prefs.WithDefaults()
+
+	apiResp, err := c.forecast(ctx, city, days, prefs)
+	if err != nil {
+		return models.Forecast{}, err
+	}
+
+	forecast := models.Forecast{
+		City:    apiResp.Location.Name,
+		Country: apiResp.Location.Country,
+		Units:   prefs.Units,
+		Lang:    prefs.Lang,
+		Days:    make([]models.DailyForecast, 0, len(apiResp.Forecast.ForecastDay)),
+	}
+	if apiResp.Current.LastUpdatedEpoch > 0 {
+		forecast.UpdatedAt = time.Unix(apiResp.Current.LastUpdatedEpoch, 0).UTC()
+	}
+
+	for _, fd := range apiResp.Forecast.ForecastDay {
+		date, err := time.Parse(time.DateOnly, fd.Date)
+		if err != nil {
+			return models.Forecast{}, fmt.Errorf("weather api returned invalid date %q: %w", fd.Date, err)
+		}
+
+		day := models.DailyForecast{
+			Date:         date,
+			MinTemp:      fd.Day.MinTempC,
+			MaxTemp:      fd.Day.MaxTempC,
+			AvgTemp:      fd.Day.AvgTempC,
+			Description:  fd.Day.Condition.Text,
+			Humidity:     int(fd.Day.AvgHumidity),
+			MaxWindSpeed: fd.Day.MaxWindKph,
+			ChanceOfRain: fd.Day.DailyChanceOfRain,
+			Sunrise:      fd.Astro.Sunrise,
+			Sunset:       fd.Astro.Sunset,
+		}
+		if prefs.Units == enums.UnitsImperial {
+			day.MinTemp, day.MaxTemp, day.AvgTemp = fd.Day.MinTempF, fd.Day.MaxTempF, fd.Day.AvgTempF
+			day.MaxWindSpeed = fd.Day.MaxWindMph
+		}
+		forecast.Days = append(forecast.Days, day)
+	}
+
+	return forecast, nil
+}
+
+func (c *Client) forecast(ctx context.Context, city string, days int, prefs models.Preferences) (weatherAPIResponse, error) {
 	var apiResp weatherAPIResponse
 	var apiErr weatherAPIErr
 
+	params := map[string]string{
+		"key": *c.key.Load(),
+		"q":   city,
+	}
+	if days > 1 {
+		params["days"] = strconv.Itoa(days)
+	}
+	if prefs.Lang != enums.LanguageEnglish {
+		params["lang"] = prefs.Lang.String()
+	}
+
 	resp, err := c.resty.R().SetContext(ctx).
-		SetQueryParams(map[string]string{
-			"key": c.key,
-			"q":   city,
-		}).
+		SetQueryParams(params).
 		SetResult(&apiResp).
 		SetError(&apiErr).
 		Get(fmt.Sprintf("%s/forecast.json", c.baseURL))
 
 	if err != nil {
-		return models.Weather{}, fmt.Errorf("weather api request error: %w", err)
+		// Transport errors quote the URL, and with it the API key.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return weatherAPIResponse{}, fmt.Errorf("weather api request error: %w", err)
 	}
 
 	if resp.IsError() {
 		if apiErr.Error.Code == apiErrCodeCityNotFound {
-			return models.Weather{}, ErrCityNotFound
+			return weatherAPIResponse{}, ErrCityNotFound
 		}
-		return models.Weather{}, fmt.Errorf("weather api returned error: %s", apiErr.Error.Message)
+		return weatherAPIResponse{}, fmt.Errorf("weather api returned error: %s", apiErr.Error.Message)
 	}
 
-	return models.Weather{
-		Temperature: apiResp.Current.TempC,
-		Description: apiResp.Current.Condition.Text,
-		Humidity:    apiResp.Current.Humidity,
-	}, nil
+	return apiResp, nil
 }
@@ -7,9 +7,14 @@ import (
 )
 
 type MockWeatherClient struct {
-	GetForecastFn func(ctx context.Context, city string) (models.Weather, error)
+	GetForecastFn      func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error)
+	GetDailyForecastFn func(ctx context.Context, city string, days int, prefs models.Preferences) (models.Forecast, error)
 }
 
-func (m MockWeatherClient) GetForecast(ctx context.Context, city string) (models.Weather, error) {
-	return m.GetForecastFn(ctx, city)
+func (m MockWeatherClient) GetForecast(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+	return m.GetForecastFn(ctx, city, prefs)
+}
+
+func (m MockWeatherClient) GetDailyForecast(ctx context.Context, city string, days int, prefs models.Preferences) (models.Forecast, error) {
+	return m.GetDailyForecastFn(ctx, city, days, prefs)
 }
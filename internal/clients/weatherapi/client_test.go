@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,12 +16,15 @@ func TestClient_GetForecast(t *testing.T) {
 	t.Run("successful response", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			require.Equal(t, "/forecast.json", r.URL.Path)
+			require.Empty(t, r.URL.Query().Get("lang"))
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(`{
-				"location": {"name": "Kyiv"},
+				"location": {"name": "Kyiv", "country": "Ukraine"},
 				"current": {
+					"last_updated_epoch": 1747670400,
 					"temp_c": 22.5,
+					"feelslike_c": 21.8,
 					"humidity": 60,
 					"condition": {"text": "Cloudy"},
 					"wind_kph": 15
@@ -28,11 +34,51 @@ func TestClient_GetForecast(t *testing.T) {
 		defer server.Close()
 
 		c := NewClient(server.URL, "dummy-key")
-		weather, err := c.GetForecast(context.Background(), "Kyiv")
+		weather, err := c.GetForecast(context.Background(), "Kyiv", models.Preferences{})
 		require.NoError(t, err)
 		require.Equal(t, 22.5, weather.Temperature)
 		require.Equal(t, "Cloudy", weather.Description)
 		require.Equal(t, 60, weather.Humidity)
+		require.Equal(t, "Kyiv", weather.City)
+		require.Equal(t, "Ukraine", weather.Country)
+		require.Equal(t, 21.8, weather.FeelsLike)
+		require.Equal(t, 15.0, weather.WindSpeed)
+		require.Equal(t, enums.UnitsMetric, weather.Units)
+		require.Equal(t, int64(1747670400), weather.UpdatedAt.Unix())
+	})
+
+	t.Run("imperial units and localized description", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "uk", r.URL.Query().Get("lang"))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"location": {"name": "Kyiv", "country": "Ukraine"},
+				"current": {
+					"temp_c": 22.5,
+					"temp_f": 72.5,
+					"feelslike_c": 21.8,
+					"feelslike_f": 71.2,
+					"humidity": 60,
+					"condition": {"text": "Хмарно"},
+					"wind_kph": 16.1,
+					"wind_mph": 10
+				}
+			}`))
+		}))
+		defer server.Close()
+
+		c := NewClient(server.URL, "dummy-key")
+		weather, err := c.GetForecast(context.Background(), "Kyiv", models.Preferences{
+			Units: enums.UnitsImperial,
+			Lang:  "uk",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 72.5, weather.Temperature)
+		require.Equal(t, 71.2, weather.FeelsLike)
+		require.Equal(t, 10.0, weather.WindSpeed)
+		require.Equal(t, "Хмарно", weather.Description)
+		require.Equal(t, enums.Language("uk"), weather.Lang)
 	})
 
 	t.Run("city not found error", func(t *testing.T) {
@@ -49,7 +95,77 @@ func TestClient_GetForecast(t *testing.T) {
 		defer server.Close()
 
 		c := NewClient(server.URL, "dummy-key")
-		_, err := c.GetForecast(context.Background(), "UnknownCity")
+		_, err := c.GetForecast(context.Background(), "UnknownCity", models.Preferences{})
 		require.ErrorIs(t, err, ErrCityNotFound)
 	})
 }
+
+func TestClient_GetDailyForecast(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/forecast.json", r.URL.Path)
+		require.Equal(t, "3", r.URL.Query().Get("days"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"location": {"name": "Kyiv", "country": "Ukraine"},
+			"current": {"last_updated_epoch": 1747670400},
+			"forecast": {"forecastday": [
+				{
+					"date": "2025-05-19",
+					"day": {
+						"maxtemp_c": 20.1, "maxtemp_f": 68.2, "mintemp_c": 10.4, "mintemp_f": 50.7,
+						"avgtemp_c": 15.3, "avgtemp_f": 59.5, "maxwind_kph": 18, "maxwind_mph": 11.2,
+						"avghumidity": 55, "daily_chance_of_rain": 80, "condition": {"text": "Patchy rain"}
+					},
+					"astro": {"sunrise": "05:01 AM", "sunset": "08:45 PM"}
+				},
+				{"date": "2025-05-20", "day": {"maxtemp_f": 71, "mintemp_f": 52, "condition": {"text": "Sunny"}}}
+			]}
+		}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "dummy-key")
+
+	forecast, err := c.GetDailyForecast(context.Background(), "Kyiv", 3, models.Preferences{})
+	require.NoError(t, err)
+	require.Equal(t, "Kyiv", forecast.City)
+	require.Equal(t, int64(1747670400), forecast.UpdatedAt.Unix())
+	require.Len(t, forecast.Days, 2)
+
+	day := forecast.Days[0]
+	require.Equal(t, time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC), day.Date)
+	require.Equal(t, 10.4, day.MinTemp)
+	require.Equal(t, 20.1, day.MaxTemp)
+	require.Equal(t, 18.0, day.MaxWindSpeed)
+	require.Equal(t, 55, day.Humidity)
+	require.Equal(t, 80, day.ChanceOfRain)
+	require.Equal(t, "Patchy rain", day.Description)
+	require.Equal(t, "05:01 AM", day.Sunrise)
+
+	forecast, err = c.GetDailyForecast(context.Background(), "Kyiv", 3, models.Preferences{Units: enums.UnitsImperial})
+	require.NoError(t, err)
+	require.Equal(t, enums.UnitsImperial, forecast.Units)
+	require.Equal(t, 68.2, forecast.Days[0].MaxTemp)
+	require.Equal(t, 11.2, forecast.Days[0].MaxWindSpeed)
+	require.Equal(t, 52.0, forecast.Days[1].MinTemp)
+}
+
+func TestClient_SetKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.URL.Query().Get("key"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"location": {"name": "Kyiv"}}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, "old-key")
+	_, err := c.GetForecast(context.Background(), "Kyiv", models.Preferences{})
+	require.NoError(t, err)
+
+	c.SetKey("new-key")
+	_, err = c.GetForecast(context.Background(), "Kyiv", models.Preferences{})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"old-key", "new-key"}, keys)
+}
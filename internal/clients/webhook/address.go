@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrPrivateAddress is returned for endpoints that resolve to addresses a
+// webhook must not reach: loopback, private, link-local, multicast and
+// unspecified ones. Without the check anyone able to subscribe could make
+// the service send requests into its own network.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// nonPublicPrefixes are special-purpose ranges not covered by the netip
+// predicates used in CheckAddr.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// CheckAddr returns ErrPrivateAddress unless addr is a public unicast
+// address.
+func CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+		}
+	}
+	return nil
+}
+
+// LookupFunc resolves a host name, like net.Resolver.LookupNetIP.
+type LookupFunc func(ctx context.Context, host string) ([]netip.Addr, error)
+
+// LookupHost resolves with the system resolver.
+func LookupHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+// CheckHost resolves host, unless it is an IP literal, and checks every
+// address it resolves to.
+func CheckHost(ctx context.Context, lookup LookupFunc, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return CheckAddr(addr)
+	}
+
+	addrs, err := lookup(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("resolve %s: no addresses", host)
+	}
+	for _, addr := range addrs {
+		if err := CheckAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dialControl rejects connections to non-public addresses. It runs for the
+// address actually dialed, so DNS rebinding and redirects cannot get past
+// the check made on subscribe.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	return CheckAddr(addrPort.Addr())
+}
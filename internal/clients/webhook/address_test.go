@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddr(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::248": true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"100.64.0.1":           false,
+		"224.0.0.1":            false,
+		"0.0.0.0":              false,
+		"::":                   false,
+		"::ffff:127.0.0.1":     false,
+	} {
+		err := CheckAddr(netip.MustParseAddr(addr))
+		if public {
+			require.NoError(t, err, addr)
+		} else {
+			require.ErrorIs(t, err, ErrPrivateAddress, addr)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	lookup := func(ctx context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "hooks.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "internal.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	require.NoError(t, CheckHost(context.Background(), lookup, "hooks.example.com"))
+	require.ErrorIs(t, CheckHost(context.Background(), lookup, "internal.example.com"), ErrPrivateAddress,
+		"any private address is enough")
+	require.ErrorIs(t, CheckHost(context.Background(), lookup, "169.254.169.254"), ErrPrivateAddress)
+	require.Error(t, CheckHost(context.Background(), lookup, "missing.example.com"))
+}
+
+func TestClient_RefusesPrivateAddresses(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second, false).Post(context.Background(), server.URL, testSecret, Request{ID: "1", Body: []byte("{}")})
+	require.ErrorIs(t, err, ErrPrivateAddress)
+	require.False(t, called)
+}
@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every webhook request. The signature is
+// "v1=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signatureVersion = "v1"
+)
+
+// EventVerification is sent once on subscribe; the endpoint must answer
+// with the challenge from the payload.
+const EventVerification = "verification"
+
+type Sender interface {
+	// Post delivers a signed payload and returns the response status code.
+	// A non-2xx status is not an error.
+	Post(ctx context.Context, url, secret string, req Request) (int, error)
+	// Verify performs the challenge handshake with the endpoint.
+	Verify(ctx context.Context, url, secret string) error
+}
+
+var _ Sender = (*Client)(nil)
+
+var ErrChallengeMismatch = errors.New("webhook endpoint did not echo the challenge")
+
+type Client struct {
+	resty *resty.Client
+}
+
+// NewClient only connects to public addresses unless allowPrivate is set,
+// e.g. for local development. Proxies from the environment are then not
+// used either, as the dialer would only see the proxy address.
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = dialControl
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &Client{resty: resty.New().SetTimeout(timeout).SetTransport(transport)}
+}
+
+// Request is a single webhook delivery. ID stays the same across retries so
+// receivers can deduplicate.
+type Request struct {
+	ID    string
+	Event string
+	Body  []byte
+}
+
+type VerificationPayload struct {
+	Event     string `json:"event"`
+	Challenge string `json:"challenge"`
+}
+
+type verificationResponse struct {
+	Challenge string `json:"challenge"`
+}
+
+func (c *Client) Post(ctx context.Context, url, secret string, req Request) (int, error) {
+	resp, err := c.post(ctx, url, secret, req)
+	if err != nil {
+		return 0, err
+	}
+	return resp.StatusCode(), nil
+}
+
+// Verify sends a signed challenge and expects a 2xx response whose body is
+// either the bare challenge or {"challenge": "..."}.
+func (c *Client) Verify(ctx context.Context, url, secret string) error {
+	challenge := NewID()
+	body, err := json.Marshal(VerificationPayload{Event: EventVerification, Challenge: challenge})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(ctx, url, secret, Request{ID: NewID(), Event: EventVerification, Body: body})
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("webhook verification returned status %d", resp.StatusCode())
+	}
+
+	got := strings.TrimSpace(resp.String())
+	var parsed verificationResponse
+	if json.Unmarshal(resp.Body(), &parsed) == nil && parsed.Challenge != "" {
+		got = parsed.Challenge
+	}
+	if !hmac.Equal([]byte(got), []byte(challenge)) {
+		return ErrChallengeMismatch
+	}
+
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, url, secret string, req Request) (*resty.Response, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	resp, err := c.resty.R().SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader(HeaderID, req.ID).
+		SetHeader(HeaderEvent, req.Event).
+		SetHeader(HeaderTimestamp, timestamp).
+		SetHeader(HeaderSignature, Sign(secret, timestamp, req.Body)).
+		SetBody(req.Body).
+		Post(url)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request error: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewID returns a random identifier for deliveries and challenges.
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
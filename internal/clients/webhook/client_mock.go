@@ -0,0 +1,16 @@
+package webhook
+
+import "context"
+
+type MockSender struct {
+	PostFn   func(ctx context.Context, url, secret string, req Request) (int, error)
+	VerifyFn func(ctx context.Context, url, secret string) error
+}
+
+func (m *MockSender) Post(ctx context.Context, url, secret string, req Request) (int, error) {
+	return m.PostFn(ctx, url, secret, req)
+}
+
+func (m *MockSender) Verify(ctx context.Context, url, secret string) error {
+	return m.VerifyFn(ctx, url, secret)
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "s3cr3t"
+
+func TestSign(t *testing.T) {
+	// Reference value: printf '1700000000.{}' | openssl dgst -sha256 -hmac s3cr3t
+	require.Equal(t,
+		"v1=dd8508e44d9a9f82f2690fb7dff1da8a6ae99700d98a23a4e7e1c307af3cb6cb",
+		Sign(testSecret, "1700000000", []byte("{}")),
+	)
+}
+
+func TestClient_Post(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	status, err := NewClient(time.Second, true).Post(context.Background(), server.URL, testSecret, Request{
+		ID:    "delivery-1",
+		Event: "daily_digest",
+		Body:  []byte(`{"city":"Kyiv"}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, status)
+
+	require.Equal(t, `{"city":"Kyiv"}`, string(body))
+	require.Equal(t, "delivery-1", got.Header.Get(HeaderID))
+	require.Equal(t, "daily_digest", got.Header.Get(HeaderEvent))
+	require.Equal(t, Sign(testSecret, got.Header.Get(HeaderTimestamp), body), got.Header.Get(HeaderSignature))
+}
+
+func TestClient_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(w http.ResponseWriter, challenge string)
+		wantErr bool
+	}{
+		{
+			name: "json echo",
+			respond: func(w http.ResponseWriter, challenge string) {
+				_ = json.NewEncoder(w).Encode(map[string]string{"challenge": challenge})
+			},
+		},
+		{
+			name: "plain echo",
+			respond: func(w http.ResponseWriter, challenge string) {
+				_, _ = io.WriteString(w, challenge+"\n")
+			},
+		},
+		{
+			name: "wrong challenge",
+			respond: func(w http.ResponseWriter, challenge string) {
+				_, _ = io.WriteString(w, "ok")
+			},
+			wantErr: true,
+		},
+		{
+			name: "error status",
+			respond: func(w http.ResponseWriter, challenge string) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, challenge)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload VerificationPayload
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				require.Equal(t, EventVerification, payload.Event)
+				require.Equal(t, EventVerification, r.Header.Get(HeaderEvent))
+				tt.respond(w, payload.Challenge)
+			}))
+			defer server.Close()
+
+			err := NewClient(time.Second, true).Verify(context.Background(), server.URL, testSecret)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -4,30 +4,227 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"os"
 	"time"
-
-	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	Env        EnvType    `envconfig:"ENV" required:"true" default:"dev"`
-	Debug      bool       `envconfig:"DEBUG" default:"false"`
-	Port       string     `envconfig:"PORT" default:"8080"`
-	DB         DB         `envconfig:"DB"`
-	WeatherAPI WeatherAPI `envconfig:"WEATHER_API"`
+	Env             EnvType       `env:"ENV" required:"true" default:"dev"`
+	Debug           bool          `env:"DEBUG" default:"false"`
+	Port            string        `env:"PORT" default:"8080"`
+	HTTP            HTTP          `env:"HTTP"`
+	GRPC            GRPC          `env:"GRPC"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"10s"`
+	// SecretReloadInterval is how often secrets read from *_FILE
+	// variables are checked for rotation; zero disables reloading.
+	SecretReloadInterval time.Duration `split_words:"true" default:"1m"`
+	DB                   DB            `env:"DB"`
+	WeatherAPI           WeatherAPI    `env:"WEATHER_API"`
+	API                  API           `env:"API"`
+	Auth                 Auth          `env:"AUTH"`
+	Notification         Notification  `env:"NOTIFICATION"`
+	Telegram             Telegram      `env:"TELEGRAM"`
+	Webhook              Webhook       `env:"WEBHOOK"`
+	Stream               Stream        `env:"STREAM"`
+	Feed                 Feed          `env:"FEED"`
+	Scheduler            Scheduler     `env:"SCHEDULER"`
+	Email                Email         `env:"EMAIL"`
+	Tokens               Tokens        `env:"TOKENS"`
+	Tracing              Tracing       `env:"TRACING"`
+	Log                  Log           `env:"LOG"`
+
+	// secretFiles maps secret keys to the files they were read from.
+	secretFiles map[string]string
+}
+
+// SecretFiles returns the files secrets were read from, by key, e.g.
+// "DB_PASSWORD" when DB_PASSWORD_FILE is set.
+func (c *Config) SecretFiles() map[string]string {
+	return c.secretFiles
+}
+
+// Log configures the application logger. Level is the initial level (it
+// can be changed at runtime through /admin/log-level); Format is "json" or
+// "console". When SamplingInitial is set, only the first SamplingInitial
+// entries with the same level and message per second are logged, and then
+// every SamplingThereafter-th.
+type Log struct {
+	Level              string `env:"LEVEL" default:"info"`
+	Format             string `env:"FORMAT" default:"json"`
+	SamplingInitial    int    `split_words:"true"`
+	SamplingThereafter int    `split_words:"true" default:"100"`
+}
+
+// Tracing exports OpenTelemetry traces over OTLP/HTTP to Endpoint
+// (host:port of a collector); tracing is disabled when it is empty.
+// SampleRatio is the share of new traces recorded; incoming requests
+// keep the sampling decision of their caller.
+type Tracing struct {
+	Endpoint    string  `env:"ENDPOINT"`
+	Insecure    bool    `env:"INSECURE"`
+	ServiceName string  `split_words:"true" default:"weather-subscription-service"`
+	SampleRatio float64 `split_words:"true" default:"1"`
+}
+
+// Tokens selects how subscription tokens are issued: "random" hands out
+// the token stored with the subscription, "signed" issues HMAC-signed
+// tokens scoped to a purpose that expire after the purpose's TTL (zero
+// means never). Keys are "id:secret" pairs; the first one signs and all of
+// them verify, which allows rotation without invalidating issued links.
+type Tokens struct {
+	Strategy       string        `env:"STRATEGY" default:"random"`
+	Keys           []string      `env:"KEYS" secret:"true"`
+	ConfirmTTL     time.Duration `env:"CONFIRM_TTL" default:"72h"`
+	UnsubscribeTTL time.Duration `env:"UNSUBSCRIBE_TTL" default:"2160h"`
+	ManageTTL      time.Duration `env:"MANAGE_TTL"`
+}
+
+// Email configures email delivery. Email updates are sent through the
+// SMTP relay at SMTPHost, which enables the channel when set; each message
+// must be sent within SMTPTimeout. EventsToken
+// enables the bounce and complaint endpoint and authenticates the mail
+// provider calling it.
+type Email struct {
+	SMTPHost     string        `env:"SMTP_HOST"`
+	SMTPPort     string        `env:"SMTP_PORT" default:"587"`
+	SMTPUsername string        `env:"SMTP_USERNAME"`
+	SMTPPassword string        `env:"SMTP_PASSWORD" secret:"true"`
+	SMTPTimeout  time.Duration `env:"SMTP_TIMEOUT" default:"30s"`
+	From         string        `env:"FROM" default:"Weather Service <weather@localhost>"`
+	EventsToken  string        `split_words:"true" secret:"true"`
+}
+
+// Scheduler configures periodic updates. Every Interval each replica claims
+// due subscriptions in batches of BatchSize, leased for Lease; Lease must
+// exceed the time needed to dispatch a batch. Daily updates are sent at
+// DailyHour UTC. Concurrency bounds parallel weather requests and
+// deliveries within a batch.
+type Scheduler struct {
+	Enabled     bool          `env:"ENABLED" default:"true"`
+	Interval    time.Duration `env:"INTERVAL" default:"30s"`
+	BatchSize   int           `split_words:"true" default:"100"`
+	Lease       time.Duration `env:"LEASE" default:"5m"`
+	DailyHour   int           `split_words:"true" default:"8"`
+	Concurrency int           `env:"CONCURRENCY" default:"8"`
+}
+
+// Feed configures the iCalendar and RSS forecast feeds. Days is limited by
+// the weatherapi.com plan (3 on the free plan).
+type Feed struct {
+	Days   int           `env:"DAYS" default:"3"`
+	MaxAge time.Duration `split_words:"true" default:"30m"`
+}
+
+// HTTP configures the HTTP server. Zero timeouts mean none; the write
+// timeout does not apply to the SSE stream. HTTPS is served when
+// TLSCertFile and TLSKeyFile are set; renewed files are picked up without
+// a restart.
+type HTTP struct {
+	ReadHeaderTimeout time.Duration `split_words:"true" default:"5s"`
+	ReadTimeout       time.Duration `split_words:"true" default:"30s"`
+	WriteTimeout      time.Duration `split_words:"true" default:"30s"`
+	IdleTimeout       time.Duration `split_words:"true" default:"2m"`
+	TLSCertFile       string        `env:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `env:"TLS_KEY_FILE"`
+}
+
+// TLS reports whether HTTPS is configured.
+func (h *HTTP) TLS() bool {
+	return h.TLSCertFile != "" && h.TLSKeyFile != ""
+}
+
+// GRPC enables the gRPC API on Port; an empty Port disables it.
+type GRPC struct {
+	Port string `env:"PORT" default:"9090"`
+}
+
+// Stream configures the live weather SSE endpoint. Each watched city is
+// polled once per PollInterval regardless of the number of listeners;
+// at most MaxCities cities are watched at a time, 0 meaning no limit.
+type Stream struct {
+	PollInterval      time.Duration `split_words:"true" default:"1m"`
+	HeartbeatInterval time.Duration `split_words:"true" default:"15s"`
+	MaxCities         int           `split_words:"true" default:"100"`
+}
+
+// Webhook configures outgoing webhook deliveries. A delivery is retried
+// with exponential backoff from InitialBackoff up to MaxBackoff until
+// MaxAttempts is reached. AllowInsecure permits plain http endpoints and
+// AllowPrivate endpoints on loopback and private networks; both are meant
+// for local development only.
+type Webhook struct {
+	Timeout        time.Duration `split_words:"true" default:"10s"`
+	MaxAttempts    int           `split_words:"true" default:"5"`
+	InitialBackoff time.Duration `split_words:"true" default:"1s"`
+	MaxBackoff     time.Duration `split_words:"true" default:"1m"`
+	AllowInsecure  bool          `split_words:"true"`
+	AllowPrivate   bool          `split_words:"true"`
+}
+
+// Telegram enables the bot when BotToken is set.
+type Telegram struct {
+	BotToken    string        `split_words:"true" secret:"true"`
+	APIURL      string        `env:"API_URL" default:"https://api.telegram.org"`
+	PollTimeout time.Duration `split_words:"true" default:"30s"`
+}
+
+// Notification configures outgoing message content. TemplatesDir, when set,
+// overrides the embedded templates file by file (<lang>/<kind>.tmpl).
+// BaseURL is the public address used to build links in messages.
+type Notification struct {
+	TemplatesDir string `split_words:"true"`
+	BaseURL      string `split_words:"true" default:"http://localhost:8080"`
+}
+
+// API describes the lifecycle of the public API versions.
+// V1DeprecatedAt and V1Sunset are advertised to v1 clients via the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) response headers. They are
+// set per deployment; a header is not sent while its date is unset.
+type API struct {
+	V1DeprecatedAt time.Time `env:"V1_DEPRECATED_AT"`
+	V1Sunset       time.Time `env:"V1_SUNSET"`
+}
+
+// Auth configures access control. Routes are gin route patterns,
+// e.g. "/api/v2/weather" or "/api/v2/confirm/:token".
+// Admin routes are only registered when AdminToken is set.
+type Auth struct {
+	AdminToken           string   `split_words:"true" secret:"true"`
+	APIKeyRequiredRoutes []string `env:"API_KEY_REQUIRED_ROUTES" default:"/api/v2/weather"`
+	APIKeyOptionalRoutes []string `env:"API_KEY_OPTIONAL_ROUTES"`
 }
 
 type WeatherAPI struct {
 	BaseURL string `split_words:"true" required:"true"`
-	Key     string `split_words:"true" required:"true"`
+	Key     string `split_words:"true" required:"true" secret:"true"`
 }
 
+const (
+	DBDriverPostgres = "postgres"
+	DBDriverSQLite   = "sqlite"
+	DBDriverMemory   = "memory"
+)
+
 type DB struct {
-	Host     string `required:"true"`
-	Port     string `required:"true"`
-	User     string `required:"true"`
-	Password string `required:"true"`
-	Database string `required:"true"`
+	// Driver is postgres, sqlite or memory. SQLite keeps everything in
+	// the file at Path and ignores the connection settings below; it suits
+	// a single instance only. Memory loses everything on restart and is
+	// meant for development.
+	Driver string `default:"postgres"`
+	Path   string `default:"weather.db"`
+
+	Host     string
+	Port     string
+	User     string
+	Password string `secret:"true"`
+	Database string
+	// SSLMode is passed to lib/pq: disable, require, verify-ca or verify-full.
+	// SSLRootCert verifies the server; SSLCert and SSLKey authenticate the
+	// client. All are file paths.
+	SSLMode     string `split_words:"true" default:"disable"`
+	SSLRootCert string `split_words:"true"`
+	SSLCert     string `split_words:"true"`
+	SSLKey      string `split_words:"true"`
 
 	MaxOpenConns    int           `default:"5" split_words:"true"`
 	MaxIdleConns    int           `default:"5" split_words:"true"`
@@ -35,23 +232,66 @@ type DB struct {
 }
 
 func (c *DB) ConnectionURL() string {
+	query := url.Values{
+		"sslmode": []string{c.SSLMode},
+	}
+	for param, value := range map[string]string{
+		"sslrootcert": c.SSLRootCert,
+		"sslcert":     c.SSLCert,
+		"sslkey":      c.SSLKey,
+	} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+
 	connURL := url.URL{
-		Scheme: "postgres",
-		User:   url.UserPassword(c.User, c.Password),
-		Host:   net.JoinHostPort(c.Host, c.Port),
-		Path:   c.Database,
-		RawQuery: url.Values{
-			"sslmode": []string{"disable"},
-		}.Encode(),
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     c.Database,
+		RawQuery: query.Encode(),
 	}
 
 	return connURL.String()
 }
 
-func Load() (*Config, error) {
-	var cfg Config
-	if err := envconfig.Process("", &cfg); err != nil {
+// Load reads the configuration from the environment, layered over the
+// YAML or TOML file at path when path is set, and validates it. Keys in
+// the file are named after the environment variables (see readFile);
+// environment variables take precedence. Secrets may instead be read from
+// the file named by their *_FILE key.
+func Load(path string) (*Config, error) {
+	fileValues := make(map[string]string)
+	if path != "" {
+		var err error
+		if fileValues, err = readFile(path); err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+	}
+
+	secrets, secretFiles, err := readSecretFiles(fileValues)
+	if err != nil {
 		return nil, fmt.Errorf("load config: %w", err)
 	}
+	for key, value := range secrets {
+		fileValues[key] = value
+	}
+
+	cfg := Config{secretFiles: secretFiles}
+	// Variables set in the environment take precedence over the file.
+	lookup := func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := fileValues[key]
+		return value, ok
+	}
+	if err := process(&cfg, lookup); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 	return &cfg, nil
 }
@@ -0,0 +1,279 @@
+package configs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setRequiredEnv(t *testing.T) {
+	for key, value := range map[string]string{
+		"DB_HOST":              "localhost",
+		"DB_PORT":              "5432",
+		"DB_USER":              "postgres",
+		"DB_PASSWORD":          "db-password",
+		"DB_DATABASE":          "weather",
+		"WEATHER_API_BASE_URL": "https://api.weatherapi.com/v1",
+		"WEATHER_API_KEY":      "wx-key",
+	} {
+		t.Setenv(key, value)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("environment only", func(t *testing.T) {
+		setRequiredEnv(t)
+
+		cfg, err := Load("")
+		require.NoError(t, err)
+		require.Equal(t, "8080", cfg.Port)
+		require.Equal(t, "9090", cfg.GRPC.Port)
+		require.Equal(t, "disable", cfg.DB.SSLMode)
+		require.Equal(t, []string{"/api/v2/weather"}, cfg.Auth.APIKeyRequiredRoutes, "v1 and the stream stay open")
+	})
+
+	t.Run("yaml file under environment", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("DB_MAX_OPEN_CONNS", "20")
+		path := writeFile(t, "config.yaml", `
+port: 8081
+db:
+  host: db.internal
+  max_open_conns: 10
+  ssl_mode: verify-full
+auth:
+  api_key_required_routes: [/api/v2/weather, /api/v2/subscribe]
+scheduler:
+  interval: 1m
+`)
+
+		cfg, err := Load(path)
+		require.NoError(t, err)
+		require.Equal(t, "8081", cfg.Port)
+		require.Equal(t, "9090", cfg.GRPC.Port, "not read from PORT")
+		// Environment variables take precedence over the file.
+		require.Equal(t, "localhost", cfg.DB.Host)
+		require.Equal(t, 20, cfg.DB.MaxOpenConns)
+		require.Equal(t, "verify-full", cfg.DB.SSLMode)
+		require.Equal(t, []string{"/api/v2/weather", "/api/v2/subscribe"}, cfg.Auth.APIKeyRequiredRoutes)
+		require.Equal(t, time.Minute, cfg.Scheduler.Interval)
+		require.Contains(t, cfg.DB.ConnectionURL(), "sslmode=verify-full")
+
+		_, set := os.LookupEnv("PORT")
+		require.False(t, set, "file values must not leak into the environment")
+	})
+
+	t.Run("toml file provides required values", func(t *testing.T) {
+		t.Setenv("WEATHER_API_BASE_URL", "https://api.weatherapi.com/v1")
+		t.Setenv("WEATHER_API_KEY", "wx-key")
+		path := writeFile(t, "config.toml", `
+[db]
+host = "localhost"
+port = 5432
+user = "postgres"
+password = "db-password"
+database = "weather"
+
+[tracing]
+sample_ratio = 0.25
+`)
+
+		cfg, err := Load(path)
+		require.NoError(t, err)
+		require.Equal(t, "5432", cfg.DB.Port)
+		require.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		setRequiredEnv(t)
+		path := writeFile(t, "config.yaml", "db:\n  hots: localhost\n")
+
+		_, err := Load(path)
+		require.ErrorContains(t, err, "unknown keys DB_HOTS")
+	})
+
+	t.Run("invalid values are reported together", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("PORT", "http")
+		t.Setenv("DB_SSL_MODE", "off")
+		t.Setenv("WEATHER_API_BASE_URL", "api.weatherapi.com")
+		t.Setenv("SCHEDULER_DAILY_HOUR", "24")
+
+		_, err := Load("")
+		require.Error(t, err)
+		for _, key := range []string{"PORT", "DB_SSL_MODE", "WEATHER_API_BASE_URL", "SCHEDULER_DAILY_HOUR"} {
+			require.ErrorContains(t, err, key+":")
+		}
+	})
+
+	t.Run("malformed values name their key", func(t *testing.T) {
+		setRequiredEnv(t)
+		path := writeFile(t, "config.yaml", "scheduler:\n  interval: soon\n")
+
+		_, err := Load(path)
+		require.ErrorContains(t, err, "SCHEDULER_INTERVAL")
+
+		t.Setenv("DB_MAX_OPEN_CONNS", "many")
+		_, err = Load("")
+		require.ErrorContains(t, err, "DB_MAX_OPEN_CONNS")
+	})
+
+	t.Run("missing required values", func(t *testing.T) {
+		setRequiredEnv(t)
+		os.Unsetenv("WEATHER_API_KEY")
+
+		_, err := Load("")
+		require.ErrorContains(t, err, "required key WEATHER_API_KEY missing value")
+	})
+
+	t.Run("sqlite and memory need no postgres settings", func(t *testing.T) {
+		for _, key := range []string{"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_DATABASE"} {
+			t.Setenv(key, "")
+		}
+		t.Setenv("WEATHER_API_BASE_URL", "https://api.weatherapi.com/v1")
+		t.Setenv("WEATHER_API_KEY", "wx-key")
+
+		_, err := Load("")
+		require.ErrorContains(t, err, "DB_HOST: required by the postgres driver")
+
+		t.Setenv("DB_DRIVER", "sqlite")
+		cfg, err := Load("")
+		require.NoError(t, err)
+		require.Equal(t, "weather.db", cfg.DB.Path)
+
+		t.Setenv("DB_DRIVER", "memory")
+		_, err = Load("")
+		require.NoError(t, err)
+
+		t.Setenv("DB_DRIVER", "mysql")
+		_, err = Load("")
+		require.ErrorContains(t, err, "DB_DRIVER:")
+	})
+}
+
+func TestConfig_Print(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("TOKENS_KEYS", "k1:0123456789abcdef0123456789abcdef")
+	cfg, err := Load("")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out, false))
+	require.Contains(t, out.String(), "DB_PASSWORD=[REDACTED]\n")
+	require.Contains(t, out.String(), "WEATHER_API_KEY=[REDACTED]\n")
+	require.Contains(t, out.String(), "TOKENS_KEYS=[REDACTED]\n")
+	require.Contains(t, out.String(), "AUTH_ADMIN_TOKEN=\n")
+	require.Contains(t, out.String(), "SCHEDULER_LEASE=5m0s\n")
+	require.NotContains(t, out.String(), "db-password")
+
+	out.Reset()
+	require.NoError(t, cfg.Print(&out, true))
+	require.Contains(t, out.String(), "DB_PASSWORD=db-password\n")
+
+	require.ElementsMatch(t, []string{"db-password", "wx-key", "", "", "", "",
+		"k1:0123456789abcdef0123456789abcdef", "0123456789abcdef0123456789abcdef"}, cfg.Secrets())
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	t.Run("secrets are read from files", func(t *testing.T) {
+		setRequiredEnv(t)
+		os.Unsetenv("DB_PASSWORD")
+		passwordFile := writeFile(t, "db_password", "from-file\n")
+		t.Setenv("DB_PASSWORD_FILE", passwordFile)
+		keyFile := writeFile(t, "weather_key", "key-from-file")
+		os.Unsetenv("WEATHER_API_KEY")
+		configFile := writeFile(t, "config.yaml", "weather_api:\n  key_file: "+keyFile+"\n")
+
+		cfg, err := Load(configFile)
+		require.NoError(t, err)
+		require.Equal(t, "from-file", cfg.DB.Password)
+		require.Equal(t, "key-from-file", cfg.WeatherAPI.Key)
+		require.Equal(t, map[string]string{"DB_PASSWORD": passwordFile, "WEATHER_API_KEY": keyFile}, cfg.SecretFiles())
+	})
+
+	t.Run("value and file are exclusive", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "from-file"))
+
+		_, err := Load("")
+		require.ErrorContains(t, err, "both DB_PASSWORD and DB_PASSWORD_FILE are set")
+	})
+
+	t.Run("only secrets have file variants", func(t *testing.T) {
+		setRequiredEnv(t)
+		configFile := writeFile(t, "config.yaml", "db:\n  host_file: /run/secrets/host\n")
+
+		_, err := Load(configFile)
+		require.ErrorContains(t, err, "unknown keys DB_HOST_FILE")
+	})
+}
+
+func TestSecretWatcher(t *testing.T) {
+	path := writeFile(t, "weather_key", "old")
+	w := NewSecretWatcher(map[string]string{"WEATHER_API_KEY": path}, time.Hour, zap.NewNop())
+
+	var got []string
+	w.OnChange("WEATHER_API_KEY", func(value string) { got = append(got, value) })
+
+	w.Check()
+	require.Empty(t, got)
+
+	require.NoError(t, os.WriteFile(path, []byte("new\n"), 0o600))
+	w.Check()
+	w.Check()
+	require.Equal(t, []string{"new"}, got)
+
+	// A missing or emptied file keeps the last value.
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	w.Check()
+	require.NoError(t, os.Remove(path))
+	w.Check()
+	require.Equal(t, []string{"new"}, got)
+}
+
+func TestSecretWatcher_Prune(t *testing.T) {
+	w := NewSecretWatcher(map[string]string{
+		"WEATHER_API_KEY":    writeFile(t, "weather_key", "key"),
+		"TELEGRAM_BOT_TOKEN": writeFile(t, "bot_token", "token"),
+	}, time.Hour, zap.NewNop())
+	w.OnChange("WEATHER_API_KEY", func(string) {})
+
+	require.Equal(t, []string{"TELEGRAM_BOT_TOKEN", "WEATHER_API_KEY"}, w.Keys())
+	w.Prune()
+	require.Equal(t, []string{"WEATHER_API_KEY"}, w.Keys(), "nothing applies a new bot token")
+}
+
+func TestDB_ConnectionURL(t *testing.T) {
+	db := DB{
+		Host: "db", Port: "5432", User: "app", Password: "p@ss", Database: "weather",
+		SSLMode: "verify-full", SSLRootCert: "/certs/ca.pem", SSLCert: "/certs/client.pem", SSLKey: "/certs/client.key",
+	}
+	require.Equal(t,
+		"postgres://app:p%40ss@db:5432/weather?sslcert=%2Fcerts%2Fclient.pem&sslkey=%2Fcerts%2Fclient.key&sslmode=verify-full&sslrootcert=%2Fcerts%2Fca.pem",
+		db.ConnectionURL())
+
+	db.SSLMode, db.SSLRootCert, db.SSLCert, db.SSLKey = "disable", "", "", ""
+	require.Equal(t, "postgres://app:p%40ss@db:5432/weather?sslmode=disable", db.ConnectionURL())
+}
+
+func TestLoad_TLSFiles(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("HTTP_TLS_CERT_FILE", writeFile(t, "tls.crt", "cert"))
+	t.Setenv("DB_SSL_ROOT_CERT", filepath.Join(t.TempDir(), "missing.pem"))
+
+	_, err := Load("")
+	require.ErrorContains(t, err, "HTTP_TLS_KEY_FILE: must be set together with HTTP_TLS_CERT_FILE")
+	require.ErrorContains(t, err, "DB_SSL_ROOT_CERT: open")
+	require.ErrorContains(t, err, "DB_SSL_MODE: must not be disable when certificates are set")
+}
@@ -0,0 +1,191 @@
+package configs
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Configuration structs are read from environment variables, one per leaf
+// field, with these struct tags:
+//
+//	env:"NAME"          variable name of the field, default the upper-cased field name
+//	split_words:"true"  without env, split the field name into words: SSLRootCert is SSL_ROOT_CERT
+//	default:"value"     used when the variable is not set
+//	required:"true"     fail when the variable is not set and there is no default
+//	secret:"true"       the value is a credential, see Config.Secrets
+//
+// A nested struct prefixes the names of its fields with its own name, so
+// Port in GRPC is read from GRPC_PORT only.
+
+// Decoder is implemented by field types that parse their own value.
+type Decoder interface {
+	Decode(value string) error
+}
+
+// field is a leaf of the configuration with its environment variable name
+// and the tags used to load it.
+type field struct {
+	Key      string
+	Name     string
+	Value    reflect.Value
+	Secret   bool
+	Default  string
+	Required bool
+}
+
+// Word splitting for split_words.
+var (
+	gatherRegexp  = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// fields lists the leaves of spec, a pointer to a struct, in declaration
+// order.
+func fields(spec any) []field {
+	return structFields("", reflect.ValueOf(spec).Elem())
+}
+
+func structFields(prefix string, v reflect.Value) []field {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key := sf.Name
+		if sf.Tag.Get("split_words") == "true" {
+			var words []string
+			for _, w := range gatherRegexp.FindAllString(sf.Name, -1) {
+				if m := acronymRegexp.FindStringSubmatch(w); len(m) == 3 {
+					words = append(words, m[1], m[2])
+				} else {
+					words = append(words, w)
+				}
+			}
+			key = strings.Join(words, "_")
+		}
+		if name := sf.Tag.Get("env"); name != "" {
+			key = name
+		}
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		key = strings.ToUpper(key)
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && !isDecodable(fv) {
+			out = append(out, structFields(key, fv)...)
+			continue
+		}
+		out = append(out, field{
+			Key:      key,
+			Name:     sf.Name,
+			Value:    fv,
+			Secret:   sf.Tag.Get("secret") == "true",
+			Default:  sf.Tag.Get("default"),
+			Required: sf.Tag.Get("required") == "true",
+		})
+	}
+	return out
+}
+
+func isDecodable(v reflect.Value) bool {
+	switch v.Addr().Interface().(type) {
+	case Decoder, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
+// process fills spec, a pointer to a struct, with the values lookup
+// returns for its fields, or their defaults.
+func process(spec any, lookup func(key string) (string, bool)) error {
+	for _, f := range fields(spec) {
+		value, ok := lookup(f.Key)
+		if !ok {
+			if f.Default == "" {
+				if f.Required {
+					return fmt.Errorf("required key %s missing value", f.Key)
+				}
+				continue
+			}
+			value = f.Default
+		}
+
+		if err := decode(value, f.Value); err != nil {
+			return fmt.Errorf("%s: parse %q as %s: %w", f.Key, value, f.Value.Type(), err)
+		}
+	}
+	return nil
+}
+
+// decode parses value into v. Decoder and encoding.TextUnmarshaler take
+// precedence over the kind of v; slices are comma-separated items.
+func decode(value string, v reflect.Value) error {
+	switch d := v.Addr().Interface().(type) {
+	case Decoder:
+		return d.Decode(value)
+	case encoding.TextUnmarshaler:
+		return d.UnmarshalText([]byte(value))
+	}
+
+	typ := v.Type()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 0, typ.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, typ.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		sl := reflect.MakeSlice(typ, 0, 0)
+		if strings.TrimSpace(value) != "" {
+			items := strings.Split(value, ",")
+			sl = reflect.MakeSlice(typ, len(items), len(items))
+			for i, item := range items {
+				if err := decode(item, sl.Index(i)); err != nil {
+					return err
+				}
+			}
+		}
+		v.Set(sl)
+	default:
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package configs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields(t *testing.T) {
+	var spec struct {
+		Name     string
+		HTTPPort int    `split_words:"true"`
+		Renamed  string `env:"OTHER" split_words:"true"`
+		Nested   struct {
+			MaxSize int    `split_words:"true" default:"5"`
+			Token   string `env:"TOKEN" secret:"true" required:"true"`
+		}
+		Tagged struct {
+			Port string
+		} `env:"GRPC"`
+		At     time.Time
+		Env    EnvType
+		hidden string
+	}
+
+	var keys []string
+	for _, f := range fields(&spec) {
+		keys = append(keys, f.Key)
+	}
+	require.Equal(t, []string{"NAME", "HTTP_PORT", "OTHER", "NESTED_MAX_SIZE", "NESTED_TOKEN", "GRPC_PORT", "AT", "ENV"}, keys)
+
+	nested := fields(&spec)[3:5]
+	require.Equal(t, field{Key: "NESTED_MAX_SIZE", Name: "MaxSize", Value: nested[0].Value, Default: "5"}, nested[0])
+	require.True(t, nested[1].Secret)
+	require.True(t, nested[1].Required)
+
+	cfgKeys := make(map[string]bool)
+	for _, f := range fields(&Config{}) {
+		require.False(t, cfgKeys[f.Key], "duplicate key %s", f.Key)
+		cfgKeys[f.Key] = true
+	}
+	for _, key := range []string{"ENV", "SECRET_RELOAD_INTERVAL", "GRPC_PORT", "DB_SSL_ROOT_CERT", "WEATHER_API_BASE_URL", "AUTH_API_KEY_REQUIRED_ROUTES", "API_V1_DEPRECATED_AT"} {
+		require.True(t, cfgKeys[key], key)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	type spec struct {
+		Port    string `default:"8080"`
+		Timeout time.Duration
+		Key     string `required:"true"`
+		GRPC    struct {
+			Port string `default:"9090"`
+		}
+	}
+	lookup := func(values map[string]string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		}
+	}
+
+	t.Run("values, defaults and empty values", func(t *testing.T) {
+		var s spec
+		require.NoError(t, process(&s, lookup(map[string]string{"PORT": "", "KEY": "k", "TIMEOUT": "1m"})))
+		require.Equal(t, "", s.Port, "a set but empty value is not replaced by the default")
+		require.Equal(t, time.Minute, s.Timeout)
+		require.Equal(t, "k", s.Key)
+		require.Equal(t, "9090", s.GRPC.Port, "nested fields do not fall back to the top-level name")
+	})
+
+	t.Run("required", func(t *testing.T) {
+		var s spec
+		require.EqualError(t, process(&s, lookup(nil)), "required key KEY missing value")
+	})
+
+	t.Run("parse errors name the key", func(t *testing.T) {
+		var s spec
+		err := process(&s, lookup(map[string]string{"KEY": "k", "TIMEOUT": "soon"}))
+		require.ErrorContains(t, err, `TIMEOUT: parse "soon" as time.Duration`)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  any
+		err   string
+	}{
+		{name: "string", value: "a,b", want: "a,b"},
+		{name: "bool", value: "true", want: true},
+		{name: "bool invalid", value: "yes please", want: false, err: "invalid syntax"},
+		{name: "int", value: "-42", want: -42},
+		{name: "int hex", value: "0x10", want: 16},
+		{name: "int overflow", value: "300", want: int8(0), err: "out of range"},
+		{name: "uint", value: "7", want: uint(7)},
+		{name: "uint negative", value: "-1", want: uint(0), err: "invalid syntax"},
+		{name: "float", value: "0.25", want: 0.25},
+		{name: "duration", value: "1m30s", want: 90 * time.Second},
+		{name: "duration without unit", value: "90", want: time.Duration(0), err: "missing unit"},
+		{name: "text unmarshaler", value: "2026-10-19T00:00:00Z", want: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "text unmarshaler invalid", value: "tomorrow", want: time.Time{}, err: "cannot parse"},
+		{name: "decoder", value: "PROD", want: EnvTypeProd},
+		{name: "decoder invalid", value: "qa", want: EnvType("qa"), err: ErrUnknownEnvType.Error()},
+		{name: "slice", value: "/a,/b", want: []string{"/a", "/b"}},
+		{name: "empty slice", value: " ", want: []string{}},
+		{name: "slice item invalid", value: "1,x", want: []int(nil), err: "invalid syntax"},
+		{name: "unsupported", value: "a:1", want: map[string]int(nil), err: "unsupported type map[string]int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflect.New(reflect.TypeOf(tt.want)).Elem()
+			err := decode(tt.value, v)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, v.Interface())
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package configs
+
+import (
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// readFile reads a YAML or TOML config file into environment variable
+// form: nested keys are joined with "_" and upper-cased, so
+//
+//	db:
+//	  max_open_conns: 10
+//
+// sets DB_MAX_OPEN_CONNS, and lists become comma-separated values.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", doc, values); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	known := make(map[string]bool)
+	for _, f := range fields(&Config{}) {
+		known[f.Key] = true
+		if f.Secret {
+			known[f.Key+"_FILE"] = true
+		}
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+func flatten(prefix string, value any, out map[string]string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, nested := range v {
+			if prefix != "" {
+				key = prefix + "_" + key
+			}
+			if err := flatten(strings.ToUpper(key), nested, out); err != nil {
+				return err
+			}
+		}
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := scalar(prefix, item)
+			if err != nil {
+				return err
+			}
+			items = append(items, s)
+		}
+		out[prefix] = strings.Join(items, ",")
+	default:
+		s, err := scalar(prefix, v)
+		if err != nil {
+			return err
+		}
+		out[prefix] = s
+	}
+	return nil
+}
+
+func scalar(key string, value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case map[string]any, []any:
+		return "", fmt.Errorf("%s: nested value not allowed here", key)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,70 @@
+package configs
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// format renders v the way decode parses it back.
+func format(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(time.RFC3339)
+	case time.Duration:
+		return x.String()
+	}
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = format(v.Index(i))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Print writes the effective configuration as KEY=value lines. Non-empty
+// secrets are replaced unless showSecrets is set.
+func (c *Config) Print(w io.Writer, showSecrets bool) error {
+	for _, f := range fields(c) {
+		value := format(f.Value)
+		if !showSecrets && f.Secret && value != "" {
+			value = redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", f.Key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Secrets returns the configured credentials, which must never be logged.
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, f := range fields(c) {
+		if !f.Secret {
+			continue
+		}
+		if f.Value.Kind() != reflect.Slice {
+			secrets = append(secrets, f.Value.String())
+			continue
+		}
+		for i := 0; i < f.Value.Len(); i++ {
+			item := f.Value.Index(i).String()
+			secrets = append(secrets, item)
+			// Token keys are "id:secret" pairs.
+			if _, secret, ok := strings.Cut(item, ":"); ok {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	return secrets
+}
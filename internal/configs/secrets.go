@@ -0,0 +1,149 @@
+package configs
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const secretFileSuffix = "_FILE"
+
+// readSecretFiles reads the secrets whose *_FILE key is set in the
+// environment or in fileValues. It returns the secret values and the files
+// they came from, both by secret key.
+func readSecretFiles(fileValues map[string]string) (map[string]string, map[string]string, error) {
+	secrets := make(map[string]string)
+	files := make(map[string]string)
+	for _, f := range fields(&Config{}) {
+		if !f.Secret {
+			continue
+		}
+
+		path, ok := os.LookupEnv(f.Key + secretFileSuffix)
+		if !ok {
+			path, ok = fileValues[f.Key+secretFileSuffix]
+		}
+		if !ok || path == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(f.Key); set {
+			return nil, nil, fmt.Errorf("both %s and %s%s are set", f.Key, f.Key, secretFileSuffix)
+		}
+		if _, set := fileValues[f.Key]; set {
+			return nil, nil, fmt.Errorf("both %s and %s%s are set", f.Key, f.Key, secretFileSuffix)
+		}
+
+		value, err := readSecret(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s%s: %w", f.Key, secretFileSuffix, err)
+		}
+		secrets[f.Key] = value
+		files[f.Key] = path
+	}
+	return secrets, files, nil
+}
+
+// readSecret reads a secret file; surrounding whitespace, such as the
+// trailing newline most tools write, is not part of the secret.
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SecretWatcher polls secret files and passes rotated values to the
+// components that use them, so credentials can be rotated without a
+// restart. A file that cannot be read keeps its previous value.
+type SecretWatcher struct {
+	files    map[string]string
+	values   map[string]string
+	handlers map[string][]func(string)
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewSecretWatcher watches files, as returned by Config.SecretFiles.
+func NewSecretWatcher(files map[string]string, interval time.Duration, logger *zap.Logger) *SecretWatcher {
+	w := &SecretWatcher{
+		files:    files,
+		values:   make(map[string]string, len(files)),
+		handlers: make(map[string][]func(string)),
+		interval: interval,
+		logger:   logger,
+	}
+	for key, path := range files {
+		w.values[key], _ = readSecret(path)
+	}
+	return w
+}
+
+// OnChange registers fn to receive the new value of the secret key, e.g.
+// "WEATHER_API_KEY". It must be called before Run.
+func (w *SecretWatcher) OnChange(key string, fn func(value string)) {
+	w.handlers[key] = append(w.handlers[key], fn)
+}
+
+// Keys returns the watched secret keys.
+func (w *SecretWatcher) Keys() []string {
+	keys := make([]string, 0, len(w.files))
+	for key := range w.files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Prune stops watching secrets without a handler. Nothing would apply
+// their new values, so a warning that a restart is needed is logged
+// instead of reporting them as reloaded.
+func (w *SecretWatcher) Prune() {
+	for _, key := range w.Keys() {
+		if len(w.handlers[key]) > 0 {
+			continue
+		}
+		delete(w.files, key)
+		delete(w.values, key)
+		w.logger.Warn("secret file is not reloaded, changes need a restart", zap.String("config_key", key))
+	}
+}
+
+// Run checks the files every interval until ctx is done.
+func (w *SecretWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check reads every file once and notifies the handlers of changed ones.
+func (w *SecretWatcher) Check() {
+	for key, path := range w.files {
+		value, err := readSecret(path)
+		if err != nil {
+			w.logger.Error("failed to read secret file", zap.String("config_key", key), zap.Error(err))
+			continue
+		}
+		if value == w.values[key] || value == "" {
+			continue
+		}
+
+		w.values[key] = value
+		for _, fn := range w.handlers[key] {
+			fn(value)
+		}
+		w.logger.Info("secret reloaded", zap.String("config_key", key), zap.Int("handlers", len(w.handlers[key])))
+	}
+}
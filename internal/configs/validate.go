@@ -0,0 +1,154 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+var logLevels = map[string]bool{
+	"debug": true, "info": true, "warn": true, "error": true, "dpanic": true, "panic": true, "fatal": true,
+}
+
+// Validate checks the semantics struct tags cannot express: port numbers,
+// URLs, positive sizes and durations, and the values of enumerations.
+// All problems are reported at once.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.port("PORT", c.Port)
+	if c.GRPC.Port != "" {
+		v.port("GRPC_PORT", c.GRPC.Port)
+		v.check(c.GRPC.Port != c.Port, "GRPC_PORT", "must differ from PORT")
+	}
+	v.positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	v.check(c.SecretReloadInterval >= 0, "SECRET_RELOAD_INTERVAL", "must not be negative")
+	v.check(c.HTTP.ReadHeaderTimeout >= 0, "HTTP_READ_HEADER_TIMEOUT", "must not be negative")
+	v.check(c.HTTP.ReadTimeout >= 0, "HTTP_READ_TIMEOUT", "must not be negative")
+	v.check(c.HTTP.WriteTimeout >= 0, "HTTP_WRITE_TIMEOUT", "must not be negative")
+	v.check(c.HTTP.IdleTimeout >= 0, "HTTP_IDLE_TIMEOUT", "must not be negative")
+	v.check((c.HTTP.TLSCertFile == "") == (c.HTTP.TLSKeyFile == ""), "HTTP_TLS_KEY_FILE", "must be set together with HTTP_TLS_CERT_FILE")
+	v.file("HTTP_TLS_CERT_FILE", c.HTTP.TLSCertFile)
+	v.file("HTTP_TLS_KEY_FILE", c.HTTP.TLSKeyFile)
+
+	switch c.DB.Driver {
+	case DBDriverPostgres:
+		v.check(c.DB.Host != "", "DB_HOST", "required by the postgres driver")
+		v.port("DB_PORT", c.DB.Port)
+		v.check(c.DB.User != "", "DB_USER", "required by the postgres driver")
+		v.check(c.DB.Password != "", "DB_PASSWORD", "required by the postgres driver")
+		v.check(c.DB.Database != "", "DB_DATABASE", "required by the postgres driver")
+	case DBDriverSQLite:
+		v.check(c.DB.Path != "", "DB_PATH", "required by the sqlite driver")
+	case DBDriverMemory:
+	default:
+		v.check(false, "DB_DRIVER", "must be postgres, sqlite or memory")
+	}
+	v.check(sslModes[c.DB.SSLMode], "DB_SSL_MODE", "must be one of disable, allow, prefer, require, verify-ca, verify-full")
+	v.check((c.DB.SSLCert == "") == (c.DB.SSLKey == ""), "DB_SSL_KEY", "must be set together with DB_SSL_CERT")
+	v.check(c.DB.SSLMode != "disable" || c.DB.SSLRootCert == "" && c.DB.SSLCert == "", "DB_SSL_MODE", "must not be disable when certificates are set")
+	v.file("DB_SSL_ROOT_CERT", c.DB.SSLRootCert)
+	v.file("DB_SSL_CERT", c.DB.SSLCert)
+	v.file("DB_SSL_KEY", c.DB.SSLKey)
+	v.check(c.DB.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS", "must not be negative")
+	v.check(c.DB.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS", "must not be negative")
+	v.check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "DB_MAX_IDLE_CONNS", "must not exceed DB_MAX_OPEN_CONNS")
+	v.check(c.DB.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME", "must not be negative")
+
+	v.httpURL("WEATHER_API_BASE_URL", c.WeatherAPI.BaseURL)
+	v.httpURL("NOTIFICATION_BASE_URL", c.Notification.BaseURL)
+	v.httpURL("TELEGRAM_API_URL", c.Telegram.APIURL)
+	v.positive("TELEGRAM_POLL_TIMEOUT", c.Telegram.PollTimeout)
+
+	v.positive("WEBHOOK_TIMEOUT", c.Webhook.Timeout)
+	v.check(c.Webhook.MaxAttempts > 0, "WEBHOOK_MAX_ATTEMPTS", "must be positive")
+	v.positive("WEBHOOK_INITIAL_BACKOFF", c.Webhook.InitialBackoff)
+	v.check(c.Webhook.MaxBackoff >= c.Webhook.InitialBackoff, "WEBHOOK_MAX_BACKOFF", "must not be less than WEBHOOK_INITIAL_BACKOFF")
+
+	v.positive("STREAM_POLL_INTERVAL", c.Stream.PollInterval)
+	v.positive("STREAM_HEARTBEAT_INTERVAL", c.Stream.HeartbeatInterval)
+	v.check(c.Stream.MaxCities >= 0, "STREAM_MAX_CITIES", "must not be negative")
+	v.check(c.Feed.Days > 0, "FEED_DAYS", "must be positive")
+	v.check(c.Feed.MaxAge >= 0, "FEED_MAX_AGE", "must not be negative")
+
+	v.positive("SCHEDULER_INTERVAL", c.Scheduler.Interval)
+	v.check(c.Scheduler.BatchSize > 0, "SCHEDULER_BATCH_SIZE", "must be positive")
+	v.positive("SCHEDULER_LEASE", c.Scheduler.Lease)
+	v.check(c.Scheduler.DailyHour >= 0 && c.Scheduler.DailyHour < 24, "SCHEDULER_DAILY_HOUR", "must be between 0 and 23")
+	v.check(c.Scheduler.Concurrency > 0, "SCHEDULER_CONCURRENCY", "must be positive")
+
+	if c.Email.SMTPHost != "" {
+		v.port("EMAIL_SMTP_PORT", c.Email.SMTPPort)
+		v.positive("EMAIL_SMTP_TIMEOUT", c.Email.SMTPTimeout)
+	}
+
+	v.check(c.Tokens.Strategy == "random" || c.Tokens.Strategy == "signed", "TOKENS_STRATEGY", "must be random or signed")
+	v.check(c.Tokens.Strategy != "signed" || len(c.Tokens.Keys) > 0, "TOKENS_KEYS", "required by the signed strategy")
+	v.check(c.Tokens.ConfirmTTL >= 0, "TOKENS_CONFIRM_TTL", "must not be negative")
+	v.check(c.Tokens.UnsubscribeTTL >= 0, "TOKENS_UNSUBSCRIBE_TTL", "must not be negative")
+	v.check(c.Tokens.ManageTTL >= 0, "TOKENS_MANAGE_TTL", "must not be negative")
+
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+
+	v.check(logLevels[c.Log.Level], "LOG_LEVEL", "must be one of debug, info, warn, error, dpanic, panic, fatal")
+	v.check(c.Log.Format == "json" || c.Log.Format == "console", "LOG_FORMAT", "must be json or console")
+	v.check(c.Log.SamplingInitial >= 0, "LOG_SAMPLING_INITIAL", "must not be negative")
+	v.check(c.Log.SamplingThereafter > 0, "LOG_SAMPLING_THEREAFTER", "must be positive")
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key, problem string) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", key, problem))
+	}
+}
+
+func (v *validator) port(key, value string) {
+	port, err := strconv.Atoi(value)
+	v.check(err == nil && port > 0 && port < 65536, key, "must be a port number")
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+	v.check(d > 0, key, "must be positive")
+}
+
+// file checks that path, when set, is a readable regular file.
+func (v *validator) file(key, path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.Open(path)
+	if err == nil {
+		var info os.FileInfo
+		info, err = f.Stat()
+		if err == nil && info.IsDir() {
+			err = errors.New("is a directory")
+		}
+		f.Close()
+	}
+	if err != nil {
+		v.check(false, key, err.Error())
+	}
+}
+
+func (v *validator) httpURL(key, value string) {
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", key, "must be an absolute http(s) URL")
+}
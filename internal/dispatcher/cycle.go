@@ -0,0 +1,92 @@
+package dispatcher
+
+import (
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"sync"
+	"time"
+)
+
+// Cycle holds the weather fetched during one scheduler cycle, so that each
+// city is requested once however many batches it spans, and collects the
+// cycle's metrics. A Cycle must not be reused across cycles: the weather
+// would go stale.
+type Cycle struct {
+	started time.Time
+
+	mu      sync.Mutex
+	weather map[weatherKey]cachedWeather
+	stats   Stats
+}
+
+// Stats describes a dispatch cycle. Suppressed deliveries are also counted
+// as Failed.
+type Stats struct {
+	Subscriptions int
+	// Cities is the number of distinct city and language pairs, each of
+	// which costs one upstream request.
+	Cities        int
+	WeatherErrors int
+	Sent          int
+	Failed        int
+	Suppressed    int
+	Duration      time.Duration
+}
+
+type cachedWeather struct {
+	weather models.Weather
+	err     error
+}
+
+func NewCycle() *Cycle {
+	return &Cycle{
+		started: time.Now(),
+		weather: make(map[weatherKey]cachedWeather),
+	}
+}
+
+// Stats returns the metrics collected so far.
+func (c *Cycle) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Duration = time.Since(c.started)
+	return stats
+}
+
+func (c *Cycle) lookup(key weatherKey) (cachedWeather, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, ok := c.weather[key]
+	return w, ok
+}
+
+// store caches the outcome of a weather request, failures included: a city
+// that is not found now will not be found later in the cycle either.
+func (c *Cycle) store(key weatherKey, weather models.Weather, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.weather[key] = cachedWeather{weather: weather, err: err}
+	c.stats.Cities++
+	if err != nil {
+		c.stats.WeatherErrors++
+	}
+}
+
+func (c *Cycle) count(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Subscriptions++
+	if errors.Is(err, ErrSuppressed) {
+		c.stats.Suppressed++
+	}
+	if err != nil {
+		c.stats.Failed++
+	} else {
+		c.stats.Sent++
+	}
+}
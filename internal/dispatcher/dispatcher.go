@@ -0,0 +1,277 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/serjnester/weather-subscription-service/internal/tokens"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dispatcher sends the periodic weather update to each subscription.
+type Dispatcher interface {
+	// Dispatch returns one Result per subscription, in order. Weather
+	// fetched for earlier batches of the same cycle is reused.
+	Dispatch(ctx context.Context, cycle *Cycle, subs []models.Subscription) []Result
+}
+
+// Result is the outcome of a single delivery. MessageID is set by channels
+// whose provider assigns one.
+type Result struct {
+	Subscription models.Subscription
+	MessageID    string
+	Err          error
+}
+
+var _ Dispatcher = (*Impl)(nil)
+
+// ErrSuppressed is the result of a delivery to a suppressed email address.
+var ErrSuppressed = errors.New("email address is suppressed")
+
+// Impl groups subscriptions by city and fetches the weather once per city
+// and language for the whole cycle; units are converted locally. Fetching
+// and sending run on at most concurrency goroutines. Every completed
+// delivery, successful or not, is recorded in the delivery history.
+type Impl struct {
+	weather      weatherapi.WeatherClient
+	renderer     notification.Renderer
+	channels     notification.Channels
+	deliveries   storage.DeliveryStorage
+	suppressions storage.SuppressionStorage
+	tokens       tokens.Manager
+	logger       *zap.Logger
+	baseURL      string
+	concurrency  int
+	now          func() time.Time
+}
+
+type Params struct {
+	Weather    weatherapi.WeatherClient
+	Renderer   notification.Renderer
+	Channels   notification.Channels
+	Deliveries storage.DeliveryStorage
+	// Suppressions, when set, are checked before mailing.
+	Suppressions storage.SuppressionStorage
+	// Tokens issues unsubscribe links; random tokens are used when unset.
+	Tokens tokens.Manager
+	Logger *zap.Logger
+	// BaseURL is used for links in messages.
+	BaseURL string
+	// Concurrency is the maximum number of parallel weather requests and
+	// deliveries.
+	Concurrency int
+}
+
+func NewDispatcher(params Params) *Impl {
+	if params.Tokens == nil {
+		params.Tokens = tokens.NewRandomManager()
+	}
+	return &Impl{
+		weather:      params.Weather,
+		renderer:     params.Renderer,
+		channels:     params.Channels,
+		deliveries:   params.Deliveries,
+		suppressions: params.Suppressions,
+		tokens:       params.Tokens,
+		logger:       params.Logger,
+		baseURL:      strings.TrimRight(params.BaseURL, "/"),
+		concurrency:  max(params.Concurrency, 1),
+		now:          time.Now,
+	}
+}
+
+func (d *Impl) Dispatch(ctx context.Context, cycle *Cycle, subs []models.Subscription) []Result {
+	skip := d.suppressed(ctx, subs)
+
+	var missing []weatherKey
+	seen := make(map[weatherKey]bool)
+	for i, sub := range subs {
+		if skip[i] != nil {
+			continue
+		}
+		key := keyFor(sub)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, ok := cycle.lookup(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	d.forEach(ctx, len(missing), func(i int) {
+		key := missing[i]
+		weather, err := d.weather.GetForecast(ctx, key.city, models.Preferences{Units: enums.UnitsMetric, Lang: key.lang})
+		if err != nil && ctx.Err() != nil {
+			// Not worth caching: the cycle is over.
+			return
+		}
+		cycle.store(key, weather, err)
+	})
+
+	results := make([]Result, len(subs))
+	ran := make([]bool, len(subs))
+	d.forEach(ctx, len(subs), func(i int) {
+		if skip[i] != nil {
+			results[i] = Result{Subscription: subs[i], Err: skip[i]}
+			ran[i] = true
+			d.record(ctx, results[i])
+			return
+		}
+
+		id, err := d.send(ctx, cycle, subs[i])
+		results[i] = Result{Subscription: subs[i], MessageID: id, Err: err}
+		ran[i] = true
+		if err == nil || ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+			d.record(ctx, results[i])
+		}
+	})
+	for i := range results {
+		if !ran[i] {
+			results[i] = Result{Subscription: subs[i], Err: ctx.Err()}
+		}
+		cycle.count(results[i].Err)
+	}
+	return results
+}
+
+// suppressed returns, by index, the error that prevents mailing each
+// email subscription: ErrSuppressed, or the lookup error, since mailing a
+// suppressed address is worse than skipping an update.
+func (d *Impl) suppressed(ctx context.Context, subs []models.Subscription) []error {
+	skip := make([]error, len(subs))
+	if d.suppressions == nil {
+		return skip
+	}
+
+	var emails []string
+	for _, sub := range subs {
+		if sub.Channel == enums.ChannelEmail {
+			emails = append(emails, sub.Contact)
+		}
+	}
+	if len(emails) == 0 {
+		return skip
+	}
+
+	suppressed, err := d.suppressions.Suppressed(ctx, emails)
+	for i, sub := range subs {
+		if sub.Channel != enums.ChannelEmail {
+			continue
+		}
+		switch {
+		case err != nil:
+			skip[i] = fmt.Errorf("[Dispatcher.suppressed] %w", err)
+		case suppressed[strings.ToLower(sub.Contact)]:
+			skip[i] = ErrSuppressed
+		}
+	}
+	return skip
+}
+
+func (d *Impl) send(ctx context.Context, cycle *Cycle, sub models.Subscription) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	weather, ok := cycle.lookup(keyFor(sub))
+	if !ok {
+		return "", ctx.Err()
+	}
+	if weather.err != nil {
+		return "", fmt.Errorf("[Dispatcher.send] forecast: %w", weather.err)
+	}
+
+	prefs := sub.Preferences()
+	msg, err := d.renderer.Render(notification.UpdateKind(sub.Frequency), prefs.Lang, notification.Data{
+		Subscription:   sub,
+		Weather:        weather.weather.In(prefs.Units),
+		UnsubscribeURL: d.baseURL + "/api/v2/unsubscribe/" + d.tokens.Issue(sub, enums.TokenPurposeUnsubscribe),
+		SubscribeURL:   d.baseURL + "/",
+	})
+	if err != nil {
+		return "", fmt.Errorf("[Dispatcher.send] render: %w", err)
+	}
+
+	id, err := d.channels.Send(ctx, sub, msg)
+	if err != nil {
+		return "", fmt.Errorf("[Dispatcher.send] %w", err)
+	}
+	return id, nil
+}
+
+// record writes r to the delivery history. Interrupted deliveries are not
+// recorded: they are released and retried by another worker.
+func (d *Impl) record(ctx context.Context, r Result) {
+	delivery := models.Delivery{
+		SubscriptionID:    r.Subscription.ID,
+		Channel:           r.Subscription.Channel,
+		ScheduledFor:      r.Subscription.NextRunAt,
+		Status:            enums.DeliveryStatusSent,
+		ProviderMessageID: r.MessageID,
+	}
+	if r.Err != nil {
+		delivery.Status = enums.DeliveryStatusFailed
+		delivery.Error = r.Err.Error()
+	} else {
+		sentAt := d.now()
+		delivery.SentAt = &sentAt
+	}
+
+	// The message is already out; its record must survive a shutdown.
+	if err := d.deliveries.Record(context.WithoutCancel(ctx), delivery); err != nil {
+		d.logger.Error("failed to record delivery",
+			zap.Int64("subscription_id", r.Subscription.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// forEach calls fn for 0..n-1 on at most d.concurrency goroutines and
+// stops starting new calls once ctx is done.
+func (d *Impl) forEach(ctx context.Context, n int, fn func(i int)) {
+	sem := make(chan struct{}, d.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		select {
+		case <-ctx.Done():
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// weatherKey identifies an upstream request. Descriptions are localized by
+// weatherapi.com, so each language needs its own request.
+type weatherKey struct {
+	city string
+	lang enums.Language
+}
+
+func keyFor(sub models.Subscription) weatherKey {
+	return weatherKey{city: CanonicalCity(sub.City), lang: sub.Preferences().Lang}
+}
+
+// CanonicalCity normalizes case and whitespace so that "Kyiv", " kyiv"
+// and "KYIV" share a weather request.
+func CanonicalCity(city string) string {
+	return strings.ToLower(strings.Join(strings.Fields(city), " "))
+}
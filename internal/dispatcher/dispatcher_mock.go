@@ -0,0 +1,15 @@
+package dispatcher
+
+import (
+	"context"
+
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+type MockDispatcher struct {
+	DispatchFn func(ctx context.Context, cycle *Cycle, subs []models.Subscription) []Result
+}
+
+func (m *MockDispatcher) Dispatch(ctx context.Context, cycle *Cycle, subs []models.Subscription) []Result {
+	return m.DispatchFn(ctx, cycle, subs)
+}
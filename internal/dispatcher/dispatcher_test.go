@@ -0,0 +1,236 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	renderer, err := notification.NewTemplateRenderer("")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	fetches := map[string]int{}
+	weatherCli := &weatherapi.MockWeatherClient{
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			mu.Lock()
+			fetches[city+"/"+prefs.Lang.String()]++
+			mu.Unlock()
+
+			require.Equal(t, enums.UnitsMetric, prefs.Units)
+			if city == "atlantis" {
+				return models.Weather{}, weatherapi.ErrCityNotFound
+			}
+			return models.Weather{City: "Kyiv", Temperature: 20, Units: enums.UnitsMetric, Lang: prefs.Lang}, nil
+		},
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	sent := map[int64]notification.Message{}
+	channels := notification.NewChannels(&notification.MockChannel{
+		KindValue: enums.ChannelEmail,
+		SendFn: func(ctx context.Context, sub models.Subscription, msg notification.Message) (string, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			sent[sub.ID] = msg
+			mu.Unlock()
+			return fmt.Sprintf("msg-%d", sub.ID), nil
+		},
+	})
+
+	recorded := map[int64]models.Delivery{}
+	deliveries := &storage.MockDeliveryStorage{
+		RecordFn: func(ctx context.Context, d models.Delivery) error {
+			mu.Lock()
+			recorded[d.SubscriptionID] = d
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	d := NewDispatcher(Params{
+		Weather:     weatherCli,
+		Renderer:    renderer,
+		Channels:    channels,
+		Deliveries:  deliveries,
+		Logger:      zap.NewNop(),
+		BaseURL:     "https://weather.example.com/",
+		Concurrency: 3,
+	})
+
+	scheduledFor := time.Date(2025, 5, 20, 10, 0, 0, 0, time.UTC)
+	var batch1, batch2 []models.Subscription
+	for i := int64(1); i <= 10; i++ {
+		city := []string{"Kyiv", " kyiv", "KYIV  "}[i%3]
+		sub := models.Subscription{ID: i, Channel: enums.ChannelEmail, City: city, Frequency: enums.FrequencyHourly, Token: fmt.Sprint(i), NextRunAt: scheduledFor}
+		if i == 4 {
+			sub.Units = enums.UnitsImperial
+		}
+		if i == 5 {
+			sub.Lang = enums.Language("uk")
+		}
+		batch1 = append(batch1, sub)
+	}
+	batch2 = append(batch2,
+		models.Subscription{ID: 11, Channel: enums.ChannelEmail, City: "Kyiv", Frequency: enums.FrequencyDaily},
+		models.Subscription{ID: 12, Channel: enums.ChannelEmail, City: "Atlantis", Frequency: enums.FrequencyDaily},
+		models.Subscription{ID: 13, Channel: enums.ChannelEmail, City: "atlantis", Frequency: enums.FrequencyDaily},
+	)
+
+	cycle := NewCycle()
+	results := d.Dispatch(context.Background(), cycle, batch1)
+	require.Len(t, results, len(batch1))
+	for i, r := range results {
+		require.NoError(t, r.Err)
+		require.Equal(t, batch1[i].ID, r.Subscription.ID)
+		require.Equal(t, fmt.Sprintf("msg-%d", batch1[i].ID), r.MessageID)
+	}
+	require.LessOrEqual(t, maxInFlight.Load(), int32(3))
+	require.Contains(t, sent[4].Text, "68")
+	require.Contains(t, sent[1].Text, "https://weather.example.com/api/v2/unsubscribe/1")
+
+	results = d.Dispatch(context.Background(), cycle, batch2)
+	require.NoError(t, results[0].Err)
+	require.ErrorIs(t, results[1].Err, weatherapi.ErrCityNotFound)
+	require.ErrorIs(t, results[2].Err, weatherapi.ErrCityNotFound)
+
+	require.Equal(t, map[string]int{"kyiv/en": 1, "kyiv/uk": 1, "atlantis/en": 1}, fetches)
+
+	require.Len(t, recorded, 13)
+	require.Equal(t, enums.DeliveryStatusSent, recorded[1].Status)
+	require.Equal(t, "msg-1", recorded[1].ProviderMessageID)
+	require.Equal(t, scheduledFor, recorded[1].ScheduledFor)
+	require.NotNil(t, recorded[1].SentAt)
+	require.Equal(t, enums.DeliveryStatusFailed, recorded[12].Status)
+	require.Nil(t, recorded[12].SentAt)
+	require.Contains(t, recorded[12].Error, "city not found")
+
+	stats := cycle.Stats()
+	require.Equal(t, 13, stats.Subscriptions)
+	require.Equal(t, 3, stats.Cities)
+	require.Equal(t, 1, stats.WeatherErrors)
+	require.Equal(t, 11, stats.Sent)
+	require.Equal(t, 2, stats.Failed)
+}
+
+func TestDispatcher_DispatchCanceled(t *testing.T) {
+	renderer, err := notification.NewTemplateRenderer("")
+	require.NoError(t, err)
+
+	weatherCli := &weatherapi.MockWeatherClient{
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			return models.Weather{}, errors.New("must not be called")
+		},
+	}
+	d := NewDispatcher(Params{
+		Weather:  weatherCli,
+		Renderer: renderer,
+		Channels: notification.Channels{},
+		Deliveries: &storage.MockDeliveryStorage{
+			RecordFn: func(ctx context.Context, d models.Delivery) error {
+				t.Error("interrupted deliveries must not be recorded")
+				return nil
+			},
+		},
+		Logger:      zap.NewNop(),
+		Concurrency: 2,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	subs := []models.Subscription{{ID: 1, City: "Kyiv"}, {ID: 2, City: "Lviv"}}
+	results := d.Dispatch(ctx, NewCycle(), subs)
+	require.Len(t, results, 2)
+	for i, r := range results {
+		require.Equal(t, subs[i].ID, r.Subscription.ID)
+		require.ErrorIs(t, r.Err, context.Canceled)
+	}
+}
+
+func TestDispatcher_DispatchSuppressed(t *testing.T) {
+	renderer, err := notification.NewTemplateRenderer("")
+	require.NoError(t, err)
+
+	var fetched []string
+	weatherCli := &weatherapi.MockWeatherClient{
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			fetched = append(fetched, city)
+			return models.Weather{City: city, Units: enums.UnitsMetric}, nil
+		},
+	}
+	var sentTo []string
+	channels := notification.NewChannels(
+		&notification.MockChannel{
+			KindValue: enums.ChannelEmail,
+			SendFn: func(ctx context.Context, sub models.Subscription, msg notification.Message) (string, error) {
+				sentTo = append(sentTo, sub.Contact)
+				return "", nil
+			},
+		},
+		&notification.MockChannel{
+			KindValue: enums.ChannelTelegram,
+			SendFn: func(ctx context.Context, sub models.Subscription, msg notification.Message) (string, error) {
+				sentTo = append(sentTo, sub.Contact)
+				return "", nil
+			},
+		},
+	)
+	var recorded []models.Delivery
+	d := NewDispatcher(Params{
+		Weather:  weatherCli,
+		Renderer: renderer,
+		Channels: channels,
+		Deliveries: &storage.MockDeliveryStorage{
+			RecordFn: func(ctx context.Context, d models.Delivery) error {
+				recorded = append(recorded, d)
+				return nil
+			},
+		},
+		Suppressions: &storage.MockSuppressionStorage{
+			SuppressedFn: func(ctx context.Context, emails []string) (map[string]bool, error) {
+				require.Equal(t, []string{"Gone@example.com", "ok@example.com"}, emails)
+				return map[string]bool{"gone@example.com": true}, nil
+			},
+		},
+		Logger:      zap.NewNop(),
+		Concurrency: 1,
+	})
+
+	cycle := NewCycle()
+	results := d.Dispatch(context.Background(), cycle, []models.Subscription{
+		{ID: 1, Channel: enums.ChannelEmail, Contact: "Gone@example.com", City: "Odesa", Frequency: enums.FrequencyDaily},
+		{ID: 2, Channel: enums.ChannelEmail, Contact: "ok@example.com", City: "Kyiv", Frequency: enums.FrequencyDaily},
+		{ID: 3, Channel: enums.ChannelTelegram, Contact: "42", City: "Kyiv", Frequency: enums.FrequencyDaily},
+	})
+
+	require.ErrorIs(t, results[0].Err, ErrSuppressed)
+	require.NoError(t, results[1].Err)
+	require.NoError(t, results[2].Err)
+	require.Equal(t, []string{"kyiv"}, fetched)
+	require.Equal(t, []string{"ok@example.com", "42"}, sentTo)
+	require.Len(t, recorded, 3)
+	require.Equal(t, 1, cycle.Stats().Suppressed)
+}
@@ -0,0 +1,72 @@
+package feeds
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+const productID = "-//weather-subscription-service//Forecast Feed//EN"
+
+// Feed is the data rendered into both formats. Output depends only on the
+// Feed, so an unchanged forecast renders byte-identical feeds.
+type Feed struct {
+	Subscription models.Subscription
+	Forecast     models.Forecast
+	// UnsubscribeURL is linked from the feed description.
+	UnsubscribeURL string
+	// Link is the public page the feed refers to.
+	Link string
+}
+
+func (f Feed) title() string {
+	return "Weather forecast: " + f.Forecast.City
+}
+
+// updated is the feed modification time: the provider's last update, or
+// the first forecast day if unknown.
+func (f Feed) updated() time.Time {
+	if !f.Forecast.UpdatedAt.IsZero() {
+		return f.Forecast.UpdatedAt.UTC()
+	}
+	if len(f.Forecast.Days) > 0 {
+		return f.Forecast.Days[0].Date
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// LastModified is the value for the Last-Modified header.
+func (f Feed) LastModified() time.Time {
+	return f.updated()
+}
+
+// uid identifies a day of a subscription without exposing its token.
+func (f Feed) uid(day models.DailyForecast) string {
+	sum := sha256.Sum256([]byte(f.Subscription.Token))
+	return fmt.Sprintf("%s-%s@weather-subscription-service", day.Date.Format("20060102"), hex.EncodeToString(sum[:8]))
+}
+
+func summary(city string, day models.DailyForecast, f models.Forecast) string {
+	sym := f.Units.TemperatureSymbol()
+	return fmt.Sprintf("%s: %s, %.0f%s…%.0f%s", city, day.Description, day.MinTemp, sym, day.MaxTemp, sym)
+}
+
+func description(day models.DailyForecast, f models.Forecast) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s. Temperature %.1f%s to %.1f%s (average %.1f%s).",
+		day.Description,
+		day.MinTemp, f.Units.TemperatureSymbol(),
+		day.MaxTemp, f.Units.TemperatureSymbol(),
+		day.AvgTemp, f.Units.TemperatureSymbol(),
+	)
+	fmt.Fprintf(&b, " Chance of rain %d%%, humidity %d%%, wind up to %.1f %s.",
+		day.ChanceOfRain, day.Humidity, day.MaxWindSpeed, f.Units.SpeedSymbol())
+	if day.Sunrise != "" && day.Sunset != "" {
+		fmt.Fprintf(&b, " Sunrise %s, sunset %s.", day.Sunrise, day.Sunset)
+	}
+	return b.String()
+}
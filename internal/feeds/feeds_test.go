@@ -0,0 +1,82 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleFeed() Feed {
+	return Feed{
+		Subscription: models.Subscription{City: "Kyiv", Token: "secret-token"},
+		Forecast: models.Forecast{
+			City:      "Kyiv",
+			Country:   "Ukraine",
+			Units:     enums.UnitsMetric,
+			Lang:      "uk",
+			UpdatedAt: time.Date(2025, 5, 19, 6, 30, 0, 0, time.UTC),
+			Days: []models.DailyForecast{
+				{
+					Date: time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC), MinTemp: 10.4, MaxTemp: 20.1, AvgTemp: 15.3,
+					Description: "Місцями дощ; можлива гроза, вітер", Humidity: 55, MaxWindSpeed: 18, ChanceOfRain: 80,
+					Sunrise: "05:01 AM", Sunset: "08:45 PM",
+				},
+				{Date: time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC), MinTemp: 11, MaxTemp: 22, Description: "Sunny"},
+			},
+		},
+		UnsubscribeURL: "https://example.com/api/v2/unsubscribe/secret-token",
+		Link:           "https://example.com/",
+	}
+}
+
+// unfold reverses RFC 5545 line folding.
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n ", "")
+}
+
+func TestICS(t *testing.T) {
+	out := string(ICS(sampleFeed()))
+
+	require.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	require.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	require.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75, line)
+		require.False(t, strings.Contains(line, "\n"))
+	}
+
+	unfolded := unfold(out)
+	require.Contains(t, unfolded, "DTSTART;VALUE=DATE:20250519\r\nDTEND;VALUE=DATE:20250520\r\n")
+	require.Contains(t, unfolded, "DTSTAMP:20250519T063000Z\r\n")
+	require.Contains(t, unfolded, `SUMMARY:Kyiv: Місцями дощ\; можлива гроза\, вітер\, 10°C…20°C`)
+	require.Contains(t, unfolded, `Sunrise 05:01 AM\, sunset 08:45 PM.\n\nUnsubscribe: https://example.com/api/v2/unsubscribe/secret-token`)
+	require.Contains(t, unfolded, `LOCATION:Kyiv\, Ukraine`)
+	require.NotContains(t, unfolded, "UID:secret-token", "uids must not leak the token")
+
+	require.Equal(t, out, string(ICS(sampleFeed())), "output is deterministic")
+}
+
+func TestRSS(t *testing.T) {
+	out, err := RSS(sampleFeed())
+	require.NoError(t, err)
+
+	var doc rss
+	require.NoError(t, xml.Unmarshal(out, &doc))
+	require.Equal(t, "2.0", doc.Version)
+	require.Equal(t, "Weather forecast: Kyiv", doc.Channel.Title)
+	require.Equal(t, "uk", doc.Channel.Language)
+	require.Equal(t, "Mon, 19 May 2025 06:30:00 +0000", doc.Channel.LastBuildDate)
+	require.Len(t, doc.Channel.Items, 2)
+
+	item := doc.Channel.Items[0]
+	require.Equal(t, "Mon, 19 May — Kyiv: Місцями дощ; можлива гроза, вітер, 10°C…20°C", item.Title)
+	require.Contains(t, item.Description, "Chance of rain 80%")
+	require.False(t, item.GUID.IsPermaLink)
+	require.NotEqual(t, item.GUID.Value, doc.Channel.Items[1].GUID.Value)
+}
@@ -0,0 +1,93 @@
+package feeds
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	icsDateFormat     = "20060102"
+	icsDateTimeFormat = "20060102T150405Z"
+	icsLineLimit      = 75
+)
+
+// ICS renders the forecast as an RFC 5545 calendar with one all-day event
+// per forecast day.
+func ICS(f Feed) []byte {
+	var w icsWriter
+	stamp := f.updated().Format(icsDateTimeFormat)
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", productID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", f.title())
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT6H")
+	w.line("X-PUBLISHED-TTL", "PT6H")
+
+	for _, day := range f.Forecast.Days {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", f.uid(day))
+		w.line("DTSTAMP", stamp)
+		w.line("DTSTART;VALUE=DATE", day.Date.Format(icsDateFormat))
+		w.line("DTEND;VALUE=DATE", day.Date.AddDate(0, 0, 1).Format(icsDateFormat))
+		w.text("SUMMARY", summary(f.Forecast.City, day, f.Forecast))
+		desc := description(day, f.Forecast)
+		if f.UnsubscribeURL != "" {
+			desc += "\n\nUnsubscribe: " + f.UnsubscribeURL
+		}
+		w.text("DESCRIPTION", desc)
+		w.text("LOCATION", location(f))
+		w.line("TRANSP", "TRANSPARENT")
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func location(f Feed) string {
+	if f.Forecast.Country == "" {
+		return f.Forecast.City
+	}
+	return f.Forecast.City + ", " + f.Forecast.Country
+}
+
+type icsWriter struct {
+	buf bytes.Buffer
+}
+
+// text writes a TEXT property value with RFC 5545 escaping.
+func (w *icsWriter) text(name, value string) {
+	w.line(name, icsEscaper.Replace(value))
+}
+
+// line writes a content line folded at 75 octets without splitting UTF-8
+// sequences.
+func (w *icsWriter) line(name, value string) {
+	s := name + ":" + value
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = icsLineLimit - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+var icsEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
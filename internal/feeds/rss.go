@@ -0,0 +1,80 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// rssTTL tells readers how often to refresh, in minutes.
+const rssTTL = 60
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders the forecast as an RSS 2.0 feed with one item per day.
+func RSS(f Feed) ([]byte, error) {
+	updated := f.updated().Format(time.RFC1123Z)
+
+	desc := "Daily weather forecast for " + location(f) + "."
+	if f.UnsubscribeURL != "" {
+		desc += " Unsubscribe: " + f.UnsubscribeURL
+	}
+
+	doc := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.title(),
+			Link:          f.Link,
+			Description:   desc,
+			Language:      f.Forecast.Lang.String(),
+			LastBuildDate: updated,
+			TTL:           rssTTL,
+		},
+	}
+	for _, day := range f.Forecast.Days {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       day.Date.Format("Mon, 02 Jan") + " — " + summary(f.Forecast.City, day, f.Forecast),
+			Link:        f.Link,
+			Description: description(day, f.Forecast),
+			GUID:        rssGUID{Value: f.uid(day)},
+			PubDate:     updated,
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strconv"
+)
+
+type AdminHandler interface {
+	ListAPIKeys(c *gin.Context)
+	CreateAPIKey(c *gin.Context)
+	RevokeAPIKey(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	ListSuppressions(c *gin.Context)
+	RemoveSuppression(c *gin.Context)
+	GetLogLevel(c *gin.Context)
+	SetLogLevel(c *gin.Context)
+}
+
+// NewAdminHandler creates the admin API; logLevel is the level of the
+// application logger, changed through /admin/log-level.
+func NewAdminHandler(apiKeys service.APIKeyService, deliveries service.DeliveryService, suppressions service.SuppressionService, logLevel zap.AtomicLevel) AdminHandler {
+	return &adminHandler{
+		APIKeys:      apiKeys,
+		Deliveries:   deliveries,
+		Suppressions: suppressions,
+		LogLevel:     logLevel,
+	}
+}
+
+type adminHandler struct {
+	APIKeys      service.APIKeyService
+	Deliveries   service.DeliveryService
+	Suppressions service.SuppressionService
+	LogLevel     zap.AtomicLevel
+}
+
+// ListAPIKeys godoc
+//
+//	@Summary		List API keys
+//	@Description	Lists partner API keys with today's usage. Key values are never returned.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{array}		models.APIKey
+//	@Failure		401	{object}	ErrorResponse	"Invalid admin token"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/admin/api-keys [get]
+func (h *adminHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.APIKeys.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+type createAPIKeyRequest struct {
+	Name       string `json:"name" binding:"required"`
+	DailyQuota int    `json:"daily_quota" binding:"min=0"`
+}
+
+type CreateAPIKeyResponse struct {
+	models.APIKey
+	// Key is the plaintext API key. It is shown only once.
+	Key string `json:"key"`
+}
+
+// CreateAPIKey godoc
+//
+//	@Summary		Create API key
+//	@Description	Creates a partner API key. The plaintext key is returned only in this response.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		createAPIKeyRequest	true	"Key name and daily quota (0 is unlimited)"
+//	@Success		201		{object}	CreateAPIKeyResponse
+//	@Failure		400		{object}	ErrorResponse	"Invalid input"
+//	@Failure		401		{object}	ErrorResponse	"Invalid admin token"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/admin/api-keys [post]
+func (h *adminHandler) CreateAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid input")
+		return
+	}
+
+	key, plain, err := h.APIKeys.CreateAPIKey(c.Request.Context(), req.Name, req.DailyQuota)
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyInvalid) {
+			abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Name is required and quota must not be negative")
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: plain})
+}
+
+// RevokeAPIKey godoc
+//
+//	@Summary		Revoke API key
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			id	path		int	true	"API key ID"
+//	@Success		200	{object}	MessageResponse
+//	@Failure		400	{object}	ErrorResponse	"Invalid ID"
+//	@Failure		401	{object}	ErrorResponse	"Invalid admin token"
+//	@Failure		404	{object}	ErrorResponse	"API key not found"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/admin/api-keys/{id} [delete]
+func (h *adminHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid ID")
+		return
+	}
+
+	if err := h.APIKeys.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			abortWithError(c, http.StatusNotFound, ErrCodeNotFound, "API key not found")
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "API key revoked"})
+}
+
+type listDeliveriesRequest struct {
+	SubscriptionID int64                `form:"subscription_id" binding:"min=0"`
+	Status         enums.DeliveryStatus `form:"status"`
+	BeforeID       int64                `form:"before_id" binding:"min=0"`
+	Limit          int                  `form:"limit" binding:"min=0"`
+}
+
+// ListDeliveries godoc
+//
+//	@Summary		List deliveries
+//	@Description	Lists scheduled deliveries, newest first. Pass the last returned id as before_id to get the next page.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			subscription_id	query		int		false	"Subscription ID"
+//	@Param			status			query		string	false	"Delivery status"	Enums(sent, failed)
+//	@Param			before_id		query		int		false	"Only deliveries with a smaller ID"
+//	@Param			limit			query		int		false	"Page size, at most 500"	default(50)
+//	@Success		200				{array}		models.Delivery
+//	@Failure		400				{object}	ErrorResponse	"Invalid filter"
+//	@Failure		401				{object}	ErrorResponse	"Invalid admin token"
+//	@Failure		500				{object}	ErrorResponse	"Internal server error"
+//	@Router			/admin/deliveries [get]
+func (h *adminHandler) ListDeliveries(c *gin.Context) {
+	var req listDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid filter")
+		return
+	}
+
+	deliveries, err := h.Deliveries.ListDeliveries(c.Request.Context(), models.DeliveryFilter{
+		SubscriptionID: req.SubscriptionID,
+		Status:         req.Status,
+		BeforeID:       req.BeforeID,
+		Limit:          req.Limit,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDeliveryFilter) {
+			abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid filter")
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+type listSuppressionsRequest struct {
+	Limit int `form:"limit,default=100" binding:"min=1,max=1000"`
+}
+
+// ListSuppressions godoc
+//
+//	@Summary		List suppressed email addresses
+//	@Description	Lists addresses that no longer receive email because of a hard bounce or a complaint, newest first.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			limit	query		int	false	"Page size, at most 1000"	default(100)
+//	@Success		200		{array}		models.Suppression
+//	@Failure		400		{object}	ErrorResponse	"Invalid limit"
+//	@Failure		401		{object}	ErrorResponse	"Invalid admin token"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/admin/suppressions [get]
+func (h *adminHandler) ListSuppressions(c *gin.Context) {
+	var req listSuppressionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid limit")
+		return
+	}
+
+	suppressions, err := h.Suppressions.ListSuppressions(c.Request.Context(), req.Limit)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, suppressions)
+}
+
+// RemoveSuppression godoc
+//
+//	@Summary		Remove an address from the suppression list
+//	@Description	Resumes email to the address for its existing subscriptions.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			email	path		string	true	"Email address"
+//	@Success		200		{object}	MessageResponse
+//	@Failure		401		{object}	ErrorResponse	"Invalid admin token"
+//	@Failure		404		{object}	ErrorResponse	"Address is not suppressed"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/admin/suppressions/{email} [delete]
+func (h *adminHandler) RemoveSuppression(c *gin.Context) {
+	if err := h.Suppressions.RemoveSuppression(c.Request.Context(), c.Param("email")); err != nil {
+		if errors.Is(err, service.ErrSuppressionNotFound) {
+			abortWithError(c, http.StatusNotFound, ErrCodeNotFound, "Address is not suppressed")
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Suppression removed"})
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level" example:"info"`
+}
+
+type setLogLevelRequest struct {
+	Level string `json:"level" binding:"required" example:"debug"`
+}
+
+// GetLogLevel godoc
+//
+//	@Summary		Get the log level
+//	@Tags			admin
+//	@Produce		json
+//	@Security		AdminToken
+//	@Success		200	{object}	LogLevelResponse
+//	@Failure		401	{object}	ErrorResponse	"Invalid admin token"
+//	@Router			/admin/log-level [get]
+func (h *adminHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, LogLevelResponse{Level: h.LogLevel.String()})
+}
+
+// SetLogLevel godoc
+//
+//	@Summary		Change the log level
+//	@Description	Takes effect immediately and lasts until the next restart.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		AdminToken
+//	@Param			request	body		setLogLevelRequest	true	"debug, info, warn, error, dpanic, panic or fatal"
+//	@Success		200		{object}	LogLevelResponse
+//	@Failure		400		{object}	ErrorResponse	"Invalid level"
+//	@Failure		401		{object}	ErrorResponse	"Invalid admin token"
+//	@Router			/admin/log-level [put]
+func (h *adminHandler) SetLogLevel(c *gin.Context) {
+	var req setLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid input")
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid level")
+		return
+	}
+	h.LogLevel.SetLevel(level)
+
+	c.JSON(http.StatusOK, LogLevelResponse{Level: level.String()})
+}
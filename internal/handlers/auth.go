@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	apiKeyContextKey = "api_key"
+)
+
+const (
+	ErrCodeUnauthorized   = "unauthorized"
+	ErrCodeQuotaExceeded  = "quota_exceeded"
+	ErrCodeAPIKeyRequired = "api_key_required"
+)
+
+type AuthRequirement int
+
+const (
+	AuthNone AuthRequirement = iota
+	// AuthOptional validates and meters a key when one is sent.
+	AuthOptional
+	AuthRequired
+)
+
+// AuthPolicy maps gin route patterns to their API key requirement.
+type AuthPolicy map[string]AuthRequirement
+
+func NewAuthPolicy(cfg configs.Auth) AuthPolicy {
+	policy := make(AuthPolicy, len(cfg.APIKeyRequiredRoutes)+len(cfg.APIKeyOptionalRoutes))
+	for _, route := range cfg.APIKeyOptionalRoutes {
+		policy[strings.TrimSpace(route)] = AuthOptional
+	}
+	for _, route := range cfg.APIKeyRequiredRoutes {
+		policy[strings.TrimSpace(route)] = AuthRequired
+	}
+	return policy
+}
+
+// apiKeyAuth is installed globally; gin resolves the matched route before
+// running middlewares, so c.FullPath() is already the registered pattern.
+func apiKeyAuth(keys service.APIKeyService, policy AuthPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requirement := policy[c.FullPath()]
+		if requirement == AuthNone {
+			c.Next()
+			return
+		}
+
+		plain := c.GetHeader(APIKeyHeader)
+		if plain == "" {
+			if requirement == AuthRequired {
+				abortWithError(c, http.StatusUnauthorized, ErrCodeAPIKeyRequired, "API key required")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		key, err := keys.AuthenticateAPIKey(c.Request.Context(), plain)
+		if key.DailyQuota > 0 {
+			remaining := int64(key.DailyQuota) - key.RequestsToday
+			if remaining < 0 {
+				remaining = 0
+			}
+			c.Header("X-RateLimit-Limit", strconv.Itoa(key.DailyQuota))
+			c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrAPIKeyQuotaExceeded):
+				abortWithError(c, http.StatusTooManyRequests, ErrCodeQuotaExceeded, "API key daily quota exceeded")
+			case errors.Is(err, service.ErrAPIKeyInvalid), errors.Is(err, service.ErrAPIKeyRevoked):
+				abortWithError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid API key")
+			default:
+				abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+			}
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// APIKeyFromContext returns the key that authenticated the request, if any.
+func APIKeyFromContext(c *gin.Context) (models.APIKey, bool) {
+	v, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return models.APIKey{}, false
+	}
+	key, ok := v.(models.APIKey)
+	return key, ok
+}
+
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			abortWithError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid admin token")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/internal/bounces"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"net/http"
+	"strings"
+)
+
+const (
+	ErrCodeEmailSuppressed = "email_suppressed"
+	ErrCodeUnsupported     = "unsupported_format"
+)
+
+// maxEmailEventBody bounds notifications; a DSN carries at most the
+// headers of the returned message.
+const maxEmailEventBody = 1 << 20
+
+type EmailEventsHandler interface {
+	HandleEmailEvents(c *gin.Context)
+}
+
+func NewEmailEventsHandler(suppressions service.SuppressionService) EmailEventsHandler {
+	return &emailEventsHandler{
+		Suppressions: suppressions,
+	}
+}
+
+type emailEventsHandler struct {
+	Suppressions service.SuppressionService
+}
+
+type EmailEventsResponse struct {
+	Received   int `json:"received"`
+	Suppressed int `json:"suppressed"`
+}
+
+// HandleEmailEvents godoc
+//
+//	@Summary		Receive bounce and complaint notifications
+//	@Description	Accepts notifications from the mail provider or a mailbox forwarder. The body is either JSON,
+//	@Description	a single {"type": "bounce|complaint", "email", "bounce_type": "hard|soft", "status", "detail"} object or an array of them,
+//	@Description	or a delivery status notification (RFC 3464) as message/delivery-status, multipart/report or message/rfc822.
+//	@Description	Hard bounces and complaints suppress the address; soft bounces are ignored.
+//	@Description	Authenticated with Authorization: Bearer $EMAIL_EVENTS_TOKEN or ?token=, for providers that only accept a URL.
+//	@Tags			email
+//	@Accept			json,message/delivery-status,multipart/report,message/rfc822
+//	@Produce		json
+//	@Param			token	query		string	false	"Events token, if not sent in the Authorization header"
+//	@Success		200		{object}	EmailEventsResponse
+//	@Failure		400		{object}	ErrorResponse	"Unsupported or malformed notification"
+//	@Failure		401		{object}	ErrorResponse	"Invalid events token"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/email/events [post]
+func (h *emailEventsHandler) HandleEmailEvents(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailEventBody)
+
+	events, err := bounces.Parse(c.GetHeader("Content-Type"), body)
+	if err != nil {
+		if errors.Is(err, bounces.ErrUnsupportedFormat) {
+			abortWithError(c, http.StatusBadRequest, ErrCodeUnsupported, err.Error())
+			return
+		}
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid body")
+		return
+	}
+
+	suppressed, err := h.Suppressions.HandleEmailEvents(c.Request.Context(), events)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, EmailEventsResponse{Received: len(events), Suppressed: suppressed})
+}
+
+// eventsAuth accepts the token as a bearer token or as the token query
+// parameter.
+func eventsAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			got = c.Query("token")
+		}
+		if got == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			abortWithError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid events token")
+			return
+		}
+		c.Next()
+	}
+}
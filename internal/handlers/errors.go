@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeInvalidInput      = "invalid_input"
+	ErrCodeInvalidToken      = "invalid_token"
+	ErrCodeNotFound          = "not_found"
+	ErrCodeCityNotFound      = "city_not_found"
+	ErrCodeTokenNotFound     = "token_not_found"
+	ErrCodeAlreadySubscribed = "already_subscribed"
+	ErrCodeInternal          = "internal_error"
+)
+
+// ErrorResponse is the JSON error envelope returned by the v2 API.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// MessageResponse is the JSON envelope for v2 responses that carry no data.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+func abortWithError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Error: ErrorBody{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/feeds"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FeedHandler interface {
+	GetFeed(c *gin.Context)
+}
+
+// NewFeedHandler serves forecasts for days days. Responses may be cached
+// for maxAge; baseURL is used for links inside the feeds.
+func NewFeedHandler(svc service.Service, days int, maxAge time.Duration, baseURL string) FeedHandler {
+	return &feedHandler{
+		Service: svc,
+		Days:    days,
+		MaxAge:  maxAge,
+		BaseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type feedHandler struct {
+	Service service.Service
+	Days    int
+	MaxAge  time.Duration
+	BaseURL string
+}
+
+const (
+	contentTypeICS = "text/calendar; charset=utf-8"
+	contentTypeRSS = "application/rss+xml; charset=utf-8"
+)
+
+// GetFeed godoc
+//
+//	@Summary		Forecast feed for a subscription
+//	@Description	Daily forecasts for the subscription's city as an iCalendar (all-day events) or RSS 2.0 feed.
+//	@Description	A manage token authorizes access; confirm and unsubscribe tokens from emails are refused.
+//	@Description	Supports If-None-Match and If-Modified-Since.
+//	@Tags			feeds
+//	@Produce		text/calendar,application/rss+xml
+//	@Param			file	path	string	true	"Manage token with .ics or .rss extension"
+//	@Success		200
+//	@Success		304
+//	@Failure		404	{object}	ErrorResponse	"Token not found"
+//	@Failure		500	{object}	ErrorResponse	"Internal server error"
+//	@Router			/feeds/{file} [get]
+func (h *feedHandler) GetFeed(c *gin.Context) {
+	file := c.Param("file")
+	ext := path.Ext(file)
+	token := strings.TrimSuffix(file, ext)
+	if token == "" || (ext != ".ics" && ext != ".rss") {
+		abortWithError(c, http.StatusNotFound, ErrCodeNotFound, "Unknown feed")
+		return
+	}
+
+	sub, forecast, err := h.Service.SubscriptionForecast(c.Request.Context(), token, h.Days)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTokenNotFound):
+			abortWithError(c, http.StatusNotFound, ErrCodeTokenNotFound, "Token not found")
+		case errors.Is(err, weatherapi.ErrCityNotFound):
+			abortWithError(c, http.StatusNotFound, ErrCodeCityNotFound, "City not found")
+		default:
+			abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	feed := feeds.Feed{
+		Subscription:   sub,
+		Forecast:       forecast,
+		UnsubscribeURL: h.BaseURL + "/api/v2/unsubscribe/" + h.Service.IssueToken(sub, enums.TokenPurposeUnsubscribe),
+		Link:           h.BaseURL + "/",
+	}
+
+	var body []byte
+	contentType := contentTypeICS
+	if ext == ".ics" {
+		body = feeds.ICS(feed)
+	} else {
+		contentType = contentTypeRSS
+		if body, err = feeds.RSS(feed); err != nil {
+			abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+			return
+		}
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := feed.LastModified()
+
+	// The URL carries a secret token, so shared caches must not store it.
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(h.MaxAge.Seconds())))
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	c.Header("Vary", "Accept-Encoding")
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if ext == ".ics" {
+		// The city comes from the weather provider; FormatMediaType quotes
+		// or encodes it so it cannot break out of the header.
+		filename := "weather-" + strings.ToLower(strings.ReplaceAll(forecast.City, " ", "-")) + ".ics"
+		if disposition := mime.FormatMediaType("inline", map[string]string{"filename": filename}); disposition != "" {
+			c.Header("Content-Disposition", disposition)
+		}
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified evaluates conditional request headers; If-None-Match takes
+// precedence over If-Modified-Since (RFC 9110, section 13.2.2).
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -14,7 +14,9 @@ type Handler interface {
 	GetWeather(c *gin.Context)
 	Subscribe(c *gin.Context)
 	Unsubscribe(c *gin.Context)
+	UnsubscribePage(c *gin.Context)
 	ConfirmSubscription(c *gin.Context)
+	ConfirmPage(c *gin.Context)
 }
 
 type CreditLimitsParams struct {
@@ -34,13 +36,18 @@ type handler struct {
 //
 //	@Summary		Get current weather for a city
 //	@Description	Returns the current weather forecast for the specified city using WeatherAPI.com.
+//	@Description	No API key is needed by default; a deployment that lists this route in AUTH_API_KEY_REQUIRED_ROUTES
+//	@Description	requires one in X-API-Key and answers 401 or 429 like /api/v2/weather.
 //	@Tags			weather
 //	@Accept			json
 //	@Produce		json
 //	@Param			city	query		string	true	"City name for weather forecast"
-//	@Success		200		{object}	models.Weather
+//	@Param			units	query		string	false	"Unit system"	Enums(metric, imperial)	default(metric)
+//	@Param			lang	query		string	false	"Language of the description, e.g. uk"	default(en)
+//	@Success		200		{object}	WeatherV1
 //	@Failure		400		{string}	string	"Invalid request"
 //	@Failure		404		{string}	string	"City not found"
+//	@Deprecated
 //	@Router			/api/weather [get]
 func (h *handler) GetWeather(c *gin.Context) {
 	city := c.Query("city")
@@ -49,7 +56,13 @@ func (h *handler) GetWeather(c *gin.Context) {
 		return
 	}
 
-	weather, err := h.Service.WeatherForecast(c.Request.Context(), city)
+	prefs, ok := parsePreferences(c.Query("units"), c.Query("lang"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, "Invalid 'units' or 'lang' query parameter")
+		return
+	}
+
+	weather, err := h.Service.WeatherForecast(c.Request.Context(), city, prefs)
 	if err != nil {
 		if errors.Is(err, weatherapi.ErrCityNotFound) {
 			c.JSON(http.StatusNotFound, "City not found")
@@ -59,7 +72,18 @@ func (h *handler) GetWeather(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, weather)
+	c.JSON(http.StatusOK, WeatherV1{
+		Temperature: weather.Temperature,
+		Description: weather.Description,
+		Humidity:    weather.Humidity,
+	})
+}
+
+// WeatherV1 is the frozen v1 weather contract.
+type WeatherV1 struct {
+	Temperature float64 `json:"temperature"`
+	Description string  `json:"description"`
+	Humidity    int     `json:"humidity"`
 }
 
 type subscribeForm struct {
@@ -81,6 +105,7 @@ type subscribeForm struct {
 //	@Success		200			{string}	string	"Subscription successful. Confirmation email sent."
 //	@Failure		400			{string}	string	"Invalid input"
 //	@Failure		409			{string}	string	"Email already subscribed"
+//	@Deprecated
 //	@Router			/api/subscribe [post]
 func (h *handler) Subscribe(c *gin.Context) {
 	var form subscribeForm
@@ -89,8 +114,9 @@ func (h *handler) Subscribe(c *gin.Context) {
 		return
 	}
 
-	err := h.Service.Subscribe(c.Request.Context(), models.Subscription{
-		Email:     form.Email,
+	_, err := h.Service.Subscribe(c.Request.Context(), models.Subscription{
+		Channel:   enums.ChannelEmail,
+		Contact:   form.Email,
 		City:      form.City,
 		Frequency: form.Frequency,
 	})
@@ -99,6 +125,10 @@ func (h *handler) Subscribe(c *gin.Context) {
 			c.JSON(http.StatusConflict, "Email already subscribed")
 			return
 		}
+		if errors.Is(err, service.ErrEmailSuppressed) {
+			c.JSON(http.StatusBadRequest, "Invalid input")
+			return
+		}
 
 		c.JSON(http.StatusInternalServerError, "Internal server error")
 		return
@@ -107,18 +137,33 @@ func (h *handler) Subscribe(c *gin.Context) {
 	c.JSON(http.StatusOK, "Subscription successful. Confirmation email sent.")
 }
 
+// ConfirmPage godoc
+//
+//	@Summary		Confirmation page
+//	@Description	Shows the subscription and a button confirming it. Opening the link has no effect, so that mail scanners cannot confirm on the user's behalf.
+//	@Tags			subscription
+//	@Produce		html
+//	@Param			token	path		string	true	"Confirmation token"
+//	@Success		200		{string}	string	"Confirmation page"
+//	@Failure		404		{string}	string	"Token not found"
+//	@Deprecated
+//	@Router			/api/confirm/{token} [get]
+func (h *handler) ConfirmPage(c *gin.Context) {
+	showTokenPage(c, h.Service, confirmAction)
+}
+
 // ConfirmSubscription godoc
 //
 //	@Summary		Confirm email subscription
-//	@Description	Confirms a subscription using the token sent in the confirmation email.
+//	@Description	Confirms a subscription using the token sent in the confirmation email. Browsers submitting the confirmation page get an HTML page.
 //	@Tags			subscription
-//	@Accept			json
-//	@Produce		json
+//	@Produce		json,html
 //	@Param			token	path		string	true	"Confirmation token"
 //	@Success		200		{string}	string	"Subscription confirmed successfully"
 //	@Failure		400		{string}	string	"Invalid token"
 //	@Failure		404		{string}	string	"Token not found"
-//	@Router			/api/confirm/{token} [get]
+//	@Deprecated
+//	@Router			/api/confirm/{token} [post]
 func (h *handler) ConfirmSubscription(c *gin.Context) {
 	token := c.Param("token")
 	if token == "" {
@@ -127,6 +172,10 @@ func (h *handler) ConfirmSubscription(c *gin.Context) {
 	}
 
 	err := h.Service.Confirm(c.Request.Context(), token)
+	if wantsPage(c) {
+		renderTokenResult(c, confirmAction, err)
+		return
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrTokenNotFound):
@@ -140,18 +189,35 @@ func (h *handler) ConfirmSubscription(c *gin.Context) {
 	c.JSON(http.StatusOK, "Subscription confirmed successfully")
 }
 
-// Unsubscribe godoc
+// UnsubscribePage godoc
 //
-//	@Summary		Unsubscribe from weather updates
-//	@Description	Unsubscribes an email from weather updates using the token sent in emails.
+//	@Summary		Unsubscribe page
+//	@Description	Shows the subscription and a button cancelling it. Opening the link has no effect, so that mail scanners cannot unsubscribe on the user's behalf.
 //	@Tags			subscription
-//	@Accept			json
-//	@Produce		json
+//	@Produce		html
 //	@Param			token	path		string	true	"Unsubscribe token"
-//	@Success		200		{string}	string	"Unsubscribed successfully"
-//	@Failure		400		{string}	string	"Invalid token"
+//	@Success		200		{string}	string	"Unsubscribe page"
 //	@Failure		404		{string}	string	"Token not found"
+//	@Deprecated
 //	@Router			/api/unsubscribe/{token} [get]
+func (h *handler) UnsubscribePage(c *gin.Context) {
+	showTokenPage(c, h.Service, unsubscribeAction)
+}
+
+// Unsubscribe godoc
+//
+//	@Summary		Unsubscribe from weather updates
+//	@Description	Unsubscribes from weather updates using the token sent in emails. Also serves one-click unsubscribe (RFC 8058) from mail clients. Browsers submitting the unsubscribe page get an HTML page.
+//	@Tags			subscription
+//	@Accept			application/x-www-form-urlencoded
+//	@Produce		json,html
+//	@Param			token				path		string	true	"Unsubscribe token"
+//	@Param			List-Unsubscribe	formData	string	false	"One-click unsubscribe marker"	Enums(One-Click)
+//	@Success		200					{string}	string	"Unsubscribed successfully"
+//	@Failure		400					{string}	string	"Invalid token"
+//	@Failure		404					{string}	string	"Token not found"
+//	@Deprecated
+//	@Router			/api/unsubscribe/{token} [post]
 func (h *handler) Unsubscribe(c *gin.Context) {
 	token := c.Param("token")
 	if token == "" {
@@ -160,6 +226,10 @@ func (h *handler) Unsubscribe(c *gin.Context) {
 	}
 
 	err := h.Service.Unsubscribe(c.Request.Context(), token)
+	if wantsPage(c) {
+		renderTokenResult(c, unsubscribeAction, err)
+		return
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrTokenNotFound):
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"net/http"
+	"time"
+)
+
+type HandlerV2 interface {
+	GetWeather(c *gin.Context)
+	Subscribe(c *gin.Context)
+	Unsubscribe(c *gin.Context)
+	UnsubscribePage(c *gin.Context)
+	ConfirmSubscription(c *gin.Context)
+	ConfirmPage(c *gin.Context)
+	GetSubscription(c *gin.Context)
+	SubscribeWebhook(c *gin.Context)
+}
+
+func NewHandlerV2(service service.Service) HandlerV2 {
+	return &handlerV2{
+		Service: service,
+	}
+}
+
+type handlerV2 struct {
+	Service service.Service
+}
+
+type WeatherV2 struct {
+	City        string    `json:"city"`
+	Country     string    `json:"country"`
+	Temperature float64   `json:"temperature"`
+	FeelsLike   float64   `json:"feels_like"`
+	Description string    `json:"description"`
+	Humidity    int       `json:"humidity"`
+	WindSpeed   float64   `json:"wind_speed"`
+	Units       string    `json:"units" enums:"metric,imperial"`
+	Lang        string    `json:"lang"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newWeatherV2(w models.Weather) WeatherV2 {
+	return WeatherV2{
+		City:        w.City,
+		Country:     w.Country,
+		Temperature: w.Temperature,
+		FeelsLike:   w.FeelsLike,
+		Description: w.Description,
+		Humidity:    w.Humidity,
+		WindSpeed:   w.WindSpeed,
+		Units:       w.Units.String(),
+		Lang:        w.Lang.String(),
+		UpdatedAt:   w.UpdatedAt,
+	}
+}
+
+// GetWeather godoc
+//
+//	@Summary		Get current weather for a city
+//	@Description	Returns the current weather for the specified city, including location, feels-like temperature and wind.
+//	@Description	Temperatures are in °C or °F and wind speed in km/h or mph depending on units.
+//	@Tags			weather
+//	@Accept			json
+//	@Produce		json
+//	@Param			city	query		string	true	"City name for weather forecast"
+//	@Param			units	query		string	false	"Unit system"	Enums(metric, imperial)	default(metric)
+//	@Param			lang	query		string	false	"Language of the description, e.g. uk"	default(en)
+//	@Security		APIKey
+//	@Success		200		{object}	WeatherV2
+//	@Failure		400		{object}	ErrorResponse	"Invalid request"
+//	@Failure		401		{object}	ErrorResponse	"API key required or invalid"
+//	@Failure		404		{object}	ErrorResponse	"City not found"
+//	@Failure		429		{object}	ErrorResponse	"API key daily quota exceeded"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/weather [get]
+func (h *handlerV2) GetWeather(c *gin.Context) {
+	city := c.Query("city")
+	if city == "" {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Missing 'city' query parameter")
+		return
+	}
+
+	prefs, ok := parsePreferences(c.Query("units"), c.Query("lang"))
+	if !ok {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid 'units' or 'lang' query parameter")
+		return
+	}
+
+	weather, err := h.Service.WeatherForecast(c.Request.Context(), city, prefs)
+	if err != nil {
+		if errors.Is(err, weatherapi.ErrCityNotFound) {
+			abortWithError(c, http.StatusNotFound, ErrCodeCityNotFound, "City not found")
+		} else {
+			abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to fetch weather")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newWeatherV2(weather))
+}
+
+type subscribeRequestV2 struct {
+	Email     string          `form:"email" json:"email" binding:"required,email"`
+	City      string          `form:"city" json:"city" binding:"required"`
+	Frequency enums.Frequency `form:"frequency" json:"frequency" binding:"required,oneof=hourly daily"`
+	Units     string          `form:"units" json:"units"`
+	Lang      string          `form:"lang" json:"lang"`
+}
+
+// parsePreferences validates optional units and lang values; empty values
+// fall back to the defaults.
+func parsePreferences(units, lang string) (models.Preferences, bool) {
+	prefs := models.DefaultPreferences()
+	if units != "" {
+		prefs.Units = enums.Units(units)
+		if !prefs.Units.IsValid() {
+			return models.Preferences{}, false
+		}
+	}
+	if lang != "" {
+		var ok bool
+		if prefs.Lang, ok = enums.ParseLanguage(lang); !ok {
+			return models.Preferences{}, false
+		}
+	}
+	return prefs, true
+}
+
+// Subscribe godoc
+//
+//	@Summary		Subscribe to weather updates
+//	@Description	Subscribe an email to receive weather updates for a specific city with chosen frequency. Accepts a form or a JSON body.
+//	@Tags			subscription
+//	@Accept			application/x-www-form-urlencoded,json
+//	@Produce		json
+//	@Param			email		formData	string	true	"Email address to subscribe"
+//	@Param			city		formData	string	true	"City for weather updates"
+//	@Param			frequency	formData	string	true	"Frequency of updates (hourly or daily)"	Enums(hourly, daily)
+//	@Param			units		formData	string	false	"Unit system for notifications"	Enums(metric, imperial)	default(metric)
+//	@Param			lang		formData	string	false	"Language of notifications, e.g. uk"	default(en)
+//	@Success		200			{object}	MessageResponse
+//	@Failure		400			{object}	ErrorResponse	"Invalid input"
+//	@Failure		409			{object}	ErrorResponse	"Email already subscribed"
+//	@Failure		422			{object}	ErrorResponse	"Email address is suppressed"
+//	@Failure		500			{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/subscribe [post]
+func (h *handlerV2) Subscribe(c *gin.Context) {
+	var req subscribeRequestV2
+	if err := c.ShouldBind(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid input")
+		return
+	}
+
+	prefs, ok := parsePreferences(req.Units, req.Lang)
+	if !ok {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid input")
+		return
+	}
+
+	_, err := h.Service.Subscribe(c.Request.Context(), models.Subscription{
+		Channel:   enums.ChannelEmail,
+		Contact:   req.Email,
+		City:      req.City,
+		Frequency: req.Frequency,
+		Units:     prefs.Units,
+		Lang:      prefs.Lang,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrAlreadySubscribed) {
+			abortWithError(c, http.StatusConflict, ErrCodeAlreadySubscribed, "Email already subscribed")
+			return
+		}
+		if errors.Is(err, service.ErrEmailSuppressed) {
+			abortWithError(c, http.StatusUnprocessableEntity, ErrCodeEmailSuppressed, "Email address bounced or reported spam and cannot be subscribed")
+			return
+		}
+
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Subscription successful. Confirmation email sent."})
+}
+
+// ConfirmPage godoc
+//
+//	@Summary		Confirmation page
+//	@Description	Shows the subscription and a button confirming it. Opening the link has no effect, so that mail scanners cannot confirm on the user's behalf.
+//	@Tags			subscription
+//	@Produce		html
+//	@Param			token	path		string	true	"Confirmation token"
+//	@Success		200		{string}	string	"Confirmation page"
+//	@Failure		404		{string}	string	"Token not found"
+//	@Router			/api/v2/confirm/{token} [get]
+func (h *handlerV2) ConfirmPage(c *gin.Context) {
+	showTokenPage(c, h.Service, confirmAction)
+}
+
+// ConfirmSubscription godoc
+//
+//	@Summary		Confirm email subscription
+//	@Description	Confirms a subscription using the token sent in the confirmation email. Browsers submitting the confirmation page get an HTML page.
+//	@Tags			subscription
+//	@Produce		json,html
+//	@Param			token	path		string	true	"Confirmation token"
+//	@Success		200		{object}	MessageResponse
+//	@Failure		400		{object}	ErrorResponse	"Invalid token"
+//	@Failure		404		{object}	ErrorResponse	"Token not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/confirm/{token} [post]
+func (h *handlerV2) ConfirmSubscription(c *gin.Context) {
+	token := c.Param("token")
+	if token == "" {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidToken, "Invalid token")
+		return
+	}
+
+	err := h.Service.Confirm(c.Request.Context(), token)
+	if wantsPage(c) {
+		renderTokenResult(c, confirmAction, err)
+		return
+	}
+	if err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Subscription confirmed successfully"})
+}
+
+// UnsubscribePage godoc
+//
+//	@Summary		Unsubscribe page
+//	@Description	Shows the subscription and a button cancelling it. Opening the link has no effect, so that mail scanners cannot unsubscribe on the user's behalf.
+//	@Tags			subscription
+//	@Produce		html
+//	@Param			token	path		string	true	"Unsubscribe token"
+//	@Success		200		{string}	string	"Unsubscribe page"
+//	@Failure		404		{string}	string	"Token not found"
+//	@Router			/api/v2/unsubscribe/{token} [get]
+func (h *handlerV2) UnsubscribePage(c *gin.Context) {
+	showTokenPage(c, h.Service, unsubscribeAction)
+}
+
+// Unsubscribe godoc
+//
+//	@Summary		Unsubscribe from weather updates
+//	@Description	Unsubscribes from weather updates using the token sent in emails. Also serves one-click unsubscribe (RFC 8058) from mail clients. Browsers submitting the unsubscribe page get an HTML page.
+//	@Tags			subscription
+//	@Accept			application/x-www-form-urlencoded
+//	@Produce		json,html
+//	@Param			token				path		string	true	"Unsubscribe token"
+//	@Param			List-Unsubscribe	formData	string	false	"One-click unsubscribe marker"	Enums(One-Click)
+//	@Success		200					{object}	MessageResponse
+//	@Failure		400					{object}	ErrorResponse	"Invalid token"
+//	@Failure		404					{object}	ErrorResponse	"Token not found"
+//	@Failure		500					{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/unsubscribe/{token} [post]
+func (h *handlerV2) Unsubscribe(c *gin.Context) {
+	token := c.Param("token")
+	if token == "" {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidToken, "Invalid token")
+		return
+	}
+
+	err := h.Service.Unsubscribe(c.Request.Context(), token)
+	if wantsPage(c) {
+		renderTokenResult(c, unsubscribeAction, err)
+		return
+	}
+	if err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Unsubscribed successfully"})
+}
+
+type SubscriptionV2 struct {
+	Channel    string     `json:"channel" enums:"email,telegram,webhook"`
+	Contact    string     `json:"contact"`
+	City       string     `json:"city"`
+	Frequency  string     `json:"frequency" enums:"hourly,daily"`
+	Units      string     `json:"units" enums:"metric,imperial"`
+	Lang       string     `json:"lang"`
+	Confirmed  bool       `json:"confirmed"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+}
+
+func newSubscriptionV2(s models.Subscription) SubscriptionV2 {
+	return SubscriptionV2{
+		Channel:    s.Channel.String(),
+		Contact:    s.Contact,
+		City:       s.City,
+		Frequency:  string(s.Frequency),
+		Units:      s.Units.String(),
+		Lang:       s.Lang.String(),
+		Confirmed:  s.Confirmed,
+		NextRunAt:  s.NextRunAt,
+		LastSentAt: s.LastSentAt,
+	}
+}
+
+// GetSubscription godoc
+//
+//	@Summary		Get subscription
+//	@Description	Returns the subscription identified by the token sent in emails, including when the next update is due and when the last one was delivered.
+//	@Tags			subscription
+//	@Produce		json
+//	@Param			token	path		string	true	"Subscription token"
+//	@Success		200		{object}	SubscriptionV2
+//	@Failure		404		{object}	ErrorResponse	"Token not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/subscriptions/{token} [get]
+func (h *handlerV2) GetSubscription(c *gin.Context) {
+	sub, err := h.Service.Subscription(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newSubscriptionV2(sub))
+}
+
+func (h *handlerV2) handleTokenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTokenNotFound):
+		abortWithError(c, http.StatusNotFound, ErrCodeTokenNotFound, "Token not found")
+	default:
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDContextKey = "request_id"
+
+	// maxRequestIDLength bounds request ids accepted from clients.
+	maxRequestIDLength = 128
+)
+
+// requestContext assigns the request id, taken from X-Request-ID when the
+// client sent a usable one, echoes it in the response and puts a logger
+// annotated with it (and the trace id, when traced) into the request
+// context for service and storage code.
+func requestContext(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+
+		fields := []zap.Field{zap.String("request_id", id)}
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+		}
+		ctx := logging.WithContext(c.Request.Context(), logger.With(fields...))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII without spaces, so a client id
+// cannot break log lines or response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// accessLog writes one line per request. The route pattern is logged
+// instead of the path, which may carry a subscription token. Health probes
+// are not logged.
+func accessLog(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/health/") {
+		c.Next()
+		return
+	}
+
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := c.Writer.Status()
+	fields := []zap.Field{
+		zap.String("method", c.Request.Method),
+		zap.String("route", route),
+		zap.Int("status", status),
+		zap.Duration("latency", time.Since(start)),
+		zap.Int("size", c.Writer.Size()),
+		zap.String("client_ip", c.ClientIP()),
+	}
+	if len(c.Errors) > 0 {
+		fields = append(fields, zap.String("errors", c.Errors.String()))
+	}
+
+	logger := logging.FromContext(c.Request.Context())
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Error("http request", fields...)
+	case status >= http.StatusBadRequest:
+		logger.Warn("http request", fields...)
+	default:
+		logger.Info("http request", fields...)
+	}
+}
+
+// recovery turns a panic in a handler into a logged 500 with the JSON
+// error envelope. Aborted handlers (http.ErrAbortHandler) are re-panicked
+// so that net/http drops the connection.
+func recovery(c *gin.Context) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+			panic(rec)
+		}
+
+		logging.FromContext(c.Request.Context()).Error("panic recovered",
+			zap.String("panic", fmt.Sprint(rec)),
+			zap.ByteString("stack", debug.Stack()),
+		)
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "internal server error")
+	}()
+	c.Next()
+}
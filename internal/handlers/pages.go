@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"embed"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"html/template"
+	"net/http"
+)
+
+//go:embed pages
+var pageFiles embed.FS
+
+var pageTemplate = template.Must(template.ParseFS(pageFiles, "pages/token.html"))
+
+// tokenAction is an action performed by following a link from a message.
+// Links are opened with GET by mail scanners and previews, so GET only
+// shows a page and the action itself requires a POST.
+type tokenAction struct {
+	title  string
+	prompt string
+	button string
+	done   string
+}
+
+var (
+	confirmAction = tokenAction{
+		title:  "Confirm subscription",
+		prompt: "Please confirm that you want to receive",
+		button: "Confirm subscription",
+		done:   "Subscription confirmed successfully",
+	}
+	unsubscribeAction = tokenAction{
+		title:  "Unsubscribe",
+		prompt: "You will no longer receive",
+		button: "Unsubscribe",
+		done:   "Unsubscribed successfully",
+	}
+)
+
+type page struct {
+	Title        string
+	Message      string
+	Subscription *models.Subscription
+	Action       string
+	Button       string
+}
+
+// showTokenPage renders the page asking to perform action; the form posts
+// back to the same path.
+func showTokenPage(c *gin.Context, svc service.Service, action tokenAction) {
+	sub, err := svc.Subscription(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		renderTokenError(c, action, err)
+		return
+	}
+
+	if action == confirmAction && sub.Confirmed {
+		renderPage(c, http.StatusOK, page{Title: action.title, Message: "This subscription is already confirmed.", Subscription: &sub})
+		return
+	}
+
+	renderPage(c, http.StatusOK, page{
+		Title:        action.title,
+		Message:      action.prompt + ":",
+		Subscription: &sub,
+		Action:       c.Request.URL.Path,
+		Button:       action.button,
+	})
+}
+
+// wantsPage reports whether the client, typically a browser submitting the
+// form, prefers HTML to JSON. One-click unsubscribe requests from mail
+// clients get JSON.
+func wantsPage(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
+
+// renderTokenResult renders the outcome of a posted action.
+func renderTokenResult(c *gin.Context, action tokenAction, err error) {
+	if err != nil {
+		renderTokenError(c, action, err)
+		return
+	}
+	renderPage(c, http.StatusOK, page{Title: action.title, Message: action.done + "."})
+}
+
+func renderTokenError(c *gin.Context, action tokenAction, err error) {
+	if errors.Is(err, service.ErrTokenNotFound) {
+		renderPage(c, http.StatusNotFound, page{Title: action.title, Message: "This link is invalid or has already been used."})
+		return
+	}
+	renderPage(c, http.StatusInternalServerError, page{Title: action.title, Message: "Something went wrong. Please try again later."})
+}
+
+func renderPage(c *gin.Context, status int, p page) {
+	// The token in the URL must not leak to other sites or caches.
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := pageTemplate.Execute(c.Writer, p); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
+	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type NotificationPreview struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// PreviewNotification godoc
+//
+//	@Summary		Preview a notification
+//	@Description	Renders a notification template with sample data. Not available in prod.
+//	@Tags			dev
+//	@Produce		html,plain,json
+//	@Param			kind	path		string	true	"Notification kind"	Enums(confirmation, hourly_update, daily_digest, alert, goodbye)
+//	@Param			lang	query		string	false	"Template locale"	default(en)
+//	@Param			units	query		string	false	"Unit system"		Enums(metric, imperial)	default(metric)
+//	@Param			format	query		string	false	"Output format"		Enums(html, text, json)	default(html)
+//	@Success		200		{object}	NotificationPreview
+//	@Failure		400		{object}	ErrorResponse	"Invalid input"
+//	@Failure		404		{object}	ErrorResponse	"Unknown notification kind"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/dev/notifications/{kind} [get]
+func previewNotification(renderer notification.Renderer, baseURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prefs, ok := parsePreferences(c.Query("units"), "")
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid 'units' query parameter")
+			return
+		}
+		// Override templates may add locales unknown to weatherapi.com, so lang is not validated.
+		if lang := c.Query("lang"); lang != "" {
+			prefs.Lang = enums.Language(strings.ToLower(lang))
+		}
+
+		msg, err := renderer.Render(notification.Kind(c.Param("kind")), prefs.Lang, samplePreviewData(prefs, baseURL))
+		if err != nil {
+			if errors.Is(err, notification.ErrUnknownKind) {
+				abortWithError(c, http.StatusNotFound, ErrCodeNotFound, "Unknown notification kind")
+				return
+			}
+			logging.FromContext(c.Request.Context()).Error("notification preview failed", zap.Error(err))
+			abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+			return
+		}
+
+		switch c.DefaultQuery("format", "html") {
+		case "html":
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+		case "text":
+			c.String(http.StatusOK, "Subject: %s\n\n%s", msg.Subject, msg.Text)
+		case "json":
+			c.JSON(http.StatusOK, NotificationPreview{Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML})
+		default:
+			abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid 'format' query parameter")
+		}
+	}
+}
+
+func samplePreviewData(prefs models.Preferences, baseURL string) notification.Data {
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	weather := models.Weather{
+		City:        "Kyiv",
+		Country:     "Ukraine",
+		Temperature: 22.5,
+		FeelsLike:   21.8,
+		Description: "Partly cloudy",
+		Humidity:    60,
+		WindSpeed:   15.1,
+		Units:       prefs.Units,
+		Lang:        prefs.Lang,
+		UpdatedAt:   time.Now().UTC().Truncate(time.Minute),
+	}
+	if prefs.Units == enums.UnitsImperial {
+		weather.Temperature, weather.FeelsLike, weather.WindSpeed = 72.5, 71.2, 9.4
+	}
+
+	return notification.Data{
+		Subscription: models.Subscription{
+			Channel:   enums.ChannelEmail,
+			Contact:   "preview@example.com",
+			City:      "Kyiv",
+			Frequency: enums.FrequencyDaily,
+			Units:     prefs.Units,
+			Lang:      prefs.Lang,
+			Token:     "preview-token",
+		},
+		Weather: weather,
+		Alert: notification.Alert{
+			Title:   "Strong wind warning",
+			Message: "Gusts up to 70 km/h are expected this afternoon.",
+		},
+		ConfirmURL:     baseURL + "/api/v2/confirm/preview-token",
+		UnsubscribeURL: baseURL + "/api/v2/unsubscribe/preview-token",
+		SubscribeURL:   baseURL + "/",
+	}
+}
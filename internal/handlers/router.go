@@ -4,25 +4,53 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
+	"github.com/serjnester/weather-subscription-service/internal/service"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 	"net/http"
+	"strconv"
 )
 
 type RouterParams struct {
 	Config configs.Config
+	// APIKeys enables API key checks on the routes listed in Config.Auth.
+	APIKeys service.APIKeyService
+	// Notifications enables the template preview endpoint outside prod.
+	Notifications notification.Renderer
+	// Logger writes the access log and is put into each request context;
+	// nothing is logged when unset.
+	Logger *zap.Logger
 }
 
 // NewRouter godoc
 //
-//	@title			Weather Forecast API
-//	@version		1.0
-//	@description	Weather API application that allows users to subscribe to weather updates for their city.
+//	@title						Weather Forecast API
+//	@version					1.0
+//	@description				Weather API application that allows users to subscribe to weather updates for their city.
+//	@securityDefinitions.apikey	APIKey
+//	@in							header
+//	@name						X-API-Key
+//	@securityDefinitions.apikey	AdminToken
+//	@in							header
+//	@name						Authorization
 func NewRouter(params RouterParams) *gin.Engine {
 	if !params.Config.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	logger := params.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
 	router := gin.New()
+	router.Use(tracingMiddleware(params.Config.Tracing.ServiceName)...)
+	router.Use(requestContext(logger), accessLog, recovery)
+
+	if params.APIKeys != nil {
+		router.Use(apiKeyAuth(params.APIKeys, NewAuthPolicy(params.Config.Auth)))
+	}
 
 	router.NoMethod(missingRoutePath)
 	router.NoRoute(missingRoutePath)
@@ -32,6 +60,10 @@ func NewRouter(params RouterParams) *gin.Engine {
 
 	if !params.Config.Env.IsProd() {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+		if params.Notifications != nil {
+			router.GET("/dev/notifications/:kind", previewNotification(params.Notifications, params.Config.Notification.BaseURL))
+		}
 	}
 
 	return router
@@ -43,14 +75,89 @@ func missingRoutePath(c *gin.Context) {
 
 type RegisterHandlersParams struct {
 	MainHandler Handler
+	V2Handler   HandlerV2
+	// V1Deprecation is advertised on every v1 response.
+	V1Deprecation configs.API
+
+	// StreamHandler is optional; it serves /api/weather/stream, outside the
+	// deprecated v1 group, and the same stream at /api/v2/weather/stream.
+	StreamHandler StreamHandler
+
+	// FeedHandler is optional; it serves /feeds/{token}.ics|.rss.
+	FeedHandler FeedHandler
+
+	// EmailEventsHandler is registered only when EmailEventsToken is set.
+	EmailEventsHandler EmailEventsHandler
+	EmailEventsToken   string
+
+	// AdminHandler is registered under /admin only when AdminToken is set.
+	AdminHandler AdminHandler
+	AdminToken   string
 }
 
 func RegisterHandlers(router *gin.Engine, params RegisterHandlersParams) {
 	handler := params.MainHandler
 
-	api := router.Group("/api")
+	api := router.Group("/api", deprecated(params.V1Deprecation, "/api/v2"))
 	api.GET("/weather", handler.GetWeather)
 	api.POST("/subscribe", handler.Subscribe)
-	api.GET("/confirm/:token", handler.ConfirmSubscription)
-	api.GET("/unsubscribe/:token", handler.Unsubscribe)
+	api.GET("/confirm/:token", handler.ConfirmPage)
+	api.POST("/confirm/:token", handler.ConfirmSubscription)
+	api.GET("/unsubscribe/:token", handler.UnsubscribePage)
+	api.POST("/unsubscribe/:token", handler.Unsubscribe)
+
+	v2Handler := params.V2Handler
+
+	v2 := router.Group("/api/v2")
+	v2.GET("/weather", v2Handler.GetWeather)
+	v2.POST("/subscribe", v2Handler.Subscribe)
+	v2.GET("/confirm/:token", v2Handler.ConfirmPage)
+	v2.POST("/confirm/:token", v2Handler.ConfirmSubscription)
+	v2.GET("/unsubscribe/:token", v2Handler.UnsubscribePage)
+	v2.POST("/unsubscribe/:token", v2Handler.Unsubscribe)
+	v2.GET("/subscriptions/:token", v2Handler.GetSubscription)
+	v2.POST("/webhooks", v2Handler.SubscribeWebhook)
+
+	if params.StreamHandler != nil {
+		router.GET("/api/weather/stream", params.StreamHandler.StreamWeather)
+		v2.GET("/weather/stream", params.StreamHandler.StreamWeather)
+	}
+
+	if params.FeedHandler != nil {
+		router.GET("/feeds/:file", params.FeedHandler.GetFeed)
+	}
+
+	if params.EmailEventsHandler != nil && params.EmailEventsToken != "" {
+		v2.POST("/email/events", eventsAuth(params.EmailEventsToken), params.EmailEventsHandler.HandleEmailEvents)
+	}
+
+	if params.AdminHandler != nil && params.AdminToken != "" {
+		adminHandler := params.AdminHandler
+
+		admin := router.Group("/admin", adminAuth(params.AdminToken))
+		admin.GET("/api-keys", adminHandler.ListAPIKeys)
+		admin.POST("/api-keys", adminHandler.CreateAPIKey)
+		admin.DELETE("/api-keys/:id", adminHandler.RevokeAPIKey)
+		admin.GET("/deliveries", adminHandler.ListDeliveries)
+		admin.GET("/suppressions", adminHandler.ListSuppressions)
+		admin.DELETE("/suppressions/:email", adminHandler.RemoveSuppression)
+		admin.GET("/log-level", adminHandler.GetLogLevel)
+		admin.PUT("/log-level", adminHandler.SetLogLevel)
+	}
+}
+
+// deprecated marks responses of a superseded API version with the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and successor Link headers.
+func deprecated(api configs.API, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !api.V1DeprecatedAt.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(api.V1DeprecatedAt.Unix(), 10))
+		}
+		if !api.V1Sunset.IsZero() {
+			c.Header("Sunset", api.V1Sunset.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+
+		c.Next()
+	}
 }
@@ -0,0 +1,931 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestRouter(svc service.Service, api configs.API) http.Handler {
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:   NewHandler(svc),
+		V2Handler:     NewHandlerV2(svc),
+		V1Deprecation: api,
+	})
+	return router
+}
+
+func TestRouter_Versioning(t *testing.T) {
+	svc := &service.MockService{
+		WeatherForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			if city == "Nowhere" {
+				return models.Weather{}, weatherapi.ErrCityNotFound
+			}
+			if prefs.Units == enums.UnitsImperial && prefs.Lang == "uk" {
+				return models.Weather{City: "Kyiv", Temperature: 72.5, Description: "Хмарно", Humidity: 60}, nil
+			}
+			return models.Weather{
+				City:        "Kyiv",
+				Country:     "Ukraine",
+				Temperature: 22.5,
+				FeelsLike:   21.8,
+				Description: "Cloudy",
+				Humidity:    60,
+				WindSpeed:   15,
+			}, nil
+		},
+	}
+	api := configs.API{
+		V1DeprecatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		V1Sunset:       time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+	}
+	router := newTestRouter(svc, api)
+
+	t.Run("v1 keeps contract and is marked deprecated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/weather?city=Kyiv", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"temperature":22.5,"description":"Cloudy","humidity":60}`, rec.Body.String())
+		require.Equal(t, "@1792368000", rec.Header().Get("Deprecation"))
+		require.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+		require.Equal(t, `</api/v2>; rel="successor-version"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("v1 sends no lifecycle dates that are not configured", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newTestRouter(svc, configs.API{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/weather?city=Kyiv", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Deprecation"))
+		require.Empty(t, rec.Header().Get("Sunset"))
+		require.Equal(t, `</api/v2>; rel="successor-version"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("v1 accepts units and lang", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/weather?city=Kyiv&units=imperial&lang=uk", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"temperature":72.5,"description":"Хмарно","humidity":60}`, rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/weather?city=Kyiv&units=kelvin", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.JSONEq(t, `"Invalid 'units' or 'lang' query parameter"`, rec.Body.String())
+	})
+
+	t.Run("v1 errors stay plain strings", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/weather?city=Nowhere", nil))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.JSONEq(t, `"City not found"`, rec.Body.String())
+	})
+
+	t.Run("v2 returns rich weather without deprecation", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/weather?city=Kyiv", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Deprecation"))
+
+		var got WeatherV2
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, "Kyiv", got.City)
+		require.Equal(t, 21.8, got.FeelsLike)
+	})
+
+	t.Run("v2 rejects unknown units", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/weather?city=Kyiv&units=kelvin", nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("v2 errors use JSON envelope", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/weather?city=Nowhere", nil))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+
+		var got ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, ErrCodeCityNotFound, got.Error.Code)
+	})
+}
+
+func TestRouter_APIKeyAuth(t *testing.T) {
+	svc := &service.MockService{
+		WeatherForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			return models.Weather{City: city}, nil
+		},
+	}
+	keys := &service.MockAPIKeyService{
+		AuthenticateAPIKeyFn: func(ctx context.Context, key string) (models.APIKey, error) {
+			switch key {
+			case "valid":
+				return models.APIKey{ID: 1, DailyQuota: 10, RequestsToday: 3}, nil
+			case "exhausted":
+				return models.APIKey{ID: 2, DailyQuota: 10, RequestsToday: 11}, service.ErrAPIKeyQuotaExceeded
+			default:
+				return models.APIKey{}, service.ErrAPIKeyInvalid
+			}
+		},
+	}
+
+	router := NewRouter(RouterParams{
+		Config: configs.Config{Env: configs.EnvTypeProd, Auth: configs.Auth{
+			APIKeyRequiredRoutes: []string{"/api/v2/weather", "/api/v2/weather/stream"},
+		}},
+		APIKeys: keys,
+	})
+	stream := &service.MockWeatherStream{
+		WatchFn: func(ctx context.Context, city string, prefs models.Preferences) (<-chan models.Weather, func(), error) {
+			return nil, nil, weatherapi.ErrCityNotFound
+		},
+	}
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:   NewHandler(svc),
+		V2Handler:     NewHandlerV2(svc),
+		StreamHandler: NewStreamHandler(stream, time.Second),
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+	}{
+		{name: "missing key", path: "/api/v2/weather?city=Kyiv", wantStatus: http.StatusUnauthorized},
+		{name: "invalid key", path: "/api/v2/weather?city=Kyiv", key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "quota exceeded", path: "/api/v2/weather?city=Kyiv", key: "exhausted", wantStatus: http.StatusTooManyRequests},
+		{name: "valid key", path: "/api/v2/weather?city=Kyiv", key: "valid", wantStatus: http.StatusOK},
+		{name: "route without policy", path: "/api/weather?city=Kyiv", wantStatus: http.StatusOK},
+		{name: "stream without key", path: "/api/v2/weather/stream?city=Kyiv", wantStatus: http.StatusUnauthorized},
+		{name: "stream with key", path: "/api/v2/weather/stream?city=Nowhere", key: "valid", wantStatus: http.StatusNotFound},
+		{name: "stream outside the policy", path: "/api/weather/stream?city=Nowhere", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+
+	t.Run("quota headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/weather?city=Kyiv", nil)
+		req.Header.Set(APIKeyHeader, "valid")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, "10", rec.Header().Get("X-RateLimit-Limit"))
+		require.Equal(t, "7", rec.Header().Get("X-RateLimit-Remaining"))
+	})
+}
+
+func TestRouter_NotificationPreview(t *testing.T) {
+	renderer, err := notification.NewTemplateRenderer("")
+	require.NoError(t, err)
+
+	t.Run("available outside prod", func(t *testing.T) {
+		router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeDev}, Notifications: renderer})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dev/notifications/daily_digest?lang=uk&units=imperial&format=json", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var got NotificationPreview
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Contains(t, got.Subject, "щоденний прогноз")
+		require.Contains(t, got.Text, "°F")
+
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dev/notifications/unknown", nil))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("template errors are not exposed", func(t *testing.T) {
+		broken := &notification.MockRenderer{
+			RenderFn: func(kind notification.Kind, lang enums.Language, data notification.Data) (notification.Message, error) {
+				return notification.Message{}, errors.New(`template: daily_digest.html:3: executing "body" at <.Secret>`)
+			},
+		}
+		router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeDev}, Notifications: broken})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dev/notifications/daily_digest", nil))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.NotContains(t, rec.Body.String(), "template")
+
+		var got ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Equal(t, ErrCodeInternal, got.Error.Code)
+	})
+
+	t.Run("hidden in prod", func(t *testing.T) {
+		router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}, Notifications: renderer})
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dev/notifications/daily_digest", nil))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRouter_SubscribeWebhook(t *testing.T) {
+	svc := &service.MockService{
+		SubscribeFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+			require.Equal(t, enums.ChannelWebhook, sub.Channel)
+			switch sub.Contact {
+			case "http://example.com/hook":
+				return models.Subscription{}, service.ErrInvalidWebhookURL
+			case "https://example.com/broken":
+				return models.Subscription{}, service.ErrWebhookVerificationFailed
+			}
+			sub.Token, sub.WebhookSecret = "token", "secret"
+			return sub, nil
+		},
+		IssueTokenFn: func(sub models.Subscription, purpose enums.TokenPurpose) string {
+			require.Equal(t, enums.TokenPurposeManage, purpose)
+			return "manage-" + sub.Token
+		},
+	}
+	router := newTestRouter(svc, configs.API{})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "created",
+			body:       `{"url":"https://example.com/hook","city":"Kyiv","frequency":"hourly"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"token":"manage-token","secret":"secret"}`,
+		},
+		{
+			name:       "invalid input",
+			body:       `{"url":"https://example.com/hook","city":"Kyiv","frequency":"weekly"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "insecure url",
+			body:       `{"url":"http://example.com/hook","city":"Kyiv","frequency":"hourly"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "verification failed",
+			body:       `{"url":"https://example.com/broken","city":"Kyiv","frequency":"hourly"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				require.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_StreamWeather(t *testing.T) {
+	svc := &service.MockService{}
+	updates := make(chan models.Weather, 1)
+	stopped := make(chan struct{})
+	stream := &service.MockWeatherStream{
+		WatchFn: func(ctx context.Context, city string, prefs models.Preferences) (<-chan models.Weather, func(), error) {
+			switch city {
+			case "Nowhere":
+				return nil, nil, weatherapi.ErrCityNotFound
+			case "Lviv":
+				return nil, nil, service.ErrStreamLimit
+			}
+			require.Equal(t, "Kyiv", city)
+			require.Equal(t, enums.UnitsImperial, prefs.Units)
+			return updates, func() { close(stopped) }, nil
+		},
+	}
+
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:   NewHandler(svc),
+		V2Handler:     NewHandlerV2(svc),
+		StreamHandler: NewStreamHandler(stream, 10*time.Millisecond),
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("unknown city", func(t *testing.T) {
+		for _, path := range []string{"/api/weather/stream", "/api/v2/weather/stream"} {
+			resp, err := http.Get(server.URL + path + "?city=Nowhere")
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+		}
+	})
+
+	t.Run("too many cities", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/weather/stream?city=Lviv")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("streams updates and heartbeats until the client leaves", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/weather/stream?city=Kyiv&units=imperial", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		require.Empty(t, resp.Header.Get("Deprecation"))
+
+		updates <- models.Weather{City: "Kyiv", Temperature: 72.5, Units: enums.UnitsImperial}
+
+		events := map[string]string{}
+		scanner := bufio.NewScanner(resp.Body)
+		var event string
+		for len(events) < 2 && scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event = line[len("event:"):]
+			case strings.HasPrefix(line, "data:"):
+				events[event] = line[len("data:"):]
+			}
+		}
+		require.NoError(t, scanner.Err())
+
+		var weather WeatherV2
+		require.NoError(t, json.Unmarshal([]byte(events[EventWeather]), &weather))
+		require.Equal(t, 72.5, weather.Temperature)
+		require.Contains(t, events, EventHeartbeat)
+
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("watcher was not released after the client disconnected")
+		}
+	})
+}
+
+func TestRouter_Feeds(t *testing.T) {
+	svc := &service.MockService{
+		SubscriptionForecastFn: func(ctx context.Context, token string, days int) (models.Subscription, models.Forecast, error) {
+			city, ok := map[string]string{"tok": "New York", "quoted": `Kyiv"; x=1` + "\r\nX-Evil: 1", "utf8": "Київ"}[token]
+			if !ok {
+				return models.Subscription{}, models.Forecast{}, service.ErrTokenNotFound
+			}
+			require.Equal(t, 3, days)
+			return models.Subscription{City: city, Token: token}, models.Forecast{
+				City:      city,
+				Units:     enums.UnitsImperial,
+				UpdatedAt: time.Date(2025, 5, 19, 6, 30, 0, 0, time.UTC),
+				Days: []models.DailyForecast{
+					{Date: time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC), MinTemp: 50, MaxTemp: 68, Description: "Sunny"},
+				},
+			}, nil
+		},
+		IssueTokenFn: func(sub models.Subscription, purpose enums.TokenPurpose) string {
+			return string(purpose) + "-" + sub.Token
+		},
+	}
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler: NewHandler(svc),
+		V2Handler:   NewHandlerV2(svc),
+		FeedHandler: NewFeedHandler(svc, 3, 30*time.Minute, "https://weather.example.com/"),
+	})
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("ics", func(t *testing.T) {
+		rec := get("/feeds/tok.ics", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+		require.Equal(t, "private, max-age=1800", rec.Header().Get("Cache-Control"))
+		require.Equal(t, "Mon, 19 May 2025 06:30:00 GMT", rec.Header().Get("Last-Modified"))
+		require.Contains(t, rec.Header().Get("Content-Disposition"), "weather-new-york.ics")
+		require.Contains(t, rec.Body.String(), "SUMMARY:New York: Sunny\\, 50°F…68°F")
+		require.Contains(t, strings.ReplaceAll(rec.Body.String(), "\r\n ", ""), "https://weather.example.com/api/v2/unsubscribe/unsubscribe-tok")
+
+		etag := rec.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rec = get("/feeds/tok.ics", http.Header{"If-None-Match": {etag}})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+		require.Empty(t, rec.Body.String())
+
+		rec = get("/feeds/tok.ics", http.Header{"If-Modified-Since": {"Mon, 19 May 2025 06:30:00 GMT"}})
+		require.Equal(t, http.StatusNotModified, rec.Code)
+
+		rec = get("/feeds/tok.ics", http.Header{"If-Modified-Since": {"Mon, 19 May 2025 06:00:00 GMT"}})
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("ics filename is escaped", func(t *testing.T) {
+		for token, want := range map[string]string{
+			"quoted": "weather-kyiv\";-x=1\r\nx-evil:-1.ics",
+			"utf8":   "weather-київ.ics",
+		} {
+			rec := get("/feeds/"+token+".ics", nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			disposition := rec.Header().Get("Content-Disposition")
+			require.NotContains(t, disposition, "\n")
+			typ, params, err := mime.ParseMediaType(disposition)
+			require.NoError(t, err)
+			require.Equal(t, "inline", typ)
+			require.Equal(t, want, params["filename"])
+		}
+	})
+
+	t.Run("rss", func(t *testing.T) {
+		rec := get("/feeds/tok.rss", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/rss+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+		require.Contains(t, rec.Body.String(), "<title>Weather forecast: New York</title>")
+		require.NotEqual(t, get("/feeds/tok.ics", nil).Header().Get("ETag"), rec.Header().Get("ETag"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, get("/feeds/unknown.ics", nil).Code)
+		require.Equal(t, http.StatusNotFound, get("/feeds/tok.pdf", nil).Code)
+	})
+}
+
+func TestRouter_GetSubscription(t *testing.T) {
+	sentAt := time.Date(2025, 5, 20, 8, 0, 3, 0, time.UTC)
+	svc := &service.MockService{
+		SubscriptionFn: func(ctx context.Context, token string) (models.Subscription, error) {
+			if token != "token" {
+				return models.Subscription{}, service.ErrTokenNotFound
+			}
+			return models.Subscription{
+				ID:         7,
+				Channel:    enums.ChannelEmail,
+				Contact:    "user@example.com",
+				City:       "Kyiv",
+				Frequency:  enums.FrequencyDaily,
+				Units:      enums.UnitsMetric,
+				Lang:       enums.LanguageEnglish,
+				Token:      token,
+				Confirmed:  true,
+				NextRunAt:  time.Date(2025, 5, 21, 8, 0, 0, 0, time.UTC),
+				LastSentAt: &sentAt,
+			}, nil
+		},
+	}
+	router := newTestRouter(svc, configs.API{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/subscriptions/token", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{
+		"channel": "email",
+		"contact": "user@example.com",
+		"city": "Kyiv",
+		"frequency": "daily",
+		"units": "metric",
+		"lang": "en",
+		"confirmed": true,
+		"next_run_at": "2025-05-21T08:00:00Z",
+		"last_sent_at": "2025-05-20T08:00:03Z"
+	}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/subscriptions/unknown", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRouter_TokenLinks(t *testing.T) {
+	var unsubscribed, confirmed []string
+	svc := &service.MockService{
+		SubscriptionFn: func(ctx context.Context, token string) (models.Subscription, error) {
+			if token != "token" {
+				return models.Subscription{}, service.ErrTokenNotFound
+			}
+			return models.Subscription{Channel: enums.ChannelEmail, Contact: "user@example.com", City: "Kyiv", Frequency: enums.FrequencyDaily, Token: token}, nil
+		},
+		ConfirmFn: func(ctx context.Context, token string) error {
+			confirmed = append(confirmed, token)
+			return nil
+		},
+		UnsubscribeFn: func(ctx context.Context, token string) error {
+			if token != "token" {
+				return service.ErrTokenNotFound
+			}
+			unsubscribed = append(unsubscribed, token)
+			return nil
+		},
+	}
+	router := newTestRouter(svc, configs.API{})
+
+	t.Run("GET shows a page without side effects", func(t *testing.T) {
+		for _, path := range []string{"/api/v2/unsubscribe/token", "/api/unsubscribe/token", "/api/v2/confirm/token", "/api/confirm/token"} {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			require.Equal(t, http.StatusOK, rec.Code, path)
+			require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+			require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			require.Contains(t, rec.Body.String(), `<form method="post" action="`+path+`">`)
+			require.Contains(t, rec.Body.String(), "user@example.com")
+		}
+		require.Empty(t, unsubscribed)
+		require.Empty(t, confirmed)
+	})
+
+	t.Run("GET with unknown token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/unsubscribe/unknown", nil))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.NotContains(t, rec.Body.String(), "<form")
+	})
+
+	t.Run("one-click POST unsubscribes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/unsubscribe/token", strings.NewReader("List-Unsubscribe=One-Click"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"message":"Unsubscribed successfully"}`, rec.Body.String())
+		require.Equal(t, []string{"token"}, unsubscribed)
+	})
+
+	t.Run("form POST from a browser gets a page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/confirm/token", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Subscription confirmed successfully.")
+		require.Equal(t, []string{"token"}, confirmed)
+	})
+
+	t.Run("v1 POST keeps plain string responses", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/unsubscribe/unknown", nil))
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.JSONEq(t, `"Token not found"`, rec.Body.String())
+	})
+}
+
+func TestRouter_AdminDeliveries(t *testing.T) {
+	deliveries := &service.MockDeliveryService{
+		ListDeliveriesFn: func(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+			if filter.Status != "" && !filter.Status.IsValid() {
+				return nil, service.ErrInvalidDeliveryFilter
+			}
+			require.Equal(t, models.DeliveryFilter{SubscriptionID: 7, Status: enums.DeliveryStatusFailed, BeforeID: 100}, filter)
+			return []models.Delivery{{ID: 99, SubscriptionID: 7, Status: enums.DeliveryStatusFailed, Error: "boom"}}, nil
+		},
+	}
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:  NewHandler(&service.MockService{}),
+		V2Handler:    NewHandlerV2(&service.MockService{}),
+		AdminHandler: NewAdminHandler(&service.MockAPIKeyService{}, deliveries, &service.MockSuppressionService{}, zap.NewAtomicLevel()),
+		AdminToken:   "admin",
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		token      string
+		wantStatus int
+	}{
+		{name: "filtered", query: "subscription_id=7&status=failed&before_id=100", token: "admin", wantStatus: http.StatusOK},
+		{name: "invalid status", query: "status=queued", token: "admin", wantStatus: http.StatusBadRequest},
+		{name: "invalid id", query: "subscription_id=x", token: "admin", wantStatus: http.StatusBadRequest},
+		{name: "unauthorized", token: "nope", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/deliveries?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				var got []models.Delivery
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				require.Len(t, got, 1)
+				require.Equal(t, "boom", got[0].Error)
+			}
+		})
+	}
+}
+
+func TestRouter_AdminCreateAPIKey(t *testing.T) {
+	keys := &service.MockAPIKeyService{
+		CreateAPIKeyFn: func(ctx context.Context, name string, dailyQuota int) (models.APIKey, string, error) {
+			if name == "rejected" {
+				return models.APIKey{}, "", fmt.Errorf("[APIKeyService.CreateAPIKey] %w", service.ErrAPIKeyInvalid)
+			}
+			return models.APIKey{ID: 1, Name: name, DailyQuota: dailyQuota}, "wss_key", nil
+		},
+	}
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:  NewHandler(&service.MockService{}),
+		V2Handler:    NewHandlerV2(&service.MockService{}),
+		AdminHandler: NewAdminHandler(keys, &service.MockDeliveryService{}, &service.MockSuppressionService{}, zap.NewAtomicLevel()),
+		AdminToken:   "admin",
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "created", body: `{"name":"partner","daily_quota":100}`, wantStatus: http.StatusCreated},
+		{name: "negative quota", body: `{"name":"partner","daily_quota":-1}`, wantStatus: http.StatusBadRequest},
+		{name: "empty name", body: `{"name":""}`, wantStatus: http.StatusBadRequest},
+		{name: "rejected by service", body: `{"name":"rejected"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer admin")
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus == http.StatusBadRequest {
+				require.Contains(t, rec.Body.String(), ErrCodeInvalidInput)
+			}
+		})
+	}
+}
+
+func TestRouter_EmailEvents(t *testing.T) {
+	var got []models.EmailEvent
+	suppressions := &service.MockSuppressionService{
+		HandleEmailEventsFn: func(ctx context.Context, events []models.EmailEvent) (int, error) {
+			got = events
+			return 1, nil
+		},
+		RemoveSuppressionFn: func(ctx context.Context, email string) error {
+			if email != "gone@example.com" {
+				return service.ErrSuppressionNotFound
+			}
+			return nil
+		},
+	}
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:        NewHandler(&service.MockService{}),
+		V2Handler:          NewHandlerV2(&service.MockService{}),
+		EmailEventsHandler: NewEmailEventsHandler(suppressions),
+		EmailEventsToken:   "events",
+		AdminHandler:       NewAdminHandler(&service.MockAPIKeyService{}, &service.MockDeliveryService{}, suppressions, zap.NewAtomicLevel()),
+		AdminToken:         "admin",
+	})
+
+	t.Run("json with bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/email/events",
+			strings.NewReader(`[{"type":"bounce","email":"gone@example.com","bounce_type":"hard"},{"type":"bounce","email":"full@example.com","bounce_type":"soft"}]`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer events")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"received":2,"suppressed":1}`, rec.Body.String())
+		require.Len(t, got, 2)
+	})
+
+	t.Run("dsn with query token", func(t *testing.T) {
+		body := "--b\r\nContent-Type: message/delivery-status\r\n\r\n" +
+			"Reporting-MTA: dns; mx.example.net\r\n\r\n" +
+			"Final-Recipient: rfc822; gone@example.com\r\nAction: failed\r\nStatus: 5.1.1\r\n" +
+			"--b--\r\n"
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/email/events?token=events", strings.NewReader(body))
+		req.Header.Set("Content-Type", `multipart/report; report-type=delivery-status; boundary="b"`)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, []models.EmailEvent{
+			{Type: enums.EmailEventBounce, Email: "gone@example.com", Permanent: true, Status: "5.1.1"},
+		}, got)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/email/events?token=events", strings.NewReader("hello"))
+		req.Header.Set("Content-Type", "text/plain")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/email/events?token=nope", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("admin removes suppression", func(t *testing.T) {
+		for email, want := range map[string]int{"gone@example.com": http.StatusOK, "other@example.com": http.StatusNotFound} {
+			req := httptest.NewRequest(http.MethodDelete, "/admin/suppressions/"+email, nil)
+			req.Header.Set("Authorization", "Bearer admin")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, want, rec.Code, email)
+		}
+	})
+}
+
+func TestRouter_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	svc := &service.MockService{
+		SubscriptionFn: func(ctx context.Context, token string) (models.Subscription, error) {
+			return models.Subscription{Channel: enums.ChannelEmail, City: "Kyiv", Frequency: enums.FrequencyDaily, Token: token}, nil
+		},
+	}
+	router := newTestRouter(svc, configs.API{})
+
+	for _, path := range []string{"/api/v2/unsubscribe/secret-token", "/health/liveness"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1, "health probes are not traced")
+	require.Equal(t, "/api/v2/unsubscribe/:token", spans[0].Name())
+	require.Contains(t, spans[0].Attributes(), attribute.String("http.target", "/api/v2/unsubscribe/:token"))
+	for _, attr := range spans[0].Attributes() {
+		require.NotContains(t, attr.Value.Emit(), "secret-token")
+	}
+}
+
+func TestRouter_RequestLogging(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	svc := &service.MockService{
+		UnsubscribeFn: func(ctx context.Context, token string) error {
+			logging.FromContext(ctx).Info("from service")
+			return nil
+		},
+	}
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}, Logger: zap.New(core)})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler: NewHandler(svc),
+		V2Handler:   NewHandlerV2(svc),
+	})
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	t.Run("request id is propagated to the response and the logs", func(t *testing.T) {
+		logs.TakeAll()
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/unsubscribe/secret-token", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+
+		entries := logs.TakeAll()
+		require.Len(t, entries, 2)
+		require.Equal(t, "from service", entries[0].Message)
+		require.Equal(t, "req-1", entries[0].ContextMap()["request_id"])
+
+		access := entries[1].ContextMap()
+		require.Equal(t, "http request", entries[1].Message)
+		require.Equal(t, "req-1", access["request_id"])
+		require.Equal(t, "/api/v2/unsubscribe/:token", access["route"])
+		require.EqualValues(t, http.StatusOK, access["status"])
+	})
+
+	t.Run("invalid request id is replaced", func(t *testing.T) {
+		for _, id := range []string{"", "has space", strings.Repeat("a", 129)} {
+			req := httptest.NewRequest(http.MethodGet, "/health/liveness", nil)
+			req.Header.Set(RequestIDHeader, id)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Len(t, rec.Header().Get(RequestIDHeader), 32, id)
+		}
+		require.Empty(t, logs.TakeAll(), "health probes are not logged")
+	})
+
+	t.Run("panic is recovered", func(t *testing.T) {
+		logs.TakeAll()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Equal(t, ErrCodeInternal, resp.Error.Code)
+
+		entries := logs.TakeAll()
+		require.Len(t, entries, 2)
+		require.Equal(t, "panic recovered", entries[0].Message)
+		require.Equal(t, zap.ErrorLevel, entries[1].Level)
+	})
+}
+
+func TestRouter_AdminLogLevel(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zap.InfoLevel)
+	router := NewRouter(RouterParams{Config: configs.Config{Env: configs.EnvTypeProd}})
+	RegisterHandlers(router, RegisterHandlersParams{
+		MainHandler:  NewHandler(&service.MockService{}),
+		V2Handler:    NewHandlerV2(&service.MockService{}),
+		AdminHandler: NewAdminHandler(&service.MockAPIKeyService{}, &service.MockDeliveryService{}, &service.MockSuppressionService{}, level),
+		AdminToken:   "admin",
+	})
+
+	do := func(method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/log-level", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level": "info"}`, rec.Body.String())
+
+	rec = do(http.MethodPut, `{"level": "debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, zap.DebugLevel, level.Level())
+
+	rec = do(http.MethodPut, `{"level": "verbose"}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, zap.DebugLevel, level.Level())
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SSE event names sent by the weather stream.
+const (
+	EventWeather   = "weather"
+	EventHeartbeat = "heartbeat"
+)
+
+const (
+	ErrCodeStreamLimit = "stream_limit"
+)
+
+type StreamHandler interface {
+	StreamWeather(c *gin.Context)
+}
+
+func NewStreamHandler(stream service.WeatherStream, heartbeat time.Duration) StreamHandler {
+	return &streamHandler{
+		Stream:    stream,
+		Heartbeat: heartbeat,
+	}
+}
+
+type streamHandler struct {
+	Stream    service.WeatherStream
+	Heartbeat time.Duration
+}
+
+type Heartbeat struct {
+	Time time.Time `json:"time"`
+}
+
+// StreamWeather godoc
+//
+//	@Summary		Stream live weather for a city
+//	@Description	Server-Sent Events stream. A "weather" event carrying WeatherV2 is sent with the current weather and then
+//	@Description	whenever it changes; "heartbeat" events keep idle connections alive.
+//	@Tags			weather
+//	@Produce		text/event-stream
+//	@Param			city	query		string	true	"City name"
+//	@Param			units	query		string	false	"Unit system"	Enums(metric, imperial)	default(metric)
+//	@Param			lang	query		string	false	"Language of the description, e.g. uk"	default(en)
+//	@Success		200		{object}	WeatherV2
+//	@Failure		400		{object}	ErrorResponse	"Invalid request"
+//	@Failure		404		{object}	ErrorResponse	"City not found"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Failure		503		{object}	ErrorResponse	"Too many cities are being streamed"
+//	@Router			/api/weather/stream [get]
+//	@Router			/api/v2/weather/stream [get]
+func (h *streamHandler) StreamWeather(c *gin.Context) {
+	city := c.Query("city")
+	if city == "" {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Missing 'city' query parameter")
+		return
+	}
+
+	prefs, ok := parsePreferences(c.Query("units"), c.Query("lang"))
+	if !ok {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid 'units' or 'lang' query parameter")
+		return
+	}
+
+	// Watch fails before anything is written, so unknown cities get a
+	// proper status code instead of an empty stream.
+	updates, stop, err := h.Stream.Watch(c.Request.Context(), city, prefs)
+	if err != nil {
+		switch {
+		case errors.Is(err, weatherapi.ErrCityNotFound):
+			abortWithError(c, http.StatusNotFound, ErrCodeCityNotFound, "City not found")
+		case errors.Is(err, service.ErrStreamLimit):
+			abortWithError(c, http.StatusServiceUnavailable, ErrCodeStreamLimit, "Too many cities are being streamed, try again later")
+		default:
+			abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to fetch weather")
+		}
+		return
+	}
+	defer stop()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	// The stream outlives the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case weather := <-updates:
+			c.SSEvent(EventWeather, newWeatherV2(weather))
+		case t := <-heartbeat.C:
+			c.SSEvent(EventHeartbeat, Heartbeat{Time: t.UTC()})
+		}
+		return true
+	})
+}
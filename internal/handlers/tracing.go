@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"strings"
+)
+
+// httpTargetKey is the pre-1.21 semantic convention attribute otelgin
+// records by default.
+const httpTargetKey = attribute.Key("http.target")
+
+// tracingMiddleware starts a server span per request, continuing the
+// caller's trace. Health probes are not traced.
+func tracingMiddleware(serviceName string) []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+			return !strings.HasPrefix(r.URL.Path, "/health/")
+		})),
+		redactSpanPath,
+	}
+}
+
+// redactSpanPath replaces the request path recorded on the span with the
+// route pattern: paths carry subscription tokens.
+func redactSpanPath(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	if span.IsRecording() {
+		route := c.FullPath()
+		span.SetAttributes(httpTargetKey.String(route), semconv.URLPath(route))
+	}
+	c.Next()
+}
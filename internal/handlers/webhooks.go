@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"net/http"
+)
+
+const (
+	ErrCodeInvalidWebhookURL         = "invalid_webhook_url"
+	ErrCodeWebhookVerificationFailed = "webhook_verification_failed"
+)
+
+type subscribeWebhookRequest struct {
+	URL       string          `json:"url" binding:"required"`
+	City      string          `json:"city" binding:"required"`
+	Frequency enums.Frequency `json:"frequency" binding:"required,oneof=hourly daily"`
+	Units     string          `json:"units"`
+	Lang      string          `json:"lang"`
+}
+
+// WebhookSubscriptionResponse carries the credentials of a new webhook
+// subscription. They are returned only once.
+type WebhookSubscriptionResponse struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+}
+
+// SubscribeWebhook godoc
+//
+//	@Summary		Subscribe a webhook to weather updates
+//	@Description	Registers an HTTPS endpoint for weather updates. Before the subscription is stored the endpoint receives a signed
+//	@Description	"verification" event and must echo its challenge, either as the response body or as {"challenge": "..."}.
+//	@Description	Deliveries are signed with X-Webhook-Signature: v1=hex(HMAC-SHA256(secret, X-Webhook-Timestamp + "." + body)).
+//	@Description	The token unsubscribes via POST /api/v2/unsubscribe/{token}. Neither the token nor the secret can be retrieved later.
+//	@Tags			subscription
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		subscribeWebhookRequest	true	"Webhook subscription"
+//	@Success		201		{object}	WebhookSubscriptionResponse
+//	@Failure		400		{object}	ErrorResponse	"Invalid input or webhook URL"
+//	@Failure		409		{object}	ErrorResponse	"Webhook already subscribed"
+//	@Failure		422		{object}	ErrorResponse	"Webhook endpoint verification failed"
+//	@Failure		500		{object}	ErrorResponse	"Internal server error"
+//	@Router			/api/v2/webhooks [post]
+func (h *handlerV2) SubscribeWebhook(c *gin.Context) {
+	var req subscribeWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid input")
+		return
+	}
+
+	prefs, ok := parsePreferences(req.Units, req.Lang)
+	if !ok {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidInput, "Invalid input")
+		return
+	}
+
+	sub, err := h.Service.Subscribe(c.Request.Context(), models.Subscription{
+		Channel:   enums.ChannelWebhook,
+		Contact:   req.URL,
+		City:      req.City,
+		Frequency: req.Frequency,
+		Units:     prefs.Units,
+		Lang:      prefs.Lang,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWebhookURL):
+			abortWithError(c, http.StatusBadRequest, ErrCodeInvalidWebhookURL, err.Error())
+		case errors.Is(err, service.ErrWebhookVerificationFailed):
+			abortWithError(c, http.StatusUnprocessableEntity, ErrCodeWebhookVerificationFailed, err.Error())
+		case errors.Is(err, service.ErrAlreadySubscribed):
+			abortWithError(c, http.StatusConflict, ErrCodeAlreadySubscribed, "Webhook already subscribed")
+		default:
+			abortWithError(c, http.StatusInternalServerError, ErrCodeInternal, "Internal server error")
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookSubscriptionResponse{Token: h.Service.IssueToken(sub, enums.TokenPurposeManage), Secret: sub.WebhookSecret})
+}
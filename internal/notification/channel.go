@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+// Channel delivers a rendered message to a subscription contact.
+type Channel interface {
+	Kind() enums.Channel
+	// Send returns the provider's message id when the provider assigns one.
+	Send(ctx context.Context, sub models.Subscription, msg Message) (string, error)
+}
+
+var ErrUnsupportedChannel = errors.New("unsupported notification channel")
+
+// Channels routes messages to the channel of each subscription.
+type Channels map[enums.Channel]Channel
+
+func NewChannels(channels ...Channel) Channels {
+	c := make(Channels, len(channels))
+	for _, ch := range channels {
+		c[ch.Kind()] = ch
+	}
+	return c
+}
+
+func (c Channels) Send(ctx context.Context, sub models.Subscription, msg Message) (string, error) {
+	ch, ok := c[sub.Channel]
+	if !ok {
+		return "", fmt.Errorf("[Channels.Send] %w: %s", ErrUnsupportedChannel, sub.Channel)
+	}
+	return ch.Send(ctx, sub, msg)
+}
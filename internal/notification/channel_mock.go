@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"context"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+type MockChannel struct {
+	KindValue enums.Channel
+	SendFn    func(ctx context.Context, sub models.Subscription, msg Message) (string, error)
+}
+
+func (m *MockChannel) Kind() enums.Channel {
+	return m.KindValue
+}
+
+func (m *MockChannel) Send(ctx context.Context, sub models.Subscription, msg Message) (string, error) {
+	return m.SendFn(ctx, sub, msg)
+}
+
+type MockRenderer struct {
+	RenderFn func(kind Kind, lang enums.Language, data Data) (Message, error)
+}
+
+func (m *MockRenderer) Render(kind Kind, lang enums.Language, data Data) (Message, error) {
+	return m.RenderFn(kind, lang, data)
+}
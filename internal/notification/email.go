@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/email"
+)
+
+var _ Channel = (*EmailChannel)(nil)
+
+// EmailChannel mails the message to the subscription contact. Messages
+// with an unsubscribe link carry List-Unsubscribe and, for one-click
+// unsubscription from the mail client, List-Unsubscribe-Post (RFC 8058).
+type EmailChannel struct {
+	sender email.Sender
+}
+
+func NewEmailChannel(sender email.Sender) *EmailChannel {
+	return &EmailChannel{sender: sender}
+}
+
+func (c *EmailChannel) Kind() enums.Channel {
+	return enums.ChannelEmail
+}
+
+func (c *EmailChannel) Send(ctx context.Context, sub models.Subscription, msg Message) (string, error) {
+	m := email.Mail{
+		To:      sub.Contact,
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		HTML:    msg.HTML,
+	}
+	if msg.UnsubscribeURL != "" {
+		m.Headers = map[string]string{
+			"List-Unsubscribe":      "<" + msg.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+
+	id, err := c.sender.Send(ctx, m)
+	if err != nil {
+		return "", fmt.Errorf("[EmailChannel.Send] %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/email"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailChannel_Send(t *testing.T) {
+	sub := models.Subscription{Channel: enums.ChannelEmail, Contact: "user@example.com", City: "Kyiv"}
+
+	tests := []struct {
+		name        string
+		msg         Message
+		wantHeaders map[string]string
+	}{
+		{
+			name: "update carries list-unsubscribe headers",
+			msg: Message{
+				Kind:           KindHourlyUpdate,
+				Subject:        "Weather in Kyiv",
+				Text:           "Sunny\n",
+				HTML:           "<p>Sunny</p>",
+				UnsubscribeURL: "https://weather.example.com/api/v2/unsubscribe/token",
+			},
+			wantHeaders: map[string]string{
+				"List-Unsubscribe":      "<https://weather.example.com/api/v2/unsubscribe/token>",
+				"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+			},
+		},
+		{
+			name: "message without unsubscribe link",
+			msg:  Message{Kind: KindConfirmation, Subject: "Confirm", Text: "Confirm\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent email.Mail
+			ch := NewEmailChannel(&email.MockSender{
+				SendFn: func(ctx context.Context, m email.Mail) (string, error) {
+					sent = m
+					return "<id@example.com>", nil
+				},
+			})
+
+			id, err := ch.Send(context.Background(), sub, tt.msg)
+			require.NoError(t, err)
+			require.Equal(t, "<id@example.com>", id)
+			require.Equal(t, sub.Contact, sent.To)
+			require.Equal(t, tt.msg.Subject, sent.Subject)
+			require.Equal(t, tt.msg.HTML, sent.HTML)
+			require.Equal(t, tt.wantHeaders, sent.Headers)
+		})
+	}
+}
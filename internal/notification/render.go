@@ -0,0 +1,259 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+type Kind string
+
+const (
+	KindConfirmation Kind = "confirmation"
+	KindHourlyUpdate Kind = "hourly_update"
+	KindDailyDigest  Kind = "daily_digest"
+	KindAlert        Kind = "alert"
+	KindGoodbye      Kind = "goodbye"
+)
+
+var Kinds = []Kind{KindConfirmation, KindHourlyUpdate, KindDailyDigest, KindAlert, KindGoodbye}
+
+// UpdateKind returns the periodic update kind matching the subscription frequency.
+func UpdateKind(f enums.Frequency) Kind {
+	if f == enums.FrequencyHourly {
+		return KindHourlyUpdate
+	}
+	return KindDailyDigest
+}
+
+// Message is a rendered notification. Kind and Weather are carried along
+// for channels that deliver structured data rather than text;
+// UnsubscribeURL for channels that advertise it outside the body.
+type Message struct {
+	Kind           Kind
+	Subject        string
+	Text           string
+	HTML           string
+	Weather        models.Weather
+	UnsubscribeURL string
+}
+
+type Alert struct {
+	Title   string
+	Message string
+}
+
+// Data is passed to every template. Fields irrelevant to a kind are left empty.
+type Data struct {
+	Subscription   models.Subscription
+	Weather        models.Weather
+	Alert          Alert
+	ConfirmURL     string
+	UnsubscribeURL string
+	SubscribeURL   string
+}
+
+type Renderer interface {
+	Render(kind Kind, lang enums.Language, data Data) (Message, error)
+}
+
+var ErrUnknownKind = errors.New("unknown notification kind")
+
+//go:embed templates
+var embedded embed.FS
+
+const (
+	layoutFile     = "layout.tmpl"
+	commonFile     = "common.tmpl"
+	templateSuffix = ".tmpl"
+)
+
+var _ Renderer = (*TemplateRenderer)(nil)
+
+// TemplateRenderer renders messages from per-locale templates. Each
+// templates/<lang>/<kind>.tmpl defines "subject", "text" and "html"; subject
+// and text are executed with text/template, html with html/template.
+// Locales missing a kind fall back to English.
+type TemplateRenderer struct {
+	sets map[enums.Language]map[Kind]templateSet
+}
+
+type templateSet struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// NewTemplateRenderer parses the embedded templates. Files in overrideDir,
+// laid out the same way, replace their embedded counterparts; overrideDir may
+// also add new locales.
+func NewTemplateRenderer(overrideDir string) (*TemplateRenderer, error) {
+	base, err := fs.Sub(embedded, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	var override fs.FS
+	if overrideDir != "" {
+		override = os.DirFS(overrideDir)
+	}
+
+	return newTemplateRenderer(layeredFS{override: override, base: base})
+}
+
+func newTemplateRenderer(fsys layeredFS) (*TemplateRenderer, error) {
+	locales, err := fsys.locales()
+	if err != nil {
+		return nil, fmt.Errorf("[NewTemplateRenderer] list locales: %w", err)
+	}
+
+	r := &TemplateRenderer{sets: make(map[enums.Language]map[Kind]templateSet, len(locales))}
+	for _, lang := range locales {
+		for _, kind := range Kinds {
+			set, ok, err := parseSet(fsys, lang, kind)
+			if err != nil {
+				return nil, fmt.Errorf("[NewTemplateRenderer] %s/%s: %w", lang, kind, err)
+			}
+			if !ok {
+				continue
+			}
+			if r.sets[lang] == nil {
+				r.sets[lang] = make(map[Kind]templateSet, len(Kinds))
+			}
+			r.sets[lang][kind] = set
+		}
+	}
+
+	if len(r.sets[enums.LanguageEnglish]) != len(Kinds) {
+		return nil, errors.New("[NewTemplateRenderer] english templates must define every kind")
+	}
+
+	return r, nil
+}
+
+func parseSet(fsys layeredFS, lang enums.Language, kind Kind) (templateSet, bool, error) {
+	kindSrc, err := fsys.ReadFile(path.Join(lang.String(), string(kind)+templateSuffix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return templateSet{}, false, nil
+	}
+	if err != nil {
+		return templateSet{}, false, err
+	}
+
+	layoutSrc, err := fsys.ReadFile(layoutFile)
+	if err != nil {
+		return templateSet{}, false, err
+	}
+	commonSrc, err := fsys.ReadFile(path.Join(lang.String(), commonFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		commonSrc, err = fsys.ReadFile(path.Join(enums.LanguageEnglish.String(), commonFile))
+	}
+	if err != nil {
+		return templateSet{}, false, err
+	}
+
+	name := string(kind)
+	text := texttemplate.New(name).Funcs(texttemplate.FuncMap(funcs))
+	html := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs))
+	for _, src := range [][]byte{layoutSrc, commonSrc, kindSrc} {
+		if text, err = text.Parse(string(src)); err != nil {
+			return templateSet{}, false, err
+		}
+		if html, err = html.Parse(string(src)); err != nil {
+			return templateSet{}, false, err
+		}
+	}
+
+	for _, block := range []string{"subject", "text", "html"} {
+		if text.Lookup(block) == nil {
+			return templateSet{}, false, fmt.Errorf("missing %q block", block)
+		}
+	}
+
+	return templateSet{text: text, html: html}, true, nil
+}
+
+func (r *TemplateRenderer) Render(kind Kind, lang enums.Language, data Data) (Message, error) {
+	set, ok := r.sets[lang][kind]
+	if !ok {
+		if set, ok = r.sets[enums.LanguageEnglish][kind]; !ok {
+			return Message{}, fmt.Errorf("[TemplateRenderer.Render] %w: %s", ErrUnknownKind, kind)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("[TemplateRenderer.Render] subject: %w", err)
+	}
+	if err := set.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return Message{}, fmt.Errorf("[TemplateRenderer.Render] text: %w", err)
+	}
+	if err := set.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return Message{}, fmt.Errorf("[TemplateRenderer.Render] html: %w", err)
+	}
+
+	return Message{
+		Kind:           kind,
+		Subject:        strings.TrimSpace(subject.String()),
+		Text:           strings.TrimSpace(text.String()) + "\n",
+		HTML:           html.String(),
+		Weather:        data.Weather,
+		UnsubscribeURL: data.UnsubscribeURL,
+	}, nil
+}
+
+var funcs = map[string]any{
+	"temp": func(v float64, u enums.Units) string {
+		return fmt.Sprintf("%.1f%s", v, u.TemperatureSymbol())
+	},
+	"speed": func(v float64, u enums.Units) string {
+		return fmt.Sprintf("%.1f %s", v, u.SpeedSymbol())
+	},
+}
+
+// layeredFS reads from override first and falls back to base.
+type layeredFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (l layeredFS) ReadFile(name string) ([]byte, error) {
+	if l.override != nil {
+		b, err := fs.ReadFile(l.override, name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+	return fs.ReadFile(l.base, name)
+}
+
+func (l layeredFS) locales() ([]enums.Language, error) {
+	seen := make(map[enums.Language]bool)
+	var locales []enums.Language
+	for _, fsys := range []fs.FS{l.base, l.override} {
+		if fsys == nil {
+			continue
+		}
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			lang := enums.Language(e.Name())
+			if e.IsDir() && !seen[lang] {
+				seen[lang] = true
+				locales = append(locales, lang)
+			}
+		}
+	}
+	return locales, nil
+}
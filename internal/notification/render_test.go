@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleData(units enums.Units, lang enums.Language) Data {
+	return Data{
+		Subscription: models.Subscription{
+			Channel:   enums.ChannelEmail,
+			Contact:   "test@example.com",
+			City:      "Kyiv",
+			Frequency: enums.FrequencyDaily,
+			Units:     units,
+			Lang:      lang,
+		},
+		Weather: models.Weather{
+			Temperature: 72.5, FeelsLike: 71.2, Description: "Хмарно", Humidity: 60, WindSpeed: 10,
+			Units: units, Lang: lang,
+		},
+		Alert:          Alert{Title: "Storm", Message: "Strong wind <b>expected</b>"},
+		ConfirmURL:     "https://example.com/confirm/abc",
+		UnsubscribeURL: "https://example.com/unsubscribe/abc",
+		SubscribeURL:   "https://example.com/",
+	}
+}
+
+func TestTemplateRenderer_Render(t *testing.T) {
+	r, err := NewTemplateRenderer("")
+	require.NoError(t, err)
+
+	for _, lang := range []enums.Language{enums.LanguageEnglish, "uk"} {
+		for _, kind := range Kinds {
+			t.Run(lang.String()+"/"+string(kind), func(t *testing.T) {
+				msg, err := r.Render(kind, lang, sampleData(enums.UnitsMetric, lang))
+				require.NoError(t, err)
+				require.NotEmpty(t, msg.Subject)
+				require.NotContains(t, msg.Subject, "\n")
+				require.NotEmpty(t, msg.Text)
+				require.Contains(t, msg.HTML, "<html>")
+			})
+		}
+	}
+
+	t.Run("units and localized description", func(t *testing.T) {
+		msg, err := r.Render(KindHourlyUpdate, "uk", sampleData(enums.UnitsImperial, "uk"))
+		require.NoError(t, err)
+		require.Equal(t, "Погода в місті Kyiv: 72.5°F, Хмарно", msg.Subject)
+		require.Contains(t, msg.Text, "вітер 10.0 mph")
+	})
+
+	t.Run("html is escaped, text is not", func(t *testing.T) {
+		msg, err := r.Render(KindAlert, enums.LanguageEnglish, sampleData(enums.UnitsMetric, enums.LanguageEnglish))
+		require.NoError(t, err)
+		require.Contains(t, msg.HTML, "Strong wind &lt;b&gt;expected&lt;/b&gt;")
+		require.Contains(t, msg.Text, "Strong wind <b>expected</b>")
+	})
+
+	t.Run("unknown locale falls back to english", func(t *testing.T) {
+		msg, err := r.Render(KindGoodbye, "fr", sampleData(enums.UnitsMetric, "fr"))
+		require.NoError(t, err)
+		require.Contains(t, msg.Subject, "You have unsubscribed")
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := r.Render("birthday", enums.LanguageEnglish, Data{})
+		require.ErrorIs(t, err, ErrUnknownKind)
+	})
+}
+
+func TestNewTemplateRenderer_Override(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "en"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "de"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en", "goodbye.tmpl"), []byte(
+		`{{define "subject"}}Custom goodbye{{end}}{{define "text"}}bye{{end}}{{define "html"}}<p>bye</p>{{end}}`,
+	), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de", "goodbye.tmpl"), []byte(
+		`{{define "subject"}}Auf Wiedersehen{{end}}{{define "text"}}tschüss{{end}}{{define "html"}}<p>tschüss</p>{{end}}`,
+	), 0o644))
+
+	r, err := NewTemplateRenderer(dir)
+	require.NoError(t, err)
+
+	msg, err := r.Render(KindGoodbye, enums.LanguageEnglish, Data{})
+	require.NoError(t, err)
+	require.Equal(t, "Custom goodbye", msg.Subject)
+
+	msg, err = r.Render(KindGoodbye, "de", Data{})
+	require.NoError(t, err)
+	require.Equal(t, "Auf Wiedersehen", msg.Subject)
+
+	msg, err = r.Render(KindConfirmation, enums.LanguageEnglish, sampleData(enums.UnitsMetric, enums.LanguageEnglish))
+	require.NoError(t, err)
+	require.Contains(t, msg.Subject, "Confirm your weather subscription")
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/telegram"
+	"strconv"
+)
+
+var _ Channel = (*TelegramChannel)(nil)
+
+// TelegramChannel sends the text part of a message to the chat stored as
+// the subscription contact.
+type TelegramChannel struct {
+	client telegram.BotClient
+}
+
+func NewTelegramChannel(client telegram.BotClient) *TelegramChannel {
+	return &TelegramChannel{client: client}
+}
+
+func (c *TelegramChannel) Kind() enums.Channel {
+	return enums.ChannelTelegram
+}
+
+func (c *TelegramChannel) Send(ctx context.Context, sub models.Subscription, msg Message) (string, error) {
+	chatID, err := strconv.ParseInt(sub.Contact, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("[TelegramChannel.Send] invalid chat id %q: %w", sub.Contact, err)
+	}
+
+	id, err := c.client.SendMessage(ctx, chatID, msg.Subject+"\n\n"+msg.Text)
+	if err != nil {
+		return "", fmt.Errorf("[TelegramChannel.Send] %w", err)
+	}
+
+	return strconv.FormatInt(id, 10), nil
+}
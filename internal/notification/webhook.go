@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/webhook"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+var _ Channel = (*WebhookChannel)(nil)
+
+var ErrWebhookDeliveryFailed = errors.New("webhook delivery failed")
+
+// RetryPolicy controls webhook redelivery. The delay before attempt n+1 is
+// InitialBackoff * 2^(n-1), capped at MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// WebhookChannel posts a signed JSON payload to the URL stored as the
+// subscription contact. Network errors, 429 and 5xx responses are retried;
+// every attempt is recorded in storage.
+type WebhookChannel struct {
+	sender   webhook.Sender
+	attempts storage.WebhookAttemptStorage
+	policy   RetryPolicy
+	logger   *zap.Logger
+}
+
+func NewWebhookChannel(sender webhook.Sender, attempts storage.WebhookAttemptStorage, policy RetryPolicy, logger *zap.Logger) *WebhookChannel {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &WebhookChannel{
+		sender:   sender,
+		attempts: attempts,
+		policy:   policy,
+		logger:   logger,
+	}
+}
+
+// WebhookPayload is the JSON body of every weather delivery.
+type WebhookPayload struct {
+	ID           string                `json:"id"`
+	Event        Kind                  `json:"event"`
+	CreatedAt    time.Time             `json:"created_at"`
+	Subscription WebhookSubscription   `json:"subscription"`
+	Weather      *models.Weather       `json:"weather,omitempty"`
+	Message      WebhookPayloadMessage `json:"message"`
+}
+
+type WebhookSubscription struct {
+	City      string          `json:"city"`
+	Frequency enums.Frequency `json:"frequency"`
+	Units     enums.Units     `json:"units"`
+	Lang      enums.Language  `json:"lang"`
+}
+
+type WebhookPayloadMessage struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+func (c *WebhookChannel) Kind() enums.Channel {
+	return enums.ChannelWebhook
+}
+
+// Send returns the delivery id, which is also sent as the X-Webhook-Id header.
+func (c *WebhookChannel) Send(ctx context.Context, sub models.Subscription, msg Message) (string, error) {
+	payload := WebhookPayload{
+		ID:        webhook.NewID(),
+		Event:     msg.Kind,
+		CreatedAt: time.Now().UTC(),
+		Subscription: WebhookSubscription{
+			City:      sub.City,
+			Frequency: sub.Frequency,
+			Units:     sub.Units,
+			Lang:      sub.Lang,
+		},
+		Message: WebhookPayloadMessage{Subject: msg.Subject, Text: msg.Text},
+	}
+	if msg.Weather.City != "" {
+		payload.Weather = &msg.Weather
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("[WebhookChannel.Send] %w", err)
+	}
+	req := webhook.Request{ID: payload.ID, Event: string(msg.Kind), Body: body}
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		status, err := c.sender.Post(ctx, sub.Contact, sub.WebhookSecret, req)
+		c.logAttempt(ctx, sub, req.ID, attempt, status, err, time.Since(start))
+
+		if err == nil && status >= 200 && status < 300 {
+			return req.ID, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("status %d", status)
+		}
+		if attempt >= c.policy.MaxAttempts || !retryable(status) {
+			return "", fmt.Errorf("[WebhookChannel.Send] %w after %d attempt(s): %v", ErrWebhookDeliveryFailed, attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("[WebhookChannel.Send] %w", ctx.Err())
+		case <-time.After(c.policy.backoff(attempt)):
+		}
+	}
+}
+
+// retryable reports whether a response status is worth retrying. Status 0
+// means no response was received.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+func (c *WebhookChannel) logAttempt(ctx context.Context, sub models.Subscription, deliveryID string, attempt, status int, sendErr error, d time.Duration) {
+	a := models.WebhookAttempt{
+		SubscriptionID: sub.ID,
+		DeliveryID:     deliveryID,
+		Attempt:        attempt,
+		StatusCode:     status,
+		Duration:       d,
+	}
+	if sendErr != nil {
+		a.Error = sendErr.Error()
+	}
+
+	// A detached context keeps the log entry when the send itself was canceled.
+	if err := c.attempts.LogAttempt(context.WithoutCancel(ctx), a); err != nil {
+		c.logger.Warn("webhook attempt log error", zap.Int64("subscription_id", sub.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/webhook"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWebhookChannel_Send(t *testing.T) {
+	sub := models.Subscription{
+		ID:            7,
+		Channel:       enums.ChannelWebhook,
+		Contact:       "https://example.com/hook",
+		City:          "Kyiv",
+		Frequency:     enums.FrequencyHourly,
+		Units:         enums.UnitsMetric,
+		Lang:          enums.LanguageEnglish,
+		WebhookSecret: "secret",
+	}
+	msg := Message{
+		Kind:    KindHourlyUpdate,
+		Subject: "Weather in Kyiv",
+		Text:    "Sunny\n",
+		Weather: models.Weather{City: "Kyiv", Temperature: 20},
+	}
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name         string
+		responses    []int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "delivered", responses: []int{http.StatusOK}, wantAttempts: 1},
+		{name: "retried until success", responses: []int{http.StatusServiceUnavailable, 0, http.StatusNoContent}, wantAttempts: 3},
+		{name: "rate limited is retried", responses: []int{http.StatusTooManyRequests, http.StatusOK}, wantAttempts: 2},
+		{name: "client error is permanent", responses: []int{http.StatusGone}, wantErr: true, wantAttempts: 1},
+		{name: "gives up after max attempts", responses: []int{500, 502, 503, 200}, wantErr: true, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				requests []webhook.Request
+				logged   []models.WebhookAttempt
+			)
+			sender := &webhook.MockSender{
+				PostFn: func(ctx context.Context, url, secret string, req webhook.Request) (int, error) {
+					require.Equal(t, sub.Contact, url)
+					require.Equal(t, sub.WebhookSecret, secret)
+					requests = append(requests, req)
+					status := tt.responses[len(requests)-1]
+					if status == 0 {
+						return 0, errors.New("connection refused")
+					}
+					return status, nil
+				},
+			}
+			attempts := &storage.MockWebhookAttemptStorage{
+				LogAttemptFn: func(ctx context.Context, a models.WebhookAttempt) error {
+					logged = append(logged, a)
+					return nil
+				},
+			}
+
+			id, err := NewWebhookChannel(sender, attempts, policy, zap.NewNop()).Send(context.Background(), sub, msg)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrWebhookDeliveryFailed)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, requests[0].ID, id)
+			}
+
+			require.Len(t, requests, tt.wantAttempts)
+			require.Len(t, logged, tt.wantAttempts)
+			for i, a := range logged {
+				require.Equal(t, int64(7), a.SubscriptionID)
+				require.Equal(t, i+1, a.Attempt)
+				require.Equal(t, requests[0].ID, a.DeliveryID)
+				require.Equal(t, requests[0].ID, requests[i].ID)
+				require.Equal(t, tt.responses[i], a.StatusCode)
+				require.Equal(t, tt.responses[i] == 0, a.Error != "")
+			}
+
+			var payload WebhookPayload
+			require.NoError(t, json.Unmarshal(requests[0].Body, &payload))
+			require.Equal(t, KindHourlyUpdate, payload.Event)
+			require.Equal(t, string(KindHourlyUpdate), requests[0].Event)
+			require.Equal(t, "Kyiv", payload.Subscription.City)
+			require.NotNil(t, payload.Weather)
+			require.Equal(t, 20.0, payload.Weather.Temperature)
+			require.Equal(t, "Weather in Kyiv", payload.Message.Subject)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	require.Equal(t, time.Second, p.backoff(1))
+	require.Equal(t, 2*time.Second, p.backoff(2))
+	require.Equal(t, 4*time.Second, p.backoff(3))
+	require.Equal(t, 5*time.Second, p.backoff(4))
+	require.Equal(t, 5*time.Second, p.backoff(30))
+}
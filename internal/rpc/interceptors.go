@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	weatherv1 "github.com/serjnester/weather-subscription-service/pkg/pb/weather/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Metadata keys read by the interceptors, matching the X-API-Key,
+// X-Request-ID and Authorization HTTP headers.
+const (
+	APIKeyMetadata        = "x-api-key"
+	RequestIDMetadata     = "x-request-id"
+	AuthorizationMetadata = "authorization"
+
+	// maxRequestIDLength bounds request ids accepted from clients.
+	maxRequestIDLength = 128
+)
+
+// requestContext assigns the request id, taken from x-request-id metadata
+// when the client sent a usable one, returns it in the response header,
+// puts an annotated logger into the context and logs every call.
+func requestContext(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := firstMetadata(ctx, RequestIDMetadata)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+
+		log := logger.With(zap.String("request_id", id))
+		ctx = logging.WithContext(ctx, log)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		fields := []zap.Field{
+			zap.String("method", info.FullMethod),
+			zap.String("code", code.String()),
+			zap.Duration("latency", time.Since(start)),
+		}
+		switch code {
+		case codes.OK:
+			log.Info("grpc request", fields...)
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			log.Error("grpc request", fields...)
+		default:
+			log.Warn("grpc request", fields...)
+		}
+		return resp, err
+	}
+}
+
+// recovery turns a panic in a handler into a logged Internal error.
+func recovery(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			logging.FromContext(ctx).Error("panic recovered",
+				zap.String("panic", fmt.Sprint(rec)),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			resp, err = nil, status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// adminMethods create, reveal or end the subscriptions of any contact, so
+// a partner API key, issued for weather access, is not enough for them.
+var adminMethods = map[string]bool{
+	weatherv1.WeatherService_Subscribe_FullMethodName:           true,
+	weatherv1.WeatherService_ConfirmSubscription_FullMethodName: true,
+	weatherv1.WeatherService_Unsubscribe_FullMethodName:         true,
+	weatherv1.WeatherService_ListSubscriptions_FullMethodName:   true,
+}
+
+// adminAuth requires "authorization: Bearer <token>" metadata on
+// adminMethods. They are refused when no admin token is configured.
+func adminAuth(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !adminMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if token == "" {
+			return nil, status.Error(codes.PermissionDenied, "subscription calls are disabled")
+		}
+
+		got, ok := strings.CutPrefix(firstMetadata(ctx, AuthorizationMetadata), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// apiKeyAuth requires a valid API key in x-api-key metadata on calls other
+// than adminMethods and meters it against the key's daily quota, like the
+// HTTP API.
+func apiKeyAuth(keys service.APIKeyService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if adminMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		plain := firstMetadata(ctx, APIKeyMetadata)
+		if plain == "" {
+			return nil, status.Error(codes.Unauthenticated, "API key required")
+		}
+
+		if _, err := keys.AuthenticateAPIKey(ctx, plain); err != nil {
+			switch {
+			case errors.Is(err, service.ErrAPIKeyQuotaExceeded):
+				return nil, status.Error(codes.ResourceExhausted, "API key daily quota exceeded")
+			case errors.Is(err, service.ErrAPIKeyInvalid), errors.Is(err, service.ErrAPIKeyRevoked):
+				return nil, status.Error(codes.Unauthenticated, "invalid API key")
+			default:
+				logging.FromContext(ctx).Error("api key authentication failed", zap.Error(err))
+				return nil, status.Error(codes.Internal, "internal error")
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// validRequestID accepts printable ASCII without spaces, so a client id
+// cannot break log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,248 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	weatherv1 "github.com/serjnester/weather-subscription-service/pkg/pb/weather/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ weatherv1.WeatherServiceServer = (*Server)(nil)
+
+// Server exposes service.Service over gRPC.
+type Server struct {
+	weatherv1.UnimplementedWeatherServiceServer
+
+	service service.Service
+}
+
+func NewServer(svc service.Service) *Server {
+	return &Server{service: svc}
+}
+
+// NewGRPCServer returns a grpc.Server with the weather service registered.
+// GetWeather needs an API key in x-api-key metadata; the subscription calls
+// need adminToken as a bearer token and are disabled without one. Every
+// call is logged and has panics recovered. Reflection is enabled outside
+// prod for tools like grpcurl.
+func NewGRPCServer(svc service.Service, keys service.APIKeyService, adminToken string, logger *zap.Logger, reflect bool, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(requestContext(logger), recovery, adminAuth(adminToken), apiKeyAuth(keys)))
+	s := grpc.NewServer(opts...)
+	weatherv1.RegisterWeatherServiceServer(s, NewServer(svc))
+	if reflect {
+		reflection.Register(s)
+	}
+	return s
+}
+
+func (s *Server) GetWeather(ctx context.Context, req *weatherv1.GetWeatherRequest) (*weatherv1.GetWeatherResponse, error) {
+	if req.GetCity() == "" {
+		return nil, status.Error(codes.InvalidArgument, "city is required")
+	}
+	prefs, err := preferencesFromPB(req.GetPreferences())
+	if err != nil {
+		return nil, err
+	}
+
+	weather, err := s.service.WeatherForecast(ctx, req.GetCity(), prefs)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &weatherv1.GetWeatherResponse{Weather: weatherToPB(weather)}, nil
+}
+
+func (s *Server) Subscribe(ctx context.Context, req *weatherv1.SubscribeRequest) (*weatherv1.SubscribeResponse, error) {
+	channel, ok := channelFromPB[req.GetChannel()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid channel")
+	}
+	frequency, ok := frequencyFromPB[req.GetFrequency()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "frequency is required")
+	}
+	if req.GetContact() == "" || req.GetCity() == "" {
+		return nil, status.Error(codes.InvalidArgument, "contact and city are required")
+	}
+	prefs, err := preferencesFromPB(req.GetPreferences())
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.service.Subscribe(ctx, models.Subscription{
+		Channel:   channel,
+		Contact:   req.GetContact(),
+		City:      req.GetCity(),
+		Frequency: frequency,
+		Units:     prefs.Units,
+		Lang:      prefs.Lang,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &weatherv1.SubscribeResponse{Confirmed: sub.Confirmed}
+	if sub.Channel == enums.ChannelWebhook {
+		resp.Token, resp.WebhookSecret = s.service.IssueToken(sub, enums.TokenPurposeManage), sub.WebhookSecret
+	}
+	return resp, nil
+}
+
+func (s *Server) ConfirmSubscription(ctx context.Context, req *weatherv1.ConfirmSubscriptionRequest) (*weatherv1.ConfirmSubscriptionResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	if err := s.service.Confirm(ctx, req.GetToken()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &weatherv1.ConfirmSubscriptionResponse{}, nil
+}
+
+func (s *Server) Unsubscribe(ctx context.Context, req *weatherv1.UnsubscribeRequest) (*weatherv1.UnsubscribeResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	if err := s.service.Unsubscribe(ctx, req.GetToken()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &weatherv1.UnsubscribeResponse{}, nil
+}
+
+func (s *Server) ListSubscriptions(ctx context.Context, req *weatherv1.ListSubscriptionsRequest) (*weatherv1.ListSubscriptionsResponse, error) {
+	channel, ok := channelFromPB[req.GetChannel()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid channel")
+	}
+	if req.GetContact() == "" {
+		return nil, status.Error(codes.InvalidArgument, "contact is required")
+	}
+
+	subs, err := s.service.SubscriptionsByContact(ctx, channel, req.GetContact())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &weatherv1.ListSubscriptionsResponse{Subscriptions: make([]*weatherv1.Subscription, 0, len(subs))}
+	for _, sub := range subs {
+		pb := &weatherv1.Subscription{
+			Channel:   channelToPB[sub.Channel],
+			Contact:   sub.Contact,
+			City:      sub.City,
+			Frequency: frequencyToPB[sub.Frequency],
+			Preferences: &weatherv1.Preferences{
+				Units: unitsToPB[sub.Units],
+				Lang:  sub.Lang.String(),
+			},
+			Confirmed: sub.Confirmed,
+		}
+		if sub.LastSentAt != nil {
+			pb.LastSentAt = timestamppb.New(*sub.LastSentAt)
+		}
+		resp.Subscriptions = append(resp.Subscriptions, pb)
+	}
+	return resp, nil
+}
+
+// toStatus maps service errors onto gRPC status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, service.ErrAlreadySubscribed):
+		return status.Error(codes.AlreadyExists, "already subscribed")
+	case errors.Is(err, service.ErrTokenNotFound):
+		return status.Error(codes.NotFound, "token not found")
+	case errors.Is(err, weatherapi.ErrCityNotFound):
+		return status.Error(codes.NotFound, "city not found")
+	case errors.Is(err, service.ErrInvalidWebhookURL):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrWebhookVerificationFailed), errors.Is(err, service.ErrEmailSuppressed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func preferencesFromPB(p *weatherv1.Preferences) (models.Preferences, error) {
+	prefs := models.DefaultPreferences()
+	if p == nil {
+		return prefs, nil
+	}
+	if p.GetUnits() != weatherv1.Units_UNITS_UNSPECIFIED {
+		units, ok := unitsFromPB[p.GetUnits()]
+		if !ok {
+			return models.Preferences{}, status.Error(codes.InvalidArgument, "invalid units")
+		}
+		prefs.Units = units
+	}
+	if p.GetLang() != "" {
+		lang, ok := enums.ParseLanguage(p.GetLang())
+		if !ok {
+			return models.Preferences{}, status.Error(codes.InvalidArgument, "invalid lang")
+		}
+		prefs.Lang = lang
+	}
+	return prefs, nil
+}
+
+func weatherToPB(w models.Weather) *weatherv1.Weather {
+	pb := &weatherv1.Weather{
+		City:        w.City,
+		Country:     w.Country,
+		Temperature: w.Temperature,
+		FeelsLike:   w.FeelsLike,
+		Description: w.Description,
+		Humidity:    int32(w.Humidity),
+		WindSpeed:   w.WindSpeed,
+		Units:       unitsToPB[w.Units],
+		Lang:        w.Lang.String(),
+	}
+	if !w.UpdatedAt.IsZero() {
+		pb.UpdatedAt = timestamppb.New(w.UpdatedAt)
+	}
+	return pb
+}
+
+var (
+	unitsFromPB = map[weatherv1.Units]enums.Units{
+		weatherv1.Units_UNITS_METRIC:   enums.UnitsMetric,
+		weatherv1.Units_UNITS_IMPERIAL: enums.UnitsImperial,
+	}
+	unitsToPB = invert(unitsFromPB)
+
+	frequencyFromPB = map[weatherv1.Frequency]enums.Frequency{
+		weatherv1.Frequency_FREQUENCY_HOURLY: enums.FrequencyHourly,
+		weatherv1.Frequency_FREQUENCY_DAILY:  enums.FrequencyDaily,
+	}
+	frequencyToPB = invert(frequencyFromPB)
+
+	channelToPB = map[enums.Channel]weatherv1.Channel{
+		enums.ChannelEmail:    weatherv1.Channel_CHANNEL_EMAIL,
+		enums.ChannelTelegram: weatherv1.Channel_CHANNEL_TELEGRAM,
+		enums.ChannelWebhook:  weatherv1.Channel_CHANNEL_WEBHOOK,
+	}
+	channelFromPB = func() map[weatherv1.Channel]enums.Channel {
+		m := invert(channelToPB)
+		m[weatherv1.Channel_CHANNEL_UNSPECIFIED] = enums.ChannelEmail
+		return m
+	}()
+)
+
+func invert[K, V comparable](m map[K]V) map[V]K {
+	out := make(map[V]K, len(m))
+	for k, v := range m {
+		out[v] = k
+	}
+	return out
+}
@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/service"
+	weatherv1 "github.com/serjnester/weather-subscription-service/pkg/pb/weather/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testKeys accepts the API key "valid", rejects "exhausted" as over quota
+// and any other key as invalid.
+var testKeys = &service.MockAPIKeyService{
+	AuthenticateAPIKeyFn: func(ctx context.Context, key string) (models.APIKey, error) {
+		switch key {
+		case "valid":
+			return models.APIKey{ID: 1}, nil
+		case "exhausted":
+			return models.APIKey{ID: 2}, service.ErrAPIKeyQuotaExceeded
+		default:
+			return models.APIKey{}, service.ErrAPIKeyInvalid
+		}
+	},
+}
+
+func withKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, APIKeyMetadata, key)
+}
+
+const testAdminToken = "admin-token"
+
+func asAdmin(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AuthorizationMetadata, "Bearer "+testAdminToken)
+}
+
+func newTestClient(t *testing.T, svc service.Service) weatherv1.WeatherServiceClient {
+	return newTestClientWithAdmin(t, svc, testAdminToken)
+}
+
+func newTestClientWithAdmin(t *testing.T, svc service.Service, adminToken string) weatherv1.WeatherServiceClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(svc, testKeys, adminToken, zap.NewNop(), false)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return weatherv1.NewWeatherServiceClient(conn)
+}
+
+func TestServer_GetWeather(t *testing.T) {
+	client := newTestClient(t, &service.MockService{
+		WeatherForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			if city == "Nowhere" {
+				return models.Weather{}, weatherapi.ErrCityNotFound
+			}
+			return models.Weather{City: city, Temperature: 72.5, Units: prefs.Units, Lang: prefs.Lang}, nil
+		},
+	})
+	ctx := withKey(context.Background(), "valid")
+
+	resp, err := client.GetWeather(ctx, &weatherv1.GetWeatherRequest{
+		City:        "Kyiv",
+		Preferences: &weatherv1.Preferences{Units: weatherv1.Units_UNITS_IMPERIAL, Lang: "uk"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Kyiv", resp.GetWeather().GetCity())
+	require.Equal(t, 72.5, resp.GetWeather().GetTemperature())
+	require.Equal(t, weatherv1.Units_UNITS_IMPERIAL, resp.GetWeather().GetUnits())
+	require.Equal(t, "uk", resp.GetWeather().GetLang())
+
+	_, err = client.GetWeather(ctx, &weatherv1.GetWeatherRequest{City: "Nowhere"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetWeather(ctx, &weatherv1.GetWeatherRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetWeather(ctx, &weatherv1.GetWeatherRequest{City: "Kyiv", Preferences: &weatherv1.Preferences{Lang: "xx"}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Subscriptions(t *testing.T) {
+	var created models.Subscription
+	client := newTestClient(t, &service.MockService{
+		SubscribeFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+			if sub.Contact == "taken@example.com" {
+				return models.Subscription{}, service.ErrAlreadySubscribed
+			}
+			created = sub
+			sub.Token, sub.WebhookSecret = "token", "secret"
+			sub.Confirmed = sub.Channel != enums.ChannelEmail
+			return sub, nil
+		},
+		ConfirmFn: func(ctx context.Context, token string) error {
+			if token != "token" {
+				return service.ErrTokenNotFound
+			}
+			return nil
+		},
+		UnsubscribeFn: func(ctx context.Context, token string) error {
+			return service.ErrTokenNotFound
+		},
+		IssueTokenFn: func(sub models.Subscription, purpose enums.TokenPurpose) string {
+			require.Equal(t, enums.TokenPurposeManage, purpose)
+			return "manage-" + sub.Token
+		},
+		SubscriptionsByContactFn: func(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+			require.Equal(t, enums.ChannelEmail, channel)
+			return []models.Subscription{{
+				Channel: channel, Contact: contact, City: "Kyiv", Frequency: enums.FrequencyDaily,
+				Units: enums.UnitsMetric, Lang: enums.LanguageEnglish, Confirmed: true,
+			}}, nil
+		},
+	})
+	ctx := asAdmin(context.Background())
+
+	t.Run("email subscription does not expose the token", func(t *testing.T) {
+		resp, err := client.Subscribe(ctx, &weatherv1.SubscribeRequest{
+			Contact:   "test@example.com",
+			City:      "Kyiv",
+			Frequency: weatherv1.Frequency_FREQUENCY_HOURLY,
+		})
+		require.NoError(t, err)
+		require.False(t, resp.GetConfirmed())
+		require.Empty(t, resp.GetToken())
+		require.Equal(t, enums.ChannelEmail, created.Channel)
+		require.Equal(t, enums.FrequencyHourly, created.Frequency)
+		require.Equal(t, models.DefaultPreferences(), created.Preferences())
+	})
+
+	t.Run("webhook subscription returns credentials", func(t *testing.T) {
+		resp, err := client.Subscribe(ctx, &weatherv1.SubscribeRequest{
+			Channel:   weatherv1.Channel_CHANNEL_WEBHOOK,
+			Contact:   "https://example.com/hook",
+			City:      "Kyiv",
+			Frequency: weatherv1.Frequency_FREQUENCY_DAILY,
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetConfirmed())
+		require.Equal(t, "manage-token", resp.GetToken())
+		require.Equal(t, "secret", resp.GetWebhookSecret())
+	})
+
+	t.Run("errors map to status codes", func(t *testing.T) {
+		_, err := client.Subscribe(ctx, &weatherv1.SubscribeRequest{
+			Contact: "taken@example.com", City: "Kyiv", Frequency: weatherv1.Frequency_FREQUENCY_DAILY,
+		})
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+		_, err = client.Subscribe(ctx, &weatherv1.SubscribeRequest{Contact: "test@example.com", City: "Kyiv"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.ConfirmSubscription(ctx, &weatherv1.ConfirmSubscriptionRequest{Token: "unknown"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.Unsubscribe(ctx, &weatherv1.UnsubscribeRequest{Token: "unknown"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("confirm and list", func(t *testing.T) {
+		_, err := client.ConfirmSubscription(ctx, &weatherv1.ConfirmSubscriptionRequest{Token: "token"})
+		require.NoError(t, err)
+
+		resp, err := client.ListSubscriptions(ctx, &weatherv1.ListSubscriptionsRequest{Contact: "test@example.com"})
+		require.NoError(t, err)
+		require.Len(t, resp.GetSubscriptions(), 1)
+		sub := resp.GetSubscriptions()[0]
+		require.Equal(t, weatherv1.Channel_CHANNEL_EMAIL, sub.GetChannel())
+		require.Equal(t, weatherv1.Frequency_FREQUENCY_DAILY, sub.GetFrequency())
+		require.Equal(t, weatherv1.Units_UNITS_METRIC, sub.GetPreferences().GetUnits())
+		require.True(t, sub.GetConfirmed())
+	})
+}
+
+func TestServer_Interceptors(t *testing.T) {
+	client := newTestClient(t, &service.MockService{
+		WeatherForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			return models.Weather{City: city}, nil
+		},
+	})
+	req := &weatherv1.GetWeatherRequest{City: "Kyiv"}
+
+	t.Run("api key", func(t *testing.T) {
+		for _, tt := range []struct {
+			name string
+			ctx  context.Context
+			want codes.Code
+		}{
+			{name: "missing", ctx: context.Background(), want: codes.Unauthenticated},
+			{name: "invalid", ctx: withKey(context.Background(), "nope"), want: codes.Unauthenticated},
+			{name: "quota exceeded", ctx: withKey(context.Background(), "exhausted"), want: codes.ResourceExhausted},
+			{name: "valid", ctx: withKey(context.Background(), "valid"), want: codes.OK},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := client.GetWeather(tt.ctx, req)
+				require.Equal(t, tt.want, status.Code(err))
+			})
+		}
+
+	})
+
+	t.Run("subscription calls need the admin token", func(t *testing.T) {
+		list := &weatherv1.ListSubscriptionsRequest{Contact: "test@example.com"}
+
+		_, err := client.ListSubscriptions(context.Background(), list)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = client.ListSubscriptions(withKey(context.Background(), "valid"), list)
+		require.Equal(t, codes.Unauthenticated, status.Code(err), "a partner key is not enough")
+
+		_, err = client.Subscribe(withKey(context.Background(), "valid"), &weatherv1.SubscribeRequest{
+			Channel: weatherv1.Channel_CHANNEL_WEBHOOK, Contact: "https://example.com/hook", City: "Kyiv",
+			Frequency: weatherv1.Frequency_FREQUENCY_DAILY,
+		})
+		require.Equal(t, codes.Unauthenticated, status.Code(err), "no manage token or webhook secret for partners")
+
+		disabled := newTestClientWithAdmin(t, &service.MockService{}, "")
+		_, err = disabled.ListSubscriptions(asAdmin(context.Background()), list)
+		require.Equal(t, codes.PermissionDenied, status.Code(err), "disabled without an admin token")
+	})
+
+	t.Run("request id", func(t *testing.T) {
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(withKey(context.Background(), "valid"), RequestIDMetadata, "abc-123")
+		_, err := client.GetWeather(ctx, req, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc-123"}, header.Get(RequestIDMetadata))
+
+		_, err = client.GetWeather(withKey(context.Background(), "valid"), req, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Len(t, header.Get(RequestIDMetadata)[0], 32, "generated when missing")
+	})
+
+	t.Run("panics become internal errors", func(t *testing.T) {
+		// SubscriptionsByContactFn is not set, so the mock panics.
+		_, err := client.ListSubscriptions(asAdmin(context.Background()), &weatherv1.ListSubscriptionsRequest{Contact: "test@example.com"})
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/dispatcher"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// Scheduler periodically claims due subscriptions and dispatches their
+// updates. Any number of replicas may run it against the same database:
+// claims are disjoint, and a batch held by a crashed replica is picked up
+// by another one once its lease expires.
+type Scheduler struct {
+	storage    storage.ScheduleStorage
+	dispatcher dispatcher.Dispatcher
+	logger     *zap.Logger
+	cfg        configs.Scheduler
+	owner      string
+	now        func() time.Time
+}
+
+func NewScheduler(s storage.ScheduleStorage, d dispatcher.Dispatcher, logger *zap.Logger, cfg configs.Scheduler) *Scheduler {
+	owner := newOwnerID()
+	return &Scheduler{
+		storage:    s,
+		dispatcher: d,
+		logger:     logger.With(zap.String("scheduler_owner", owner)),
+		cfg:        cfg,
+		owner:      owner,
+		now:        time.Now,
+	}
+}
+
+// Run processes due subscriptions every cfg.Interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("scheduler cycle error", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and dispatches batches until nothing is due and returns
+// the number of dispatched subscriptions. All batches share one
+// dispatcher.Cycle, so each city's weather is fetched once per call.
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	cycle := dispatcher.NewCycle()
+	total, err := s.runCycle(ctx, cycle)
+	if total > 0 {
+		stats := cycle.Stats()
+		s.logger.Info("dispatch cycle finished",
+			zap.Int("subscriptions", stats.Subscriptions),
+			zap.Int("cities", stats.Cities),
+			zap.Int("weather_errors", stats.WeatherErrors),
+			zap.Int("sent", stats.Sent),
+			zap.Int("failed", stats.Failed),
+			zap.Int("suppressed", stats.Suppressed),
+			zap.Duration("duration", stats.Duration),
+		)
+	}
+	return total, err
+}
+
+func (s *Scheduler) runCycle(ctx context.Context, cycle *dispatcher.Cycle) (int, error) {
+	var total int
+	for ctx.Err() == nil {
+		subs, err := s.storage.ClaimDue(ctx, s.owner, s.cfg.BatchSize, s.cfg.Lease)
+		if err != nil {
+			return total, fmt.Errorf("[Scheduler.RunOnce] claim: %w", err)
+		}
+		if len(subs) == 0 {
+			return total, nil
+		}
+
+		results := s.dispatcher.Dispatch(ctx, cycle, subs)
+		total += len(results)
+		if err := s.finish(ctx, results); err != nil {
+			return total, err
+		}
+
+		if len(subs) < s.cfg.BatchSize {
+			return total, nil
+		}
+	}
+	return total, ctx.Err()
+}
+
+// finish reschedules dispatched subscriptions. Failed deliveries are not
+// retried: a late weather update is worse than a skipped one. Deliveries
+// interrupted by shutdown are released for another worker.
+func (s *Scheduler) finish(ctx context.Context, results []dispatcher.Result) error {
+	// Bookkeeping must outlive a shutdown that interrupted the batch.
+	dbCtx := context.WithoutCancel(ctx)
+	now := s.now()
+
+	var released []int64
+	for _, r := range results {
+		sub := r.Subscription
+		if r.Err != nil && ctx.Err() != nil && errors.Is(r.Err, ctx.Err()) {
+			released = append(released, sub.ID)
+			continue
+		}
+		if r.Err != nil && !errors.Is(r.Err, dispatcher.ErrSuppressed) {
+			s.logger.Warn("delivery failed",
+				zap.Int64("subscription_id", sub.ID),
+				zap.String("channel", sub.Channel.String()),
+				zap.Error(r.Err),
+			)
+		}
+
+		err := s.storage.Complete(dbCtx, s.owner, sub.ID, NextRun(sub.Frequency, now, s.cfg.DailyHour))
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.Warn("subscription lease lost", zap.Int64("subscription_id", sub.ID))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("[Scheduler.finish] complete: %w", err)
+		}
+	}
+
+	if len(released) > 0 {
+		if err := s.storage.Release(dbCtx, s.owner, released); err != nil {
+			return fmt.Errorf("[Scheduler.finish] release: %w", err)
+		}
+	}
+	return nil
+}
+
+// NextRun returns the next slot after now: the top of the next hour for
+// hourly subscriptions, the next dailyHour:00 UTC for daily ones.
+func NextRun(f enums.Frequency, now time.Time, dailyHour int) time.Time {
+	now = now.UTC()
+	if f == enums.FrequencyHourly {
+		return now.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), dailyHour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func newOwnerID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/dispatcher"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2025, 5, 20, 10, 42, 13, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		frequency enums.Frequency
+		now       time.Time
+		want      time.Time
+	}{
+		{
+			name:      "hourly",
+			frequency: enums.FrequencyHourly,
+			now:       now,
+			want:      time.Date(2025, 5, 20, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "hourly on the hour",
+			frequency: enums.FrequencyHourly,
+			now:       time.Date(2025, 5, 20, 11, 0, 0, 0, time.UTC),
+			want:      time.Date(2025, 5, 20, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "daily before the hour",
+			frequency: enums.FrequencyDaily,
+			now:       time.Date(2025, 5, 20, 7, 59, 0, 0, time.UTC),
+			want:      time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "daily after the hour",
+			frequency: enums.FrequencyDaily,
+			now:       now,
+			want:      time.Date(2025, 5, 21, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "daily in another zone",
+			frequency: enums.FrequencyDaily,
+			now:       time.Date(2025, 5, 20, 9, 0, 0, 0, time.FixedZone("EEST", 3*3600)),
+			want:      time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NextRun(tt.frequency, tt.now, 8))
+		})
+	}
+}
+
+func newTestScheduler(s storage.ScheduleStorage, d dispatcher.Dispatcher, batchSize int) *Scheduler {
+	sch := NewScheduler(s, d, zap.NewNop(), configs.Scheduler{BatchSize: batchSize, Lease: time.Minute, DailyHour: 8})
+	sch.now = func() time.Time { return time.Date(2025, 5, 20, 10, 0, 0, 0, time.UTC) }
+	return sch
+}
+
+func dispatchAll(err error) *dispatcher.MockDispatcher {
+	return &dispatcher.MockDispatcher{
+		DispatchFn: func(ctx context.Context, cycle *dispatcher.Cycle, subs []models.Subscription) []dispatcher.Result {
+			results := make([]dispatcher.Result, 0, len(subs))
+			for _, sub := range subs {
+				results = append(results, dispatcher.Result{Subscription: sub, Err: err})
+			}
+			return results
+		},
+	}
+}
+
+func TestScheduler_RunOnce(t *testing.T) {
+	t.Run("claims batches until nothing is due", func(t *testing.T) {
+		batches := [][]models.Subscription{
+			{{ID: 1, Frequency: enums.FrequencyHourly}, {ID: 2, Frequency: enums.FrequencyDaily}},
+			{{ID: 3, Frequency: enums.FrequencyHourly}, {ID: 4, Frequency: enums.FrequencyHourly}},
+			{},
+		}
+		var claims int
+		completed := map[int64]time.Time{}
+		store := &storage.MockScheduleStorage{
+			ClaimDueFn: func(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+				require.Equal(t, 2, limit)
+				require.Equal(t, time.Minute, lease)
+				batch := batches[claims]
+				claims++
+				return batch, nil
+			},
+			CompleteFn: func(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+				completed[id] = nextRunAt
+				return nil
+			},
+		}
+
+		n, err := newTestScheduler(store, dispatchAll(nil), 2).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 4, n)
+		require.Equal(t, 3, claims)
+		require.Len(t, completed, 4)
+		require.Equal(t, time.Date(2025, 5, 20, 11, 0, 0, 0, time.UTC), completed[1])
+		require.Equal(t, time.Date(2025, 5, 21, 8, 0, 0, 0, time.UTC), completed[2])
+	})
+
+	t.Run("failed deliveries are rescheduled", func(t *testing.T) {
+		var completed []int64
+		store := &storage.MockScheduleStorage{
+			ClaimDueFn: func(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+				return []models.Subscription{{ID: 1}}, nil
+			},
+			CompleteFn: func(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+				completed = append(completed, id)
+				return nil
+			},
+		}
+
+		n, err := newTestScheduler(store, dispatchAll(errors.New("smtp down")), 10).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []int64{1}, completed)
+	})
+
+	t.Run("lost lease is skipped", func(t *testing.T) {
+		store := &storage.MockScheduleStorage{
+			ClaimDueFn: func(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+				return []models.Subscription{{ID: 1}, {ID: 2}}, nil
+			},
+			CompleteFn: func(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+				if id == 1 {
+					return sql.ErrNoRows
+				}
+				return nil
+			},
+		}
+
+		n, err := newTestScheduler(store, dispatchAll(nil), 10).RunOnce(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+	})
+
+	t.Run("interrupted deliveries are released", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var completed, released []int64
+		store := &storage.MockScheduleStorage{
+			ClaimDueFn: func(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+				return []models.Subscription{{ID: 1}, {ID: 2}}, nil
+			},
+			CompleteFn: func(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+				require.NoError(t, ctx.Err())
+				completed = append(completed, id)
+				return nil
+			},
+			ReleaseFn: func(ctx context.Context, owner string, ids []int64) error {
+				require.NoError(t, ctx.Err())
+				released = append(released, ids...)
+				return nil
+			},
+		}
+		d := &dispatcher.MockDispatcher{
+			DispatchFn: func(ctx context.Context, cycle *dispatcher.Cycle, subs []models.Subscription) []dispatcher.Result {
+				cancel()
+				return []dispatcher.Result{
+					{Subscription: subs[0]},
+					{Subscription: subs[1], Err: ctx.Err()},
+				}
+			},
+		}
+
+		_, err := newTestScheduler(store, d, 2).RunOnce(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, []int64{1}, completed)
+		require.Equal(t, []int64{2}, released)
+	})
+
+	t.Run("claim error", func(t *testing.T) {
+		store := &storage.MockScheduleStorage{
+			ClaimDueFn: func(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+				return nil, errors.New("db down")
+			},
+		}
+
+		_, err := newTestScheduler(store, dispatchAll(nil), 10).RunOnce(context.Background())
+		require.Error(t, err)
+	})
+}
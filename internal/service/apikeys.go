@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+)
+
+type APIKeyService interface {
+	// CreateAPIKey returns the stored key and its plaintext value. The plaintext
+	// is never persisted and cannot be recovered later.
+	CreateAPIKey(ctx context.Context, name string, dailyQuota int) (models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	// AuthenticateAPIKey validates the key and counts the request against its quota.
+	AuthenticateAPIKey(ctx context.Context, key string) (models.APIKey, error)
+}
+
+var _ APIKeyService = (*APIKeyImpl)(nil)
+
+type APIKeyImpl struct {
+	storage storage.APIKeyStorage
+}
+
+func NewAPIKeyService(s storage.APIKeyStorage) *APIKeyImpl {
+	return &APIKeyImpl{storage: s}
+}
+
+var (
+	ErrAPIKeyInvalid       = errors.New("invalid api key")
+	ErrAPIKeyRevoked       = errors.New("api key revoked")
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrAPIKeyQuotaExceeded = errors.New("api key daily quota exceeded")
+)
+
+const (
+	apiKeyPrefix       = "wss"
+	apiKeyPrefixLength = 8
+)
+
+func (s *APIKeyImpl) CreateAPIKey(ctx context.Context, name string, dailyQuota int) (models.APIKey, string, error) {
+	if name == "" || dailyQuota < 0 {
+		return models.APIKey{}, "", fmt.Errorf("[APIKeyService.CreateAPIKey] %w: name is required and quota must not be negative", ErrAPIKeyInvalid)
+	}
+
+	plain := fmt.Sprintf("%s_%s", apiKeyPrefix, generateAPIKeySecret())
+
+	key, err := s.storage.Create(ctx, models.APIKey{
+		Name:       name,
+		Prefix:     plain[:len(apiKeyPrefix)+1+apiKeyPrefixLength],
+		DailyQuota: dailyQuota,
+	}, hashAPIKey(plain))
+	if err != nil {
+		return models.APIKey{}, "", fmt.Errorf("[APIKeyService.CreateAPIKey] %w", err)
+	}
+
+	return key, plain, nil
+}
+
+func (s *APIKeyImpl) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := s.storage.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[APIKeyService.ListAPIKeys] %w", err)
+	}
+	return keys, nil
+}
+
+func (s *APIKeyImpl) RevokeAPIKey(ctx context.Context, id int64) error {
+	if err := s.storage.Revoke(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAPIKeyNotFound
+		}
+		return fmt.Errorf("[APIKeyService.RevokeAPIKey] %w", err)
+	}
+	return nil
+}
+
+func (s *APIKeyImpl) AuthenticateAPIKey(ctx context.Context, plain string) (models.APIKey, error) {
+	if plain == "" {
+		return models.APIKey{}, ErrAPIKeyInvalid
+	}
+
+	key, err := s.storage.GetByHash(ctx, hashAPIKey(plain))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.APIKey{}, ErrAPIKeyInvalid
+		}
+		return models.APIKey{}, fmt.Errorf("[APIKeyService.AuthenticateAPIKey] %w", err)
+	}
+	if key.Revoked() {
+		return models.APIKey{}, ErrAPIKeyRevoked
+	}
+
+	// Rejected requests are not counted, so a client over its quota does
+	// not keep inflating the usage.
+	used, err := s.storage.TrackUsage(ctx, key.ID)
+	if errors.Is(err, storage.ErrQuotaExceeded) {
+		key.RequestsToday = int64(key.DailyQuota)
+		return key, ErrAPIKeyQuotaExceeded
+	}
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("[APIKeyService.AuthenticateAPIKey] %w", err)
+	}
+	key.RequestsToday = used
+
+	return key, nil
+}
+
+// hashAPIKey uses a plain SHA-256: keys carry 256 bits of entropy, so a slow
+// password hash would only add latency to every authenticated request.
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKeySecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	var storedHash string
+	st := &storage.MockAPIKeyStorage{
+		CreateFn: func(ctx context.Context, key models.APIKey, keyHash string) (models.APIKey, error) {
+			storedHash = keyHash
+			key.ID = 1
+			return key, nil
+		},
+	}
+
+	svc := NewAPIKeyService(st)
+	key, plain, err := svc.CreateAPIKey(context.Background(), "partner", 100)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(plain, key.Prefix))
+	assert.Equal(t, hashAPIKey(plain), storedHash)
+	assert.NotContains(t, storedHash, plain)
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	revokedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		key     models.APIKey
+		lookup  error
+		used    int64
+		track   error
+		wantErr error
+	}{
+		{
+			name: "success",
+			key:  models.APIKey{ID: 1, DailyQuota: 10},
+			used: 10,
+		},
+		{
+			name: "unlimited quota",
+			key:  models.APIKey{ID: 1},
+			used: 1_000_000,
+		},
+		{
+			name:    "unknown key",
+			lookup:  sql.ErrNoRows,
+			wantErr: ErrAPIKeyInvalid,
+		},
+		{
+			name:    "revoked",
+			key:     models.APIKey{ID: 1, RevokedAt: &revokedAt},
+			wantErr: ErrAPIKeyRevoked,
+		},
+		{
+			name:    "quota exceeded",
+			key:     models.APIKey{ID: 1, DailyQuota: 10},
+			track:   storage.ErrQuotaExceeded,
+			wantErr: ErrAPIKeyQuotaExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &storage.MockAPIKeyStorage{
+				GetByHashFn: func(ctx context.Context, keyHash string) (models.APIKey, error) {
+					return tt.key, tt.lookup
+				},
+				TrackUsageFn: func(ctx context.Context, id int64) (int64, error) {
+					return tt.used, tt.track
+				},
+			}
+
+			key, err := NewAPIKeyService(st).AuthenticateAPIKey(context.Background(), "wss_key")
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.used, key.RequestsToday)
+			}
+			if tt.track != nil {
+				assert.Equal(t, int64(tt.key.DailyQuota), key.RequestsToday)
+			}
+		})
+	}
+}
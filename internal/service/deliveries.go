@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+)
+
+type DeliveryService interface {
+	ListDeliveries(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error)
+}
+
+var _ DeliveryService = (*DeliveryImpl)(nil)
+
+type DeliveryImpl struct {
+	storage storage.DeliveryStorage
+}
+
+func NewDeliveryService(s storage.DeliveryStorage) *DeliveryImpl {
+	return &DeliveryImpl{storage: s}
+}
+
+const (
+	DefaultDeliveriesLimit = 50
+	MaxDeliveriesLimit     = 500
+)
+
+var ErrInvalidDeliveryFilter = errors.New("invalid delivery filter")
+
+// ListDeliveries returns deliveries newest first. A zero Limit defaults to
+// DefaultDeliveriesLimit.
+func (s *DeliveryImpl) ListDeliveries(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+	if filter.Limit == 0 {
+		filter.Limit = DefaultDeliveriesLimit
+	}
+	if filter.Limit < 0 || filter.Limit > MaxDeliveriesLimit || (filter.Status != "" && !filter.Status.IsValid()) {
+		return nil, ErrInvalidDeliveryFilter
+	}
+
+	deliveries, err := s.storage.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("[DeliveryService.ListDeliveries] %w", err)
+	}
+	return deliveries, nil
+}
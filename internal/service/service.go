@@ -7,17 +7,43 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
 	"github.com/serjnester/weather-subscription-service/domain/models"
 	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/clients/webhook"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
 	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/serjnester/weather-subscription-service/internal/tokens"
+	"github.com/serjnester/weather-subscription-service/pkg/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type Service interface {
-	Subscribe(ctx context.Context, sub models.Subscription) error
+	// Subscribe returns the stored subscription, including generated
+	// credentials such as the token and the webhook secret.
+	Subscribe(ctx context.Context, sub models.Subscription) (models.Subscription, error)
 	Confirm(ctx context.Context, token string) error
 	Unsubscribe(ctx context.Context, token string) error
+	// Subscription returns the subscription identified by token, including
+	// LastSentAt. Any valid token gives read access.
+	Subscription(ctx context.Context, token string) (models.Subscription, error)
+	// SubscriptionsByContact returns the contact's subscriptions, including
+	// LastSentAt.
+	SubscriptionsByContact(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error)
 
-	WeatherForecast(ctx context.Context, city string) (models.Weather, error)
+	WeatherForecast(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error)
+	// SubscriptionForecast returns the subscription identified by a manage
+	// token together with a daily forecast for its city in its preferences.
+	// Confirm and unsubscribe tokens, which are sent in emails, are refused.
+	SubscriptionForecast(ctx context.Context, token string, days int) (models.Subscription, models.Forecast, error)
+
+	// IssueToken returns the token authorizing purpose on sub, to be handed
+	// out in links and API responses.
+	IssueToken(sub models.Subscription, purpose enums.TokenPurpose) string
 }
 
 var _ Service = (*Impl)(nil)
@@ -25,67 +51,357 @@ var _ Service = (*Impl)(nil)
 type Impl struct {
 	storage storage.SubscriptionStorage
 	weather weatherapi.WeatherClient
+	tokens  tokens.Manager
+
+	webhookVerifier       WebhookVerifier
+	allowInsecureWebhooks bool
+	allowPrivateWebhooks  bool
+	lookupHost            webhook.LookupFunc
+
+	deliveries   storage.DeliveryStorage
+	suppressions storage.SuppressionStorage
+	uow          storage.UnitOfWork
+
+	email    notification.Channel
+	renderer notification.Renderer
+	baseURL  string
 }
 
-func NewService(s storage.SubscriptionStorage, weatherCli weatherapi.WeatherClient) *Impl {
-	return &Impl{storage: s, weather: weatherCli}
+// WebhookVerifier proves that the owner of a webhook endpoint accepts
+// deliveries signed with secret.
+type WebhookVerifier interface {
+	Verify(ctx context.Context, url, secret string) error
+}
+
+type Option func(*Impl)
+
+// WithWebhooks enables webhook subscriptions. Plain http endpoints are
+// rejected unless allowInsecure is set.
+func WithWebhooks(verifier WebhookVerifier, allowInsecure bool) Option {
+	return func(s *Impl) {
+		s.webhookVerifier = verifier
+		s.allowInsecureWebhooks = allowInsecure
+	}
+}
+
+// WithPrivateWebhooks lets webhook endpoints resolve to loopback and private
+// addresses, for local development.
+func WithPrivateWebhooks(allow bool) Option {
+	return func(s *Impl) {
+		s.allowPrivateWebhooks = allow
+	}
+}
+
+// WithDeliveries enables LastSentAt in subscription lookups.
+func WithDeliveries(deliveries storage.DeliveryStorage) Option {
+	return func(s *Impl) {
+		s.deliveries = deliveries
+	}
+}
+
+// WithSuppressions rejects email subscriptions for suppressed addresses.
+func WithSuppressions(suppressions storage.SuppressionStorage) Option {
+	return func(s *Impl) {
+		s.suppressions = suppressions
+	}
+}
+
+// WithUnitOfWork runs the checks and the insert of Subscribe in one
+// transaction. The storages of the transaction replace those passed to
+// NewService and the other options for its duration.
+func WithUnitOfWork(uow storage.UnitOfWork) Option {
+	return func(s *Impl) {
+		s.uow = uow
+	}
+}
+
+// WithEmail sends the confirmation email of email subscriptions and the
+// goodbye email on unsubscription through channel, with links under
+// baseURL. A nil channel, when email is not configured, sends nothing.
+func WithEmail(channel notification.Channel, renderer notification.Renderer, baseURL string) Option {
+	return func(s *Impl) {
+		s.email = channel
+		s.renderer = renderer
+		s.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithTokens replaces the default random tokens, e.g. with signed ones.
+func WithTokens(manager tokens.Manager) Option {
+	return func(s *Impl) {
+		s.tokens = manager
+	}
+}
+
+func NewService(s storage.SubscriptionStorage, weatherCli weatherapi.WeatherClient, opts ...Option) *Impl {
+	impl := &Impl{
+		storage:    s,
+		weather:    weatherCli,
+		tokens:     tokens.NewRandomManager(),
+		lookupHost: webhook.LookupHost,
+	}
+	for _, opt := range opts {
+		opt(impl)
+	}
+	return impl
 }
 
 var (
-	ErrAlreadySubscribed = errors.New("email already subscribed to city")
-	ErrTokenNotFound     = errors.New("token not found")
+	ErrAlreadySubscribed         = errors.New("email already subscribed to city")
+	ErrTokenNotFound             = errors.New("token not found")
+	ErrInvalidWebhookURL         = errors.New("invalid webhook url")
+	ErrWebhookVerificationFailed = errors.New("webhook endpoint verification failed")
+	ErrEmailSuppressed           = errors.New("email address is suppressed")
 )
 
-func (s *Impl) Subscribe(ctx context.Context, sub models.Subscription) error {
-	exists, err := s.storage.IsAlreadySubscribed(ctx, sub.Email, sub.City)
-	if err != nil {
-		return err
+// Subscribe stores a new subscription. Email subscriptions wait for
+// confirmation, requested by email; other channels are confirmed by the
+// channel itself (the Telegram chat the request came from, a webhook
+// challenge) and are active immediately. If the confirmation email cannot
+// be sent, the subscription is removed so that it can be retried.
+func (s *Impl) Subscribe(ctx context.Context, sub models.Subscription) (_ models.Subscription, err error) {
+	if sub.Channel == "" {
+		sub.Channel = enums.ChannelEmail
 	}
-	if exists {
-		return ErrAlreadySubscribed
+	ctx, span := startSpan(ctx, "Service.Subscribe",
+		attribute.String("subscription.channel", sub.Channel.String()),
+		attribute.String("subscription.city", sub.City),
+		attribute.String("subscription.frequency", sub.Frequency.String()),
+	)
+	defer func() { endSpan(span, err) }()
+	if sub.Channel == enums.ChannelWebhook {
+		if err := s.validateWebhookURL(ctx, sub.Contact); err != nil {
+			return models.Subscription{}, err
+		}
+		// The endpoint is challenged outside the transaction, and only
+		// if the subscription can be created.
+		defaultStores := storage.Stores{Subscriptions: s.storage, Suppressions: s.suppressions}
+		if err := s.admit(ctx, defaultStores, sub); err != nil {
+			return models.Subscription{}, err
+		}
+		sub.WebhookSecret = generateToken() + generateToken()
+		if err := s.webhookVerifier.Verify(ctx, sub.Contact, sub.WebhookSecret); err != nil {
+			return models.Subscription{}, fmt.Errorf("%w: %v", ErrWebhookVerificationFailed, err)
+		}
 	}
 
+	prefs := sub.Preferences()
+	sub.Units, sub.Lang = prefs.Units, prefs.Lang
 	sub.Token = generateToken()
+	sub.Confirmed = sub.Channel != enums.ChannelEmail
 
-	err = s.storage.Create(ctx, sub)
-	if err != nil {
+	var created models.Subscription
+	err = s.inTx(ctx, func(stores storage.Stores) error {
+		if err := s.admit(ctx, stores, sub); err != nil {
+			return err
+		}
+		var err error
+		created, err = stores.Subscriptions.Create(ctx, sub)
 		return err
+	})
+	// The existence check does not lock anything: a concurrent Subscribe
+	// for the same contact and city is caught by the unique key.
+	if errors.Is(err, storage.ErrDuplicate) {
+		return models.Subscription{}, ErrAlreadySubscribed
+	}
+	if err != nil {
+		return models.Subscription{}, err
 	}
 
-	// todo send confirm email
+	if created.Channel == enums.ChannelEmail {
+		if err := s.sendEmail(ctx, created, notification.KindConfirmation, notification.Data{
+			ConfirmURL: s.baseURL + "/api/v2/confirm/" + s.IssueToken(created, enums.TokenPurposeConfirm),
+		}); err != nil {
+			if err := s.storage.Unsubscribe(ctx, created.Token); err != nil {
+				logging.FromContext(ctx).Error("unconfirmable subscription not removed", zap.Int64("subscription_id", created.ID), zap.Error(err))
+			}
+			return models.Subscription{}, fmt.Errorf("[Service.Subscribe] confirmation: %w", err)
+		}
+	}
 
-	return nil
+	logging.FromContext(ctx).Info("subscription created",
+		zap.Int64("subscription_id", created.ID),
+		zap.String("channel", created.Channel.String()),
+		zap.String("frequency", created.Frequency.String()),
+	)
+	return created, nil
 }
 
-func (s *Impl) Confirm(ctx context.Context, token string) error {
-	sub, err := s.storage.GetByToken(ctx, token)
+// admit checks that sub may be created: the address is not suppressed and
+// the contact is not yet subscribed to the city on the channel.
+func (s *Impl) admit(ctx context.Context, stores storage.Stores, sub models.Subscription) error {
+	if sub.Channel == enums.ChannelEmail && s.suppressions != nil {
+		suppressed, err := stores.Suppressions.Suppressed(ctx, []string{sub.Contact})
+		if err != nil {
+			return fmt.Errorf("[Service.admit] %w", err)
+		}
+		if len(suppressed) > 0 {
+			return ErrEmailSuppressed
+		}
+	}
+
+	exists, err := stores.Subscriptions.IsAlreadySubscribed(ctx, sub.Channel, sub.Contact, sub.City)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrTokenNotFound
+		return fmt.Errorf("[Service.admit] %w", err)
+	}
+	if exists {
+		return ErrAlreadySubscribed
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction when a unit of work is configured, and on
+// the service's own storages otherwise.
+func (s *Impl) inTx(ctx context.Context, fn func(stores storage.Stores) error) error {
+	if s.uow == nil {
+		return fn(storage.Stores{
+			Subscriptions: s.storage,
+			Deliveries:    s.deliveries,
+			Suppressions:  s.suppressions,
+		})
+	}
+	return s.uow.InTx(ctx, fn)
+}
+
+// validateWebhookURL also rejects endpoints in private networks, as the
+// verification request is sent on behalf of an unauthenticated caller.
+// The webhook client checks the dialed address again.
+func (s *Impl) validateWebhookURL(ctx context.Context, raw string) error {
+	if s.webhookVerifier == nil {
+		return fmt.Errorf("%w: webhooks are disabled", ErrInvalidWebhookURL)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && s.allowInsecureWebhooks) {
+		return fmt.Errorf("%w: https is required", ErrInvalidWebhookURL)
+	}
+	if !s.allowPrivateWebhooks {
+		if err := webhook.CheckHost(ctx, s.lookupHost, u.Hostname()); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
 		}
+	}
+	return nil
+}
+
+func (s *Impl) Confirm(ctx context.Context, token string) (err error) {
+	ctx, span := startSpan(ctx, "Service.Confirm")
+	defer func() { endSpan(span, err) }()
+
+	sub, err := s.lookup(ctx, token, enums.TokenPurposeConfirm)
+	if err != nil {
 		return err
 	}
 	if sub.Confirmed {
 		return nil
 	}
 
-	return s.storage.Confirm(ctx, token)
+	if err := s.storage.Confirm(ctx, sub.Token); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("subscription confirmed", zap.Int64("subscription_id", sub.ID))
+	return nil
 }
 
-func (s *Impl) Unsubscribe(ctx context.Context, token string) error {
-	_, err := s.storage.GetByToken(ctx, token)
+func (s *Impl) Unsubscribe(ctx context.Context, token string) (err error) {
+	ctx, span := startSpan(ctx, "Service.Unsubscribe")
+	defer func() { endSpan(span, err) }()
+
+	sub, err := s.lookup(ctx, token, enums.TokenPurposeUnsubscribe)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrTokenNotFound
+		return err
+	}
+	if err := s.storage.Unsubscribe(ctx, sub.Token); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("subscription cancelled", zap.Int64("subscription_id", sub.ID))
+
+	// The subscription is gone either way, so a lost goodbye is only logged.
+	if sub.Channel == enums.ChannelEmail {
+		if err := s.sendEmail(ctx, sub, notification.KindGoodbye, notification.Data{
+			SubscribeURL: s.baseURL + "/",
+		}); err != nil {
+			logging.FromContext(ctx).Warn("goodbye email not sent", zap.Int64("subscription_id", sub.ID), zap.Error(err))
 		}
+	}
+	return nil
+}
+
+// sendEmail renders kind for sub and mails it. Without an email channel it
+// only logs that nothing was sent.
+func (s *Impl) sendEmail(ctx context.Context, sub models.Subscription, kind notification.Kind, data notification.Data) error {
+	if s.email == nil {
+		logging.FromContext(ctx).Warn("email is not configured, message not sent",
+			zap.Int64("subscription_id", sub.ID),
+			zap.String("kind", string(kind)),
+		)
+		return nil
+	}
 
+	data.Subscription = sub
+	msg, err := s.renderer.Render(kind, sub.Preferences().Lang, data)
+	if err != nil {
+		return fmt.Errorf("render %s: %w", kind, err)
+	}
+	if _, err := s.email.Send(ctx, sub, msg); err != nil {
 		return err
 	}
-	return s.storage.Unsubscribe(ctx, token)
+	return nil
+}
+
+func (s *Impl) Subscription(ctx context.Context, token string) (_ models.Subscription, err error) {
+	ctx, span := startSpan(ctx, "Service.Subscription")
+	defer func() { endSpan(span, err) }()
+
+	sub, err := s.lookup(ctx, token, "")
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return models.Subscription{}, err
+		}
+		return models.Subscription{}, fmt.Errorf("[Service.Subscription] %w", err)
+	}
+
+	if sub.LastSentAt, err = s.lastSentAt(ctx, sub.ID); err != nil {
+		return models.Subscription{}, fmt.Errorf("[Service.Subscription] %w", err)
+	}
+	return sub, nil
+}
+
+func (s *Impl) SubscriptionsByContact(ctx context.Context, channel enums.Channel, contact string) (_ []models.Subscription, err error) {
+	ctx, span := startSpan(ctx, "Service.SubscriptionsByContact",
+		attribute.String("subscription.channel", channel.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	subs, err := s.storage.ListByContact(ctx, channel, contact)
+	if err != nil {
+		return nil, fmt.Errorf("[Service.SubscriptionsByContact] %w", err)
+	}
+
+	for i := range subs {
+		if subs[i].LastSentAt, err = s.lastSentAt(ctx, subs[i].ID); err != nil {
+			return nil, fmt.Errorf("[Service.SubscriptionsByContact] %w", err)
+		}
+	}
+	return subs, nil
+}
+
+func (s *Impl) lastSentAt(ctx context.Context, subscriptionID int64) (*time.Time, error) {
+	if s.deliveries == nil {
+		return nil, nil
+	}
+	return s.deliveries.LastSentAt(ctx, subscriptionID)
 }
 
-func (s *Impl) WeatherForecast(ctx context.Context, city string) (models.Weather, error) {
-	forecast, err := s.weather.GetForecast(ctx, city)
+func (s *Impl) WeatherForecast(ctx context.Context, city string, prefs models.Preferences) (_ models.Weather, err error) {
+	ctx, span := startSpan(ctx, "Service.WeatherForecast",
+		attribute.String("weather.city", city),
+	)
+	defer func() { endSpan(span, err) }()
+
+	forecast, err := s.weather.GetForecast(ctx, city, prefs.WithDefaults())
 	if err != nil {
 		return models.Weather{}, fmt.Errorf("[Service.GetWeatherForecast] %w", err)
 	}
@@ -93,6 +409,56 @@ func (s *Impl) WeatherForecast(ctx context.Context, city string) (models.Weather
 	return forecast, nil
 }
 
+func (s *Impl) SubscriptionForecast(ctx context.Context, token string, days int) (_ models.Subscription, _ models.Forecast, err error) {
+	ctx, span := startSpan(ctx, "Service.SubscriptionForecast",
+		attribute.Int("forecast.days", days),
+	)
+	defer func() { endSpan(span, err) }()
+
+	sub, err := s.lookup(ctx, token, enums.TokenPurposeManage)
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return models.Subscription{}, models.Forecast{}, err
+		}
+		return models.Subscription{}, models.Forecast{}, fmt.Errorf("[Service.SubscriptionForecast] %w", err)
+	}
+
+	forecast, err := s.weather.GetDailyForecast(ctx, sub.City, days, sub.Preferences())
+	if err != nil {
+		return models.Subscription{}, models.Forecast{}, fmt.Errorf("[Service.SubscriptionForecast] %w", err)
+	}
+
+	return sub, forecast, nil
+}
+
+func (s *Impl) IssueToken(sub models.Subscription, purpose enums.TokenPurpose) string {
+	return s.tokens.Issue(sub, purpose)
+}
+
+// lookup returns the subscription that token authorizes purpose on; an
+// empty purpose accepts any valid token. Invalid, expired and misused
+// tokens are all reported as ErrTokenNotFound.
+func (s *Impl) lookup(ctx context.Context, token string, purpose enums.TokenPurpose) (models.Subscription, error) {
+	claims, err := s.tokens.Parse(token)
+	if err != nil || (purpose != "" && !claims.Grants(purpose)) {
+		return models.Subscription{}, ErrTokenNotFound
+	}
+
+	var sub models.Subscription
+	if claims.Token != "" {
+		sub, err = s.storage.GetByToken(ctx, claims.Token)
+	} else {
+		sub, err = s.storage.GetByID(ctx, claims.SubscriptionID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Subscription{}, ErrTokenNotFound
+		}
+		return models.Subscription{}, err
+	}
+	return sub, nil
+}
+
 func generateToken() string {
 	b := make([]byte, 16)
 	_, _ = rand.Read(b)
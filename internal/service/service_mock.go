@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+)
+
+type MockService struct {
+	SubscribeFn              func(ctx context.Context, sub models.Subscription) (models.Subscription, error)
+	ConfirmFn                func(ctx context.Context, token string) error
+	UnsubscribeFn            func(ctx context.Context, token string) error
+	SubscriptionFn           func(ctx context.Context, token string) (models.Subscription, error)
+	SubscriptionsByContactFn func(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error)
+	WeatherForecastFn        func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error)
+	SubscriptionForecastFn   func(ctx context.Context, token string, days int) (models.Subscription, models.Forecast, error)
+	IssueTokenFn             func(sub models.Subscription, purpose enums.TokenPurpose) string
+}
+
+func (m *MockService) Subscribe(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+	return m.SubscribeFn(ctx, sub)
+}
+
+func (m *MockService) Confirm(ctx context.Context, token string) error {
+	return m.ConfirmFn(ctx, token)
+}
+
+func (m *MockService) Unsubscribe(ctx context.Context, token string) error {
+	return m.UnsubscribeFn(ctx, token)
+}
+
+func (m *MockService) Subscription(ctx context.Context, token string) (models.Subscription, error) {
+	return m.SubscriptionFn(ctx, token)
+}
+
+func (m *MockService) SubscriptionsByContact(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+	return m.SubscriptionsByContactFn(ctx, channel, contact)
+}
+
+func (m *MockService) WeatherForecast(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+	return m.WeatherForecastFn(ctx, city, prefs)
+}
+
+func (m *MockService) SubscriptionForecast(ctx context.Context, token string, days int) (models.Subscription, models.Forecast, error) {
+	return m.SubscriptionForecastFn(ctx, token, days)
+}
+
+func (m *MockService) IssueToken(sub models.Subscription, purpose enums.TokenPurpose) string {
+	return m.IssueTokenFn(sub, purpose)
+}
+
+type MockAPIKeyService struct {
+	CreateAPIKeyFn       func(ctx context.Context, name string, dailyQuota int) (models.APIKey, string, error)
+	ListAPIKeysFn        func(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKeyFn       func(ctx context.Context, id int64) error
+	AuthenticateAPIKeyFn func(ctx context.Context, key string) (models.APIKey, error)
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, name string, dailyQuota int) (models.APIKey, string, error) {
+	return m.CreateAPIKeyFn(ctx, name, dailyQuota)
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return m.ListAPIKeysFn(ctx)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id int64) error {
+	return m.RevokeAPIKeyFn(ctx, id)
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (models.APIKey, error) {
+	return m.AuthenticateAPIKeyFn(ctx, key)
+}
+
+type MockWebhookVerifier struct {
+	VerifyFn func(ctx context.Context, url, secret string) error
+}
+
+func (m *MockWebhookVerifier) Verify(ctx context.Context, url, secret string) error {
+	return m.VerifyFn(ctx, url, secret)
+}
+
+type MockWeatherStream struct {
+	WatchFn func(ctx context.Context, city string, prefs models.Preferences) (<-chan models.Weather, func(), error)
+}
+
+func (m *MockWeatherStream) Watch(ctx context.Context, city string, prefs models.Preferences) (<-chan models.Weather, func(), error) {
+	return m.WatchFn(ctx, city, prefs)
+}
+
+type MockDeliveryService struct {
+	ListDeliveriesFn func(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error)
+}
+
+func (m *MockDeliveryService) ListDeliveries(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+	return m.ListDeliveriesFn(ctx, filter)
+}
+
+type MockSuppressionService struct {
+	HandleEmailEventsFn func(ctx context.Context, events []models.EmailEvent) (int, error)
+	ListSuppressionsFn  func(ctx context.Context, limit int) ([]models.Suppression, error)
+	RemoveSuppressionFn func(ctx context.Context, email string) error
+}
+
+func (m *MockSuppressionService) HandleEmailEvents(ctx context.Context, events []models.EmailEvent) (int, error) {
+	return m.HandleEmailEventsFn(ctx, events)
+}
+
+func (m *MockSuppressionService) ListSuppressions(ctx context.Context, limit int) ([]models.Suppression, error) {
+	return m.ListSuppressionsFn(ctx, limit)
+}
+
+func (m *MockSuppressionService) RemoveSuppression(ctx context.Context, email string) error {
+	return m.RemoveSuppressionFn(ctx, email)
+}
@@ -2,11 +2,19 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
 	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/serjnester/weather-subscription-service/internal/notification"
 	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/serjnester/weather-subscription-service/internal/tokens"
 	"github.com/stretchr/testify/assert"
+	"net/netip"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/serjnester/weather-subscription-service/domain/models"
 )
@@ -25,7 +33,7 @@ func TestService_WeatherForecast(t *testing.T) {
 			name: "success",
 			mockWeather: func() *weatherapi.MockWeatherClient {
 				return &weatherapi.MockWeatherClient{
-					GetForecastFn: func(ctx context.Context, city string) (models.Weather, error) {
+					GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
 						return models.Weather{
 							Temperature: 25.0,
 							Description: "Sunny",
@@ -42,7 +50,7 @@ func TestService_WeatherForecast(t *testing.T) {
 			name: "city not found",
 			mockWeather: func() *weatherapi.MockWeatherClient {
 				return &weatherapi.MockWeatherClient{
-					GetForecastFn: func(ctx context.Context, city string) (models.Weather, error) {
+					GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
 						return models.Weather{}, weatherapi.ErrCityNotFound
 					},
 				}
@@ -60,7 +68,7 @@ func TestService_WeatherForecast(t *testing.T) {
 				weather: tt.mockWeather(),
 			}
 
-			result, err := s.WeatherForecast(ctx, tt.city)
+			result, err := s.WeatherForecast(ctx, tt.city, models.Preferences{})
 
 			if !errors.Is(err, tt.wantErr) {
 				t.Fatalf("expected err = %v, got = %v", tt.wantErr, err)
@@ -75,7 +83,7 @@ func TestService_WeatherForecast(t *testing.T) {
 
 func TestService_Subscribe(t *testing.T) {
 	weatherClient := weatherapi.MockWeatherClient{
-		GetForecastFn: func(ctx context.Context, city string) (models.Weather, error) {
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
 			return models.Weather{
 				Temperature: 25.0,
 				Description: "Sunny",
@@ -85,42 +93,279 @@ func TestService_Subscribe(t *testing.T) {
 	}
 	t.Run("success", func(t *testing.T) {
 		st := &storage.MockStorage{
-			IsAlreadySubscribedFn: func(ctx context.Context, email, city string) (bool, error) {
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
 				return false, nil
 			},
-			CreateFn: func(ctx context.Context, sub models.Subscription) error {
-				return nil
+			CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+				return sub, nil
 			},
 		}
 
 		svc := NewService(st, weatherClient)
 
-		err := svc.Subscribe(context.Background(), models.Subscription{
-			Email:     "test@example.com",
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Contact:   "test@example.com",
+			City:      "Kyiv",
+			Frequency: "daily",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("suppressed email", func(t *testing.T) {
+		st := &storage.MockStorage{}
+		suppressions := &storage.MockSuppressionStorage{
+			SuppressedFn: func(ctx context.Context, emails []string) (map[string]bool, error) {
+				assert.Equal(t, []string{"Gone@example.com"}, emails)
+				return map[string]bool{"gone@example.com": true}, nil
+			},
+		}
+
+		svc := NewService(st, weatherClient, WithSuppressions(suppressions))
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Contact:   "Gone@example.com",
+			City:      "Kyiv",
+			Frequency: "daily",
+		})
+		assert.ErrorIs(t, err, ErrEmailSuppressed)
+	})
+
+	t.Run("non-email channels are confirmed immediately", func(t *testing.T) {
+		var created models.Subscription
+		st := &storage.MockStorage{
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+				assert.Equal(t, enums.ChannelTelegram, channel)
+				return false, nil
+			},
+			CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+				created = sub
+				return sub, nil
+			},
+		}
+
+		svc := NewService(st, weatherClient)
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Channel:   enums.ChannelTelegram,
+			Contact:   "42",
 			City:      "Kyiv",
 			Frequency: "daily",
 		})
 		assert.NoError(t, err)
+		assert.True(t, created.Confirmed)
+		assert.NotEmpty(t, created.Token)
+	})
+
+	t.Run("webhook is verified and confirmed", func(t *testing.T) {
+		var created models.Subscription
+		st := &storage.MockStorage{
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+				return false, nil
+			},
+			CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+				created = sub
+				return sub, nil
+			},
+		}
+		var verifiedSecret string
+		verifier := &MockWebhookVerifier{
+			VerifyFn: func(ctx context.Context, url, secret string) error {
+				assert.Equal(t, "https://example.com/hook", url)
+				verifiedSecret = secret
+				return nil
+			},
+		}
+
+		svc := NewService(st, weatherClient, WithWebhooks(verifier, false))
+		svc.lookupHost = publicLookup
+		sub, err := svc.Subscribe(context.Background(), models.Subscription{
+			Channel:   enums.ChannelWebhook,
+			Contact:   "https://example.com/hook",
+			City:      "Kyiv",
+			Frequency: "hourly",
+		})
+		assert.NoError(t, err)
+		assert.True(t, created.Confirmed)
+		assert.NotEmpty(t, created.WebhookSecret)
+		assert.Equal(t, created.WebhookSecret, verifiedSecret)
+		assert.Equal(t, created, sub)
+	})
+
+	t.Run("webhook verification failure", func(t *testing.T) {
+		st := &storage.MockStorage{
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+				return false, nil
+			},
+		}
+		verifier := &MockWebhookVerifier{
+			VerifyFn: func(ctx context.Context, url, secret string) error {
+				return errors.New("challenge mismatch")
+			},
+		}
+
+		svc := NewService(st, weatherClient, WithWebhooks(verifier, false))
+		svc.lookupHost = publicLookup
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Channel: enums.ChannelWebhook,
+			Contact: "https://example.com/hook",
+			City:    "Kyiv",
+		})
+		assert.ErrorIs(t, err, ErrWebhookVerificationFailed)
+	})
+
+	t.Run("webhook url must be https", func(t *testing.T) {
+		verifier := &MockWebhookVerifier{}
+		for _, url := range []string{"http://example.com/hook", "example.com/hook", "ftp://example.com"} {
+			svc := NewService(&storage.MockStorage{}, weatherClient, WithWebhooks(verifier, false))
+			_, err := svc.Subscribe(context.Background(), models.Subscription{
+				Channel: enums.ChannelWebhook,
+				Contact: url,
+				City:    "Kyiv",
+			})
+			assert.ErrorIs(t, err, ErrInvalidWebhookURL, url)
+		}
+
+		svc := NewService(&storage.MockStorage{}, weatherClient)
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Channel: enums.ChannelWebhook,
+			Contact: "https://example.com/hook",
+			City:    "Kyiv",
+		})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, "webhooks disabled")
+	})
+
+	t.Run("webhook url must not reach private networks", func(t *testing.T) {
+		lookup := func(ctx context.Context, host string) ([]netip.Addr, error) {
+			if host == "intranet.example.com" {
+				return []netip.Addr{netip.MustParseAddr("10.0.0.8")}, nil
+			}
+			return publicLookup(ctx, host)
+		}
+		verifier := &MockWebhookVerifier{}
+		for _, url := range []string{
+			"http://127.0.0.1/hook",
+			"http://[::1]/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"https://intranet.example.com/hook",
+		} {
+			svc := NewService(&storage.MockStorage{}, weatherClient, WithWebhooks(verifier, true))
+			svc.lookupHost = lookup
+			_, err := svc.Subscribe(context.Background(), models.Subscription{
+				Channel: enums.ChannelWebhook,
+				Contact: url,
+				City:    "Kyiv",
+			})
+			assert.ErrorIs(t, err, ErrInvalidWebhookURL, url)
+		}
+
+		svc := NewService(&storage.MockStorage{
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+				return false, nil
+			},
+			CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+				return sub, nil
+			},
+		}, weatherClient,
+			WithWebhooks(&MockWebhookVerifier{VerifyFn: func(ctx context.Context, url, secret string) error { return nil }}, true),
+			WithPrivateWebhooks(true),
+		)
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Channel: enums.ChannelWebhook,
+			Contact: "http://127.0.0.1:8081/hook",
+			City:    "Kyiv",
+		})
+		assert.NoError(t, err, "allowed for local development")
 	})
 
 	t.Run("already subscribed", func(t *testing.T) {
 		st := &storage.MockStorage{
-			IsAlreadySubscribedFn: func(ctx context.Context, email, city string) (bool, error) {
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
 				return true, nil
 			},
 		}
 		svc := NewService(st, weatherClient)
-		err := svc.Subscribe(context.Background(), models.Subscription{
-			Email: "test@example.com",
-			City:  "Kyiv",
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Contact: "test@example.com",
+			City:    "Kyiv",
 		})
 		assert.ErrorContains(t, err, "already subscribed")
 	})
+
+	t.Run("lost race to the unique key", func(t *testing.T) {
+		st := &storage.MockStorage{
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+				return false, nil
+			},
+			CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+				return models.Subscription{}, fmt.Errorf("[SubImpl.Create] %w", storage.ErrDuplicate)
+			},
+		}
+		svc := NewService(st, weatherClient)
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Contact: "test@example.com",
+			City:    "Kyiv",
+		})
+		assert.ErrorIs(t, err, ErrAlreadySubscribed)
+	})
+
+	t.Run("checks and insert run in the unit of work", func(t *testing.T) {
+		var created models.Subscription
+		txSubs := &storage.MockStorage{
+			IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+				return false, nil
+			},
+			CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+				created = sub
+				return sub, nil
+			},
+		}
+		txSuppressions := &storage.MockSuppressionStorage{
+			SuppressedFn: func(ctx context.Context, emails []string) (map[string]bool, error) {
+				return map[string]bool{}, nil
+			},
+		}
+		var calls int
+		uow := &storage.MockUnitOfWork{
+			InTxFn: func(ctx context.Context, fn func(stores storage.Stores) error) error {
+				calls++
+				return fn(storage.Stores{Subscriptions: txSubs, Suppressions: txSuppressions})
+			},
+		}
+
+		// The storages passed to NewService must not be used.
+		svc := NewService(&storage.MockStorage{}, weatherClient,
+			WithSuppressions(&storage.MockSuppressionStorage{}),
+			WithUnitOfWork(uow),
+		)
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Contact: "test@example.com",
+			City:    "Kyiv",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "test@example.com", created.Contact)
+	})
+
+	t.Run("unit of work error", func(t *testing.T) {
+		uow := &storage.MockUnitOfWork{
+			InTxFn: func(ctx context.Context, fn func(stores storage.Stores) error) error {
+				return errors.New("begin failed")
+			},
+		}
+		svc := NewService(&storage.MockStorage{}, weatherClient, WithUnitOfWork(uow))
+		_, err := svc.Subscribe(context.Background(), models.Subscription{
+			Contact: "test@example.com",
+			City:    "Kyiv",
+		})
+		assert.ErrorContains(t, err, "begin failed")
+	})
+}
+
+func publicLookup(ctx context.Context, host string) ([]netip.Addr, error) {
+	return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
 }
 
 func TestService_Confirm(t *testing.T) {
 	weatherClient := weatherapi.MockWeatherClient{
-		GetForecastFn: func(ctx context.Context, city string) (models.Weather, error) {
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
 			return models.Weather{
 				Temperature: 25.0,
 				Description: "Sunny",
@@ -159,7 +404,7 @@ func TestService_Confirm(t *testing.T) {
 
 func TestService_Unsubscribe(t *testing.T) {
 	weatherClient := weatherapi.MockWeatherClient{
-		GetForecastFn: func(ctx context.Context, city string) (models.Weather, error) {
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
 			return models.Weather{
 				Temperature: 25.0,
 				Description: "Sunny",
@@ -198,3 +443,206 @@ func TestService_Unsubscribe(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestService_Emails(t *testing.T) {
+	renderer, err := notification.NewTemplateRenderer("")
+	assert.NoError(t, err)
+
+	var sent []notification.Message
+	var sendErr error
+	channel := &notification.MockChannel{
+		KindValue: enums.ChannelEmail,
+		SendFn: func(ctx context.Context, sub models.Subscription, msg notification.Message) (string, error) {
+			assert.Equal(t, "test@example.com", sub.Contact)
+			sent = append(sent, msg)
+			return "msg-1", sendErr
+		},
+	}
+	var removed []string
+	st := &storage.MockStorage{
+		IsAlreadySubscribedFn: func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+			return false, nil
+		},
+		CreateFn: func(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+			sub.ID = 1
+			return sub, nil
+		},
+		GetByTokenFn: func(ctx context.Context, token string) (models.Subscription, error) {
+			return models.Subscription{ID: 1, Channel: enums.ChannelEmail, Contact: "test@example.com", City: "Kyiv", Token: token}, nil
+		},
+		UnsubscribeFn: func(ctx context.Context, token string) error {
+			removed = append(removed, token)
+			return nil
+		},
+	}
+	svc := NewService(st, weatherapi.MockWeatherClient{}, WithEmail(channel, renderer, "https://weather.example.com/"))
+	newSub := models.Subscription{Contact: "test@example.com", City: "Kyiv", Frequency: enums.FrequencyDaily}
+
+	t.Run("subscribe sends the confirmation", func(t *testing.T) {
+		sent, removed = nil, nil
+
+		sub, err := svc.Subscribe(context.Background(), newSub)
+		assert.NoError(t, err)
+		assert.Len(t, sent, 1)
+		assert.Equal(t, notification.KindConfirmation, sent[0].Kind)
+		assert.Contains(t, sent[0].Text, "https://weather.example.com/api/v2/confirm/"+sub.Token)
+		assert.Empty(t, removed)
+	})
+
+	t.Run("subscription is removed when the confirmation fails", func(t *testing.T) {
+		sent, removed, sendErr = nil, nil, errors.New("smtp: 451")
+		defer func() { sendErr = nil }()
+
+		_, err := svc.Subscribe(context.Background(), newSub)
+		assert.ErrorIs(t, err, sendErr)
+		assert.Len(t, removed, 1)
+	})
+
+	t.Run("unsubscribe says goodbye", func(t *testing.T) {
+		sent, removed = nil, nil
+
+		assert.NoError(t, svc.Unsubscribe(context.Background(), "token"))
+		assert.Equal(t, []string{"token"}, removed)
+		assert.Len(t, sent, 1)
+		assert.Equal(t, notification.KindGoodbye, sent[0].Kind)
+		assert.Contains(t, sent[0].Text, "https://weather.example.com/")
+	})
+
+	t.Run("a lost goodbye does not fail unsubscribe", func(t *testing.T) {
+		sendErr = errors.New("smtp: 451")
+		defer func() { sendErr = nil }()
+
+		assert.NoError(t, svc.Unsubscribe(context.Background(), "token"))
+	})
+
+	t.Run("other channels get no emails", func(t *testing.T) {
+		sent = nil
+
+		tg := newSub
+		tg.Channel = enums.ChannelTelegram
+		_, err := svc.Subscribe(context.Background(), tg)
+		assert.NoError(t, err)
+		assert.Empty(t, sent)
+	})
+}
+
+func TestService_SubscriptionForecast(t *testing.T) {
+	weatherClient := weatherapi.MockWeatherClient{
+		GetDailyForecastFn: func(ctx context.Context, city string, days int, prefs models.Preferences) (models.Forecast, error) {
+			assert.Equal(t, "Kyiv", city)
+			assert.Equal(t, 3, days)
+			assert.Equal(t, enums.UnitsImperial, prefs.Units)
+			return models.Forecast{City: city, Units: prefs.Units}, nil
+		},
+	}
+	st := &storage.MockStorage{
+		GetByTokenFn: func(ctx context.Context, token string) (models.Subscription, error) {
+			if token != "valid" {
+				return models.Subscription{}, sql.ErrNoRows
+			}
+			return models.Subscription{City: "Kyiv", Units: enums.UnitsImperial, Token: token}, nil
+		},
+	}
+	svc := NewService(st, weatherClient)
+
+	sub, forecast, err := svc.SubscriptionForecast(context.Background(), "valid", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "valid", sub.Token)
+	assert.Equal(t, "Kyiv", forecast.City)
+
+	_, _, err = svc.SubscriptionForecast(context.Background(), "unknown", 3)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+func TestService_Subscription(t *testing.T) {
+	sentAt := time.Date(2025, 5, 20, 8, 0, 3, 0, time.UTC)
+	st := &storage.MockStorage{
+		GetByTokenFn: func(ctx context.Context, token string) (models.Subscription, error) {
+			if token != "valid" {
+				return models.Subscription{}, sql.ErrNoRows
+			}
+			return models.Subscription{ID: 7, City: "Kyiv", Token: token}, nil
+		},
+	}
+	deliveries := &storage.MockDeliveryStorage{
+		LastSentAtFn: func(ctx context.Context, subscriptionID int64) (*time.Time, error) {
+			assert.Equal(t, int64(7), subscriptionID)
+			return &sentAt, nil
+		},
+	}
+
+	t.Run("with deliveries", func(t *testing.T) {
+		svc := NewService(st, weatherapi.MockWeatherClient{}, WithDeliveries(deliveries))
+
+		sub, err := svc.Subscription(context.Background(), "valid")
+		assert.NoError(t, err)
+		assert.Equal(t, "Kyiv", sub.City)
+		assert.Equal(t, &sentAt, sub.LastSentAt)
+
+		_, err = svc.Subscription(context.Background(), "unknown")
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+
+	t.Run("without deliveries", func(t *testing.T) {
+		svc := NewService(st, weatherapi.MockWeatherClient{})
+
+		sub, err := svc.Subscription(context.Background(), "valid")
+		assert.NoError(t, err)
+		assert.Nil(t, sub.LastSentAt)
+	})
+}
+
+func TestService_SignedTokens(t *testing.T) {
+	manager, err := tokens.NewSignedManager(
+		[]tokens.Key{{ID: "k1", Secret: []byte(strings.Repeat("s", 32))}},
+		map[enums.TokenPurpose]time.Duration{enums.TokenPurposeConfirm: time.Hour},
+	)
+	assert.NoError(t, err)
+
+	var confirmed, unsubscribed []string
+	st := &storage.MockStorage{
+		GetByIDFn: func(ctx context.Context, id int64) (models.Subscription, error) {
+			if id != 7 {
+				return models.Subscription{}, sql.ErrNoRows
+			}
+			return models.Subscription{ID: id, City: "Kyiv", Token: "stored"}, nil
+		},
+		ConfirmFn: func(ctx context.Context, token string) error {
+			confirmed = append(confirmed, token)
+			return nil
+		},
+		UnsubscribeFn: func(ctx context.Context, token string) error {
+			unsubscribed = append(unsubscribed, token)
+			return nil
+		},
+	}
+	weatherClient := weatherapi.MockWeatherClient{
+		GetDailyForecastFn: func(ctx context.Context, city string, days int, prefs models.Preferences) (models.Forecast, error) {
+			return models.Forecast{City: city}, nil
+		},
+	}
+	svc := NewService(st, weatherClient, WithTokens(manager))
+	sub := models.Subscription{ID: 7, Token: "stored"}
+	ctx := context.Background()
+
+	confirmToken := svc.IssueToken(sub, enums.TokenPurposeConfirm)
+	assert.NotEqual(t, "stored", confirmToken)
+	assert.ErrorIs(t, svc.Unsubscribe(ctx, confirmToken), ErrTokenNotFound, "wrong purpose")
+	assert.NoError(t, svc.Confirm(ctx, confirmToken))
+	assert.Equal(t, []string{"stored"}, confirmed)
+
+	got, err := svc.Subscription(ctx, confirmToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kyiv", got.City)
+
+	assert.ErrorIs(t, svc.Confirm(ctx, "stored"), ErrTokenNotFound, "stored tokens are not accepted")
+	assert.ErrorIs(t, svc.Unsubscribe(ctx, svc.IssueToken(models.Subscription{ID: 8}, enums.TokenPurposeUnsubscribe)), ErrTokenNotFound)
+
+	_, _, err = svc.SubscriptionForecast(ctx, confirmToken, 3)
+	assert.ErrorIs(t, err, ErrTokenNotFound, "feeds need a manage token")
+	_, _, err = svc.SubscriptionForecast(ctx, svc.IssueToken(sub, enums.TokenPurposeManage), 3)
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.Unsubscribe(ctx, svc.IssueToken(sub, enums.TokenPurposeManage)))
+	assert.Equal(t, []string{"stored"}, unsubscribed)
+}
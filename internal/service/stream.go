@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WeatherStream fans out live weather updates. All watchers of the same
+// city and preferences share a single poller, which stops when the last
+// watcher leaves.
+type WeatherStream interface {
+	// Watch returns a channel receiving the latest weather whenever it
+	// changes, starting with the current value. The first watcher of a
+	// city fetches it, so unknown cities fail with
+	// weatherapi.ErrCityNotFound and start no poller. The channel is never
+	// closed; call stop to release it.
+	Watch(ctx context.Context, city string, prefs models.Preferences) (updates <-chan models.Weather, stop func(), err error)
+}
+
+var _ WeatherStream = (*WeatherHub)(nil)
+
+// ErrStreamLimit is returned by Watch when MaxCities pollers are running
+// and the city is not one of them.
+var ErrStreamLimit = errors.New("too many cities watched")
+
+type WeatherHub struct {
+	weather   weatherapi.WeatherClient
+	interval  time.Duration
+	maxCities int
+	logger    *zap.Logger
+
+	mu      sync.Mutex
+	pollers map[pollerKey]*cityPoller
+}
+
+// NewWeatherHub polls at most maxCities city and preference combinations
+// at a time; 0 means no limit.
+func NewWeatherHub(weatherCli weatherapi.WeatherClient, interval time.Duration, maxCities int, logger *zap.Logger) *WeatherHub {
+	return &WeatherHub{
+		weather:   weatherCli,
+		interval:  interval,
+		maxCities: maxCities,
+		logger:    logger,
+		pollers:   make(map[pollerKey]*cityPoller),
+	}
+}
+
+type pollerKey struct {
+	city  string
+	prefs models.Preferences
+}
+
+type cityPoller struct {
+	cancel    context.CancelFunc
+	listeners map[chan models.Weather]struct{}
+	last      *models.Weather
+}
+
+func (h *WeatherHub) Watch(ctx context.Context, city string, prefs models.Preferences) (<-chan models.Weather, func(), error) {
+	key := pollerKey{city: strings.ToLower(strings.TrimSpace(city)), prefs: prefs.WithDefaults()}
+	ch := make(chan models.Weather, 1)
+
+	// The lock is not held while fetching; the pollers are checked again
+	// afterwards, as another watcher may have started or stopped one.
+	var (
+		p     *cityPoller
+		first *models.Weather
+	)
+	for p == nil {
+		h.mu.Lock()
+		var ok bool
+		p, ok = h.pollers[key]
+		switch {
+		case ok:
+		case h.full():
+			h.mu.Unlock()
+			return nil, nil, ErrStreamLimit
+		case first != nil:
+			pollCtx, cancel := context.WithCancel(context.Background())
+			p = &cityPoller{cancel: cancel, listeners: make(map[chan models.Weather]struct{}), last: first}
+			h.pollers[key] = p
+			go h.poll(pollCtx, key, city, p)
+		default:
+			h.mu.Unlock()
+			weather, err := h.weather.GetForecast(ctx, city, key.prefs)
+			if err != nil {
+				return nil, nil, err
+			}
+			first = &weather
+			continue
+		}
+
+		p.listeners[ch] = struct{}{}
+		if p.last != nil {
+			ch <- *p.last
+		}
+		h.mu.Unlock()
+	}
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			delete(p.listeners, ch)
+			if len(p.listeners) == 0 {
+				p.cancel()
+				delete(h.pollers, key)
+			}
+		})
+	}
+
+	return ch, stop, nil
+}
+
+// full must be called with h.mu held.
+func (h *WeatherHub) full() bool {
+	return h.maxCities > 0 && len(h.pollers) >= h.maxCities
+}
+
+// poll refreshes the weather fetched by the first watcher every interval.
+func (h *WeatherHub) poll(ctx context.Context, key pollerKey, city string, p *cityPoller) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		weather, err := h.weather.GetForecast(ctx, city, key.prefs)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			h.logger.Warn("weather stream poll error", zap.String("city", city), zap.Error(err))
+			continue
+		}
+		h.publish(p, weather)
+	}
+}
+
+// publish delivers weather to every listener if it changed. Slow listeners
+// only ever see the most recent value.
+func (h *WeatherHub) publish(p *cityPoller, weather models.Weather) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if p.last != nil && *p.last == weather {
+		return
+	}
+	p.last = &weather
+
+	for ch := range p.listeners {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- weather
+	}
+}
+
+// pollerCount returns the number of active pollers.
+func (h *WeatherHub) pollerCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.pollers)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/clients/weatherapi"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWeatherHub_Watch(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+		temp  = 20.0
+	)
+	client := &weatherapi.MockWeatherClient{
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return models.Weather{City: "Kyiv", Temperature: temp, Units: prefs.Units, Lang: prefs.Lang}, nil
+		},
+	}
+	setTemp := func(v float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		temp = v
+	}
+	receive := func(t *testing.T, ch <-chan models.Weather) models.Weather {
+		t.Helper()
+		select {
+		case w := <-ch:
+			return w
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for weather")
+			return models.Weather{}
+		}
+	}
+
+	hub := NewWeatherHub(client, 10*time.Millisecond, 0, zap.NewNop())
+
+	first, stopFirst, err := hub.Watch(context.Background(), "Kyiv", models.Preferences{})
+	require.NoError(t, err)
+	require.Equal(t, 20.0, receive(t, first).Temperature)
+
+	second, stopSecond, err := hub.Watch(context.Background(), " kyiv ", models.DefaultPreferences())
+	require.NoError(t, err)
+	require.Equal(t, 20.0, receive(t, second).Temperature, "late watcher gets the current value")
+	require.Equal(t, 1, hub.pollerCount(), "watchers of the same city share a poller")
+	mu.Lock()
+	require.Equal(t, 1, calls, "only the first watcher fetches")
+	mu.Unlock()
+
+	// Unchanged weather is not re-sent.
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, first)
+
+	setTemp(25)
+	require.Equal(t, 25.0, receive(t, first).Temperature)
+	require.Equal(t, 25.0, receive(t, second).Temperature)
+
+	stopFirst()
+	stopFirst()
+	require.Equal(t, 1, hub.pollerCount())
+	stopSecond()
+	require.Equal(t, 0, hub.pollerCount())
+
+	time.Sleep(20 * time.Millisecond) // let an in-flight poll finish
+	mu.Lock()
+	stoppedAt := calls
+	mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, stoppedAt, calls, "poller stops with the last watcher")
+}
+
+func TestWeatherHub_Watch_UnknownCity(t *testing.T) {
+	client := &weatherapi.MockWeatherClient{
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			return models.Weather{}, weatherapi.ErrCityNotFound
+		},
+	}
+	hub := NewWeatherHub(client, time.Hour, 0, zap.NewNop())
+
+	_, _, err := hub.Watch(context.Background(), "Nowhere", models.Preferences{})
+	require.ErrorIs(t, err, weatherapi.ErrCityNotFound)
+	require.Equal(t, 0, hub.pollerCount(), "no poller for a city that failed")
+}
+
+func TestWeatherHub_Watch_MaxCities(t *testing.T) {
+	client := &weatherapi.MockWeatherClient{
+		GetForecastFn: func(ctx context.Context, city string, prefs models.Preferences) (models.Weather, error) {
+			return models.Weather{City: city}, nil
+		},
+	}
+	hub := NewWeatherHub(client, time.Hour, 1, zap.NewNop())
+
+	_, stop, err := hub.Watch(context.Background(), "Kyiv", models.Preferences{})
+	require.NoError(t, err)
+
+	_, stopAgain, err := hub.Watch(context.Background(), "Kyiv", models.Preferences{})
+	require.NoError(t, err, "watched cities can still be joined")
+	stopAgain()
+
+	_, _, err = hub.Watch(context.Background(), "Lviv", models.Preferences{})
+	require.ErrorIs(t, err, ErrStreamLimit)
+
+	stop()
+	_, stop, err = hub.Watch(context.Background(), "Lviv", models.Preferences{})
+	require.NoError(t, err, "the slot is freed by the last watcher")
+	stop()
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+)
+
+// SuppressionService maintains the list of addresses that must not be
+// mailed: hard-bounced and complaining ones.
+type SuppressionService interface {
+	// HandleEmailEvents suppresses addresses with permanent bounces or
+	// complaints and returns how many were suppressed. Soft bounces are
+	// ignored.
+	HandleEmailEvents(ctx context.Context, events []models.EmailEvent) (int, error)
+	ListSuppressions(ctx context.Context, limit int) ([]models.Suppression, error)
+	RemoveSuppression(ctx context.Context, email string) error
+}
+
+var _ SuppressionService = (*SuppressionImpl)(nil)
+
+type SuppressionImpl struct {
+	storage storage.SuppressionStorage
+}
+
+func NewSuppressionService(s storage.SuppressionStorage) *SuppressionImpl {
+	return &SuppressionImpl{storage: s}
+}
+
+var ErrSuppressionNotFound = errors.New("suppression not found")
+
+func (s *SuppressionImpl) HandleEmailEvents(ctx context.Context, events []models.EmailEvent) (int, error) {
+	var suppressed int
+	for _, e := range events {
+		reason := enums.SuppressionReasonComplaint
+		if e.Type == enums.EmailEventBounce {
+			if !e.Permanent {
+				continue
+			}
+			reason = enums.SuppressionReasonHardBounce
+		}
+
+		detail := e.Detail
+		if detail == "" {
+			detail = e.Status
+		}
+		if err := s.storage.Suppress(ctx, models.Suppression{Email: e.Email, Reason: reason, Detail: detail}); err != nil {
+			return suppressed, fmt.Errorf("[SuppressionService.HandleEmailEvents] %w", err)
+		}
+		suppressed++
+	}
+	return suppressed, nil
+}
+
+func (s *SuppressionImpl) ListSuppressions(ctx context.Context, limit int) ([]models.Suppression, error) {
+	suppressions, err := s.storage.List(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("[SuppressionService.ListSuppressions] %w", err)
+	}
+	return suppressions, nil
+}
+
+func (s *SuppressionImpl) RemoveSuppression(ctx context.Context, email string) error {
+	err := s.storage.Remove(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSuppressionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("[SuppressionService.RemoveSuppression] %w", err)
+	}
+	return nil
+}
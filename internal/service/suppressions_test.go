@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSuppressionService_HandleEmailEvents(t *testing.T) {
+	var suppressed []models.Suppression
+	svc := NewSuppressionService(&storage.MockSuppressionStorage{
+		SuppressFn: func(ctx context.Context, s models.Suppression) error {
+			suppressed = append(suppressed, s)
+			return nil
+		},
+	})
+
+	n, err := svc.HandleEmailEvents(context.Background(), []models.EmailEvent{
+		{Type: enums.EmailEventBounce, Email: "gone@example.com", Permanent: true, Status: "5.1.1"},
+		{Type: enums.EmailEventBounce, Email: "full@example.com", Status: "4.2.2"},
+		{Type: enums.EmailEventComplaint, Email: "angry@example.com", Detail: "abuse"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []models.Suppression{
+		{Email: "gone@example.com", Reason: enums.SuppressionReasonHardBounce, Detail: "5.1.1"},
+		{Email: "angry@example.com", Reason: enums.SuppressionReasonComplaint, Detail: "abuse"},
+	}, suppressed)
+}
+
+func TestSuppressionService_RemoveSuppression(t *testing.T) {
+	svc := NewSuppressionService(&storage.MockSuppressionStorage{
+		RemoveFn: func(ctx context.Context, email string) error {
+			return sql.ErrNoRows
+		},
+	})
+
+	err := svc.RemoveSuppression(context.Background(), "nobody@example.com")
+	assert.ErrorIs(t, err, ErrSuppressionNotFound)
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"time"
+)
+
+type APIKeyStorage interface {
+	Create(ctx context.Context, key models.APIKey, keyHash string) (models.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	List(ctx context.Context) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	// TrackUsage counts one request for the key and returns today's total.
+	// Requests over the daily quota are not counted and return
+	// ErrQuotaExceeded.
+	TrackUsage(ctx context.Context, id int64) (int64, error)
+}
+
+// ErrQuotaExceeded is returned by TrackUsage for a key that already used
+// its daily quota.
+var ErrQuotaExceeded = errors.New("daily quota exceeded")
+
+var _ APIKeyStorage = (*APIKeyImpl)(nil)
+
+type APIKeyImpl struct {
+	q *Queries
+}
+
+func NewAPIKeyStorage(q *Queries) *APIKeyImpl {
+	return &APIKeyImpl{q: q}
+}
+
+func (s *APIKeyImpl) Create(ctx context.Context, key models.APIKey, keyHash string) (models.APIKey, error) {
+	dbKey, err := s.q.CreateAPIKey(ctx, CreateAPIKeyParams{
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    keyHash,
+		DailyQuota: int32(key.DailyQuota),
+	})
+	if err != nil {
+		return models.APIKey{}, mapError(err)
+	}
+
+	return apiKeyFromDB(dbKey), nil
+}
+
+func (s *APIKeyImpl) GetByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	dbKey, err := s.q.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return apiKeyFromDB(dbKey), nil
+}
+
+func (s *APIKeyImpl) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.q.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]models.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, models.APIKey{
+			ID:            int64(row.ID),
+			Name:          row.Name,
+			Prefix:        row.Prefix,
+			DailyQuota:    int(row.DailyQuota),
+			RequestsToday: row.RequestsToday,
+			LastUsedAt:    nullTime(row.LastUsedAt),
+			RevokedAt:     nullTime(row.RevokedAt),
+			CreatedAt:     row.CreatedAt,
+		})
+	}
+
+	return keys, nil
+}
+
+// Revoke returns sql.ErrNoRows if the key does not exist or is already revoked.
+func (s *APIKeyImpl) Revoke(ctx context.Context, id int64) error {
+	affected, err := s.q.RevokeAPIKey(ctx, int32(id))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *APIKeyImpl) TrackUsage(ctx context.Context, id int64) (int64, error) {
+	used, err := s.q.TrackAPIKeyUsage(ctx, int32(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrQuotaExceeded
+	}
+	return used, mapError(err)
+}
+
+func apiKeyFromDB(k ApiKey) models.APIKey {
+	return models.APIKey{
+		ID:         int64(k.ID),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		DailyQuota: int(k.DailyQuota),
+		LastUsedAt: nullTime(k.LastUsedAt),
+		RevokedAt:  nullTime(k.RevokedAt),
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,494 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testSubscriptionStorage checks the behavior every SubscriptionStorage
+// implementation must share. subStorage must be empty.
+func testSubscriptionStorage(t *testing.T, subStorage storage.SubscriptionStorage) {
+	ctx := context.Background()
+	sub := models.Subscription{
+		Channel:   enums.ChannelEmail,
+		Contact:   "test@example.com",
+		City:      "Kyiv",
+		Token:     "abc123",
+		Frequency: "daily",
+		Units:     enums.UnitsImperial,
+		Lang:      "uk",
+	}
+
+	// Insert
+	created, err := subStorage.Create(ctx, sub)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+	require.False(t, created.NextRunAt.IsZero())
+	require.False(t, created.CreatedAt.IsZero())
+	require.Equal(t, enums.SubscriptionStatusPending, created.Status())
+
+	// GetByToken maps every column
+	got, err := subStorage.GetByToken(ctx, sub.Token)
+	require.NoError(t, err)
+	require.Equal(t, created, got)
+	require.Equal(t, sub.Contact, got.Contact)
+	require.Equal(t, enums.ChannelEmail, got.Channel)
+	require.Equal(t, enums.FrequencyDaily, got.Frequency)
+	require.Equal(t, sub.Preferences(), got.Preferences())
+	require.False(t, got.Confirmed)
+
+	// GetByID
+	byID, err := subStorage.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, created, byID)
+
+	// Not found
+	_, err = subStorage.GetByToken(ctx, "missing")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = subStorage.GetByID(ctx, created.ID+1000)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Unique keys
+	dup := sub
+	dup.Token = "other"
+	_, err = subStorage.Create(ctx, dup)
+	require.ErrorIs(t, err, storage.ErrDuplicate, "same channel, contact and city")
+
+	dup = sub
+	dup.City = "Lviv"
+	_, err = subStorage.Create(ctx, dup)
+	require.ErrorIs(t, err, storage.ErrDuplicate, "same token")
+
+	exists, err := subStorage.IsAlreadySubscribed(ctx, enums.ChannelEmail, sub.Contact, "Lviv")
+	require.NoError(t, err)
+	require.False(t, exists, "failed inserts leave nothing behind")
+
+	// Same contact and city on another channel is a separate subscription
+	tgSub := sub
+	tgSub.Channel = enums.ChannelTelegram
+	tgSub.Token = "tg123"
+	tgSub.Confirmed = true
+	tgCreated, err := subStorage.Create(ctx, tgSub)
+	require.NoError(t, err)
+
+	exists, err = subStorage.IsAlreadySubscribed(ctx, enums.ChannelTelegram, sub.Contact, sub.City)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	byContact, err := subStorage.ListByContact(ctx, enums.ChannelTelegram, sub.Contact)
+	require.NoError(t, err)
+	require.Len(t, byContact, 1)
+	require.Equal(t, "tg123", byContact[0].Token)
+	require.True(t, byContact[0].Confirmed)
+
+	// ListByContact is ordered by ID
+	lviv := sub
+	lviv.City = "Lviv"
+	lviv.Token = "lviv123"
+	lvivSub, err := subStorage.Create(ctx, lviv)
+	require.NoError(t, err)
+
+	byContact, err = subStorage.ListByContact(ctx, enums.ChannelEmail, sub.Contact)
+	require.NoError(t, err)
+	require.Len(t, byContact, 2)
+	require.Equal(t, created, byContact[0])
+	require.Equal(t, lvivSub.ID, byContact[1].ID)
+
+	byContact, err = subStorage.ListByContact(ctx, enums.ChannelEmail, "nobody@example.com")
+	require.NoError(t, err)
+	require.Empty(t, byContact)
+
+	// ListByEmail ignores case and other channels
+	mixedCase := sub
+	mixedCase.Contact = "Test@Example.com"
+	mixedCase.City = "Odesa"
+	mixedCase.Token = "mixed123"
+	mixedSub, err := subStorage.Create(ctx, mixedCase)
+	require.NoError(t, err)
+
+	byEmail, err := subStorage.ListByEmail(ctx, "TEST@example.COM")
+	require.NoError(t, err)
+	require.Equal(t, []models.Subscription{created, lvivSub, mixedSub}, byEmail)
+
+	byEmail, err = subStorage.ListByEmail(ctx, "nobody@example.com")
+	require.NoError(t, err)
+	require.Empty(t, byEmail)
+
+	// Webhook secret
+	hook, err := subStorage.Create(ctx, models.Subscription{
+		Channel:       enums.ChannelWebhook,
+		Contact:       "https://example.com/hook",
+		City:          "Kyiv",
+		Frequency:     enums.FrequencyHourly,
+		Units:         enums.UnitsMetric,
+		Lang:          enums.LanguageEnglish,
+		Token:         "webhook-token",
+		WebhookSecret: "secret",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "secret", hook.WebhookSecret)
+
+	// Confirm
+	err = subStorage.Confirm(ctx, sub.Token)
+	require.NoError(t, err)
+	require.NoError(t, subStorage.Confirm(ctx, "missing"))
+
+	confirmed, err := subStorage.GetByToken(ctx, sub.Token)
+	require.NoError(t, err)
+	require.True(t, confirmed.Confirmed)
+	require.Equal(t, enums.SubscriptionStatusActive, confirmed.Status())
+
+	// ListDue returns confirmed subscriptions only, earliest first
+	due, err := subStorage.ListDue(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	require.Equal(t, confirmed, due[0])
+	require.Equal(t, tgCreated, due[1])
+
+	due, err = subStorage.ListDue(ctx, time.Now().Add(time.Hour), 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, created.ID, due[0].ID)
+
+	due, err = subStorage.ListDue(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Empty(t, due)
+
+	for _, limit := range []int{0, -1} {
+		due, err = subStorage.ListDue(ctx, time.Now().Add(time.Hour), limit)
+		require.NoError(t, err, limit)
+		require.Empty(t, due, limit)
+	}
+
+	// Unsubscribe
+	err = subStorage.Unsubscribe(ctx, sub.Token)
+	require.NoError(t, err)
+	require.NoError(t, subStorage.Unsubscribe(ctx, sub.Token))
+
+	_, err = subStorage.GetByToken(ctx, sub.Token)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	exists, err = subStorage.IsAlreadySubscribed(ctx, enums.ChannelEmail, sub.Contact, sub.City)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// Concurrent inserts of the same subscription: exactly one wins.
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = subStorage.Create(ctx, models.Subscription{
+				Channel:   enums.ChannelEmail,
+				Contact:   "race@example.com",
+				City:      "Odesa",
+				Frequency: enums.FrequencyDaily,
+				Units:     enums.UnitsMetric,
+				Lang:      enums.LanguageEnglish,
+				Token:     fmt.Sprintf("race-%d", i),
+			})
+		}()
+	}
+	wg.Wait()
+
+	var won int
+	for _, err := range errs {
+		if err == nil {
+			won++
+			continue
+		}
+		require.ErrorIs(t, err, storage.ErrDuplicate)
+	}
+	require.Equal(t, 1, won)
+}
+
+func testAPIKeyStorage(t *testing.T, keyStorage storage.APIKeyStorage) {
+	ctx := context.Background()
+
+	// Create
+	key, err := keyStorage.Create(ctx, models.APIKey{Name: "partner", Prefix: "wss_abcd", DailyQuota: 2}, "hash")
+	require.NoError(t, err)
+	require.NotZero(t, key.ID)
+
+	// GetByHash
+	got, err := keyStorage.GetByHash(ctx, "hash")
+	require.NoError(t, err)
+	require.Equal(t, key.ID, got.ID)
+	require.Equal(t, 2, got.DailyQuota)
+
+	// TrackUsage
+	used, err := keyStorage.TrackUsage(ctx, key.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	used, err = keyStorage.TrackUsage(ctx, key.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), used)
+
+	// Requests over the quota are not counted
+	_, err = keyStorage.TrackUsage(ctx, key.ID)
+	require.ErrorIs(t, err, storage.ErrQuotaExceeded)
+
+	list, err := keyStorage.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, int64(2), list[0].RequestsToday)
+	require.NotNil(t, list[0].LastUsedAt)
+
+	// Revoke
+	require.NoError(t, keyStorage.Revoke(ctx, key.ID))
+	require.ErrorIs(t, keyStorage.Revoke(ctx, key.ID), sql.ErrNoRows)
+
+	revoked, err := keyStorage.GetByHash(ctx, "hash")
+	require.NoError(t, err)
+	require.True(t, revoked.Revoked())
+
+	// A zero quota is unlimited
+	unlimited, err := keyStorage.Create(ctx, models.APIKey{Name: "internal", Prefix: "wss_efgh"}, "unlimited-hash")
+	require.NoError(t, err)
+	for range 3 {
+		_, err = keyStorage.TrackUsage(ctx, unlimited.ID)
+		require.NoError(t, err)
+	}
+}
+
+func testWebhookAttemptStorage(t *testing.T, subStorage storage.SubscriptionStorage, attemptStorage storage.WebhookAttemptStorage) {
+	ctx := context.Background()
+	_, err := subStorage.Create(ctx, models.Subscription{
+		Channel:       enums.ChannelWebhook,
+		Contact:       "https://example.com/hook",
+		City:          "Kyiv",
+		Frequency:     enums.FrequencyHourly,
+		Units:         enums.UnitsMetric,
+		Lang:          enums.LanguageEnglish,
+		Token:         "webhook-token",
+		Confirmed:     true,
+		WebhookSecret: "secret",
+	})
+	require.NoError(t, err)
+
+	sub, err := subStorage.GetByToken(ctx, "webhook-token")
+	require.NoError(t, err)
+	require.Equal(t, "secret", sub.WebhookSecret)
+	require.NotZero(t, sub.ID)
+
+	require.NoError(t, attemptStorage.LogAttempt(ctx, models.WebhookAttempt{
+		SubscriptionID: sub.ID, DeliveryID: "d1", Attempt: 1, Error: "connection refused", Duration: 30 * time.Millisecond,
+	}))
+	require.NoError(t, attemptStorage.LogAttempt(ctx, models.WebhookAttempt{
+		SubscriptionID: sub.ID, DeliveryID: "d1", Attempt: 2, StatusCode: 200, Duration: 12 * time.Millisecond,
+	}))
+
+	attempts, err := attemptStorage.ListAttempts(ctx, sub.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	require.Equal(t, 2, attempts[0].Attempt)
+	require.Equal(t, 200, attempts[0].StatusCode)
+	require.Empty(t, attempts[0].Error)
+	require.Equal(t, 1, attempts[1].Attempt)
+	require.Zero(t, attempts[1].StatusCode)
+	require.Equal(t, "connection refused", attempts[1].Error)
+	require.Equal(t, 30*time.Millisecond, attempts[1].Duration)
+
+	require.NoError(t, subStorage.Unsubscribe(ctx, "webhook-token"))
+	attempts, err = attemptStorage.ListAttempts(ctx, sub.ID, 10)
+	require.NoError(t, err)
+	require.Empty(t, attempts)
+}
+
+func testScheduleStorage(t *testing.T, subStorage storage.SubscriptionStorage, schedule storage.ScheduleStorage) {
+	ctx := context.Background()
+	for _, token := range []string{"t1", "t2", "t3"} {
+		_, err := subStorage.Create(ctx, models.Subscription{
+			Channel:   enums.ChannelEmail,
+			Contact:   token + "@example.com",
+			City:      "Kyiv",
+			Frequency: enums.FrequencyDaily,
+			Units:     enums.UnitsMetric,
+			Lang:      enums.LanguageEnglish,
+			Token:     token,
+			Confirmed: token != "t3",
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := schedule.ClaimDue(ctx, "a", 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	require.Equal(t, enums.FrequencyDaily, first[0].Frequency)
+
+	second, err := schedule.ClaimDue(ctx, "b", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, second, 1, "unconfirmed and leased subscriptions are not claimed")
+	require.NotEqual(t, first[0].ID, second[0].ID)
+
+	require.ErrorIs(t, schedule.Complete(ctx, "b", first[0].ID, time.Now().Add(time.Hour)), sql.ErrNoRows)
+	require.NoError(t, schedule.Complete(ctx, "a", first[0].ID, time.Now().Add(time.Hour)))
+	require.NoError(t, schedule.Release(ctx, "b", []int64{second[0].ID}))
+
+	again, err := schedule.ClaimDue(ctx, "c", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, again, 1, "completed subscriptions wait for next_run_at")
+	require.Equal(t, second[0].ID, again[0].ID)
+
+	// A zero lease expires immediately, as if the owner had crashed.
+	reclaimed, err := schedule.ClaimDue(ctx, "d", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, reclaimed, 1)
+	require.ErrorIs(t, schedule.Complete(ctx, "c", again[0].ID, time.Now()), sql.ErrNoRows)
+}
+
+func testDeliveryStorage(t *testing.T, subStorage storage.SubscriptionStorage, deliveries storage.DeliveryStorage) {
+	ctx := context.Background()
+	sub, err := subStorage.Create(ctx, models.Subscription{
+		Channel:   enums.ChannelEmail,
+		Contact:   "test@example.com",
+		City:      "Kyiv",
+		Frequency: enums.FrequencyDaily,
+		Units:     enums.UnitsMetric,
+		Lang:      enums.LanguageEnglish,
+		Token:     "token",
+		Confirmed: true,
+	})
+	require.NoError(t, err)
+
+	last, err := deliveries.LastSentAt(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Nil(t, last)
+
+	scheduledFor := time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC)
+	sentAt := scheduledFor.Add(3 * time.Second)
+	require.NoError(t, deliveries.Record(ctx, models.Delivery{
+		SubscriptionID: sub.ID, Channel: enums.ChannelEmail, ScheduledFor: scheduledFor,
+		SentAt: &sentAt, Status: enums.DeliveryStatusSent, ProviderMessageID: "msg-1",
+	}))
+	require.NoError(t, deliveries.Record(ctx, models.Delivery{
+		SubscriptionID: sub.ID, Channel: enums.ChannelEmail, ScheduledFor: scheduledFor.AddDate(0, 0, 1),
+		Status: enums.DeliveryStatusFailed, Error: "smtp: 451",
+	}))
+
+	last, err = deliveries.LastSentAt(ctx, sub.ID)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	require.True(t, sentAt.Equal(*last))
+
+	all, err := deliveries.List(ctx, models.DeliveryFilter{SubscriptionID: sub.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	require.Equal(t, enums.DeliveryStatusFailed, all[0].Status)
+	require.Equal(t, "smtp: 451", all[0].Error)
+	require.Nil(t, all[0].SentAt)
+	require.Equal(t, "msg-1", all[1].ProviderMessageID)
+
+	failed, err := deliveries.List(ctx, models.DeliveryFilter{Status: enums.DeliveryStatusFailed, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+
+	older, err := deliveries.List(ctx, models.DeliveryFilter{BeforeID: all[0].ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, older, 1)
+	require.Equal(t, all[1].ID, older[0].ID)
+
+	err = deliveries.Record(ctx, models.Delivery{
+		SubscriptionID: sub.ID + 1000, Channel: enums.ChannelEmail, ScheduledFor: scheduledFor,
+		Status: enums.DeliveryStatusSent,
+	})
+	require.ErrorIs(t, err, storage.ErrMissingReference)
+
+	err = deliveries.Record(ctx, models.Delivery{
+		SubscriptionID: sub.ID, Channel: enums.ChannelEmail, ScheduledFor: scheduledFor,
+		Status: "bounced",
+	})
+	require.ErrorIs(t, err, storage.ErrInvalidValue)
+}
+
+func testUnitOfWork(t *testing.T, uow storage.UnitOfWork, subStorage storage.SubscriptionStorage) {
+	ctx := context.Background()
+	newSub := func(contact string) models.Subscription {
+		return models.Subscription{
+			Channel:   enums.ChannelEmail,
+			Contact:   contact,
+			City:      "Kyiv",
+			Frequency: enums.FrequencyDaily,
+			Units:     enums.UnitsMetric,
+			Lang:      enums.LanguageEnglish,
+			Token:     "token-" + contact,
+		}
+	}
+
+	var committed models.Subscription
+	err := uow.InTx(ctx, func(stores storage.Stores) error {
+		var err error
+		committed, err = stores.Subscriptions.Create(ctx, newSub("commit@example.com"))
+		return err
+	})
+	require.NoError(t, err)
+
+	got, err := subStorage.GetByID(ctx, committed.ID)
+	require.NoError(t, err)
+	require.Equal(t, "commit@example.com", got.Contact)
+
+	fnErr := errors.New("abort")
+	err = uow.InTx(ctx, func(stores storage.Stores) error {
+		if _, err := stores.Subscriptions.Create(ctx, newSub("rollback@example.com")); err != nil {
+			return err
+		}
+		exists, err := stores.Subscriptions.IsAlreadySubscribed(ctx, enums.ChannelEmail, "rollback@example.com", "Kyiv")
+		require.NoError(t, err)
+		require.True(t, exists, "the transaction sees its own writes")
+		return fnErr
+	})
+	require.Same(t, fnErr, err)
+
+	exists, err := subStorage.IsAlreadySubscribed(ctx, enums.ChannelEmail, "rollback@example.com", "Kyiv")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	err = uow.InTx(ctx, func(stores storage.Stores) error {
+		_, err := stores.Subscriptions.Create(ctx, newSub("commit@example.com"))
+		return err
+	})
+	require.ErrorIs(t, err, storage.ErrDuplicate)
+}
+
+func testSuppressionStorage(t *testing.T, suppressions storage.SuppressionStorage) {
+	ctx := context.Background()
+
+	require.NoError(t, suppressions.Suppress(ctx, models.Suppression{
+		Email: "Angry@Example.com", Reason: enums.SuppressionReasonComplaint, Detail: "abuse",
+	}))
+	require.NoError(t, suppressions.Suppress(ctx, models.Suppression{
+		Email: "angry@example.com", Reason: enums.SuppressionReasonHardBounce, Detail: "5.1.1",
+	}))
+	require.NoError(t, suppressions.Suppress(ctx, models.Suppression{
+		Email: "gone@example.com", Reason: enums.SuppressionReasonHardBounce,
+	}))
+
+	list, err := suppressions.List(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	for _, s := range list {
+		if s.Email == "angry@example.com" {
+			require.Equal(t, enums.SuppressionReasonComplaint, s.Reason, "a later bounce must not downgrade a complaint")
+		}
+	}
+
+	suppressed, err := suppressions.Suppressed(ctx, []string{"ANGRY@example.com", "fine@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"angry@example.com": true}, suppressed)
+
+	require.NoError(t, suppressions.Remove(ctx, "Gone@Example.com"))
+	require.ErrorIs(t, suppressions.Remove(ctx, "gone@example.com"), sql.ErrNoRows)
+}
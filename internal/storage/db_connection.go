@@ -3,19 +3,61 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
 	"github.com/serjnester/weather-subscription-service/internal/configs"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/zap"
+	"net"
+	"sync/atomic"
 	"time"
 )
 
-func NewDBConn(ctx context.Context, conf configs.DB, logger *zap.Logger) (*sql.DB, error) {
-	db, err := sql.Open("postgres", conf.ConnectionURL())
+// Connector opens Postgres connections with the current password, so a
+// rotated password is used by new connections while open ones are
+// recycled after ConnMaxLifetime.
+type Connector struct {
+	conf     configs.DB
+	password atomic.Pointer[string]
+}
+
+func NewConnector(conf configs.DB) *Connector {
+	c := &Connector{conf: conf}
+	c.SetPassword(conf.Password)
+	return c
+}
+
+func (c *Connector) SetPassword(password string) {
+	c.password.Store(&password)
+}
+
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conf := c.conf
+	conf.Password = *c.password.Load()
+	connector, err := pq.NewConnector(conf.ConnectionURL())
 	if err != nil {
-		return nil, fmt.Errorf("[NewDBConn] sql open: %w", err)
+		return nil, err
 	}
+	return connector.Connect(ctx)
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func NewDBConn(ctx context.Context, connector *Connector, logger *zap.Logger) (*sql.DB, error) {
+	conf := connector.conf
+
+	// Queries run through an instrumented driver; statements are traced
+	// without their arguments.
+	db := otelsql.OpenDB(connector,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true}),
+	)
 
-	if err = waitDBConn(ctx, conf, db, logger); err != nil {
+	if err := waitDBConn(ctx, conf, db, logger); err != nil {
 		return nil, err
 	}
 
@@ -33,9 +75,10 @@ func waitDBConn(ctx context.Context, conf configs.DB, db *sql.DB, logger *zap.Lo
 	ctx, cancel := context.WithTimeout(ctx, waitPingDBTimeout)
 	defer cancel()
 
-	conf.User = "user"
-	conf.Password = "password"
-	logger.Info("START wait DB connection", zap.String("database URL", conf.ConnectionURL()))
+	logger.Info("START wait DB connection",
+		zap.String("db_host", net.JoinHostPort(conf.Host, conf.Port)),
+		zap.String("db_name", conf.Database),
+	)
 
 	attempts := 0
 
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"time"
+)
+
+type DeliveryStorage interface {
+	Record(ctx context.Context, d models.Delivery) error
+	List(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error)
+	// LastSentAt returns nil if nothing was ever delivered to the
+	// subscription.
+	LastSentAt(ctx context.Context, subscriptionID int64) (*time.Time, error)
+}
+
+var _ DeliveryStorage = (*DeliveryImpl)(nil)
+
+type DeliveryImpl struct {
+	q *Queries
+}
+
+func NewDeliveryStorage(q *Queries) *DeliveryImpl {
+	return &DeliveryImpl{q: q}
+}
+
+func (s *DeliveryImpl) Record(ctx context.Context, d models.Delivery) error {
+	var sentAt sql.NullTime
+	if d.SentAt != nil {
+		sentAt = sql.NullTime{Time: *d.SentAt, Valid: true}
+	}
+	err := s.q.CreateDelivery(ctx, CreateDeliveryParams{
+		SubscriptionID:    int32(d.SubscriptionID),
+		Channel:           d.Channel,
+		ScheduledFor:      d.ScheduledFor,
+		SentAt:            sentAt,
+		Status:            d.Status,
+		ProviderMessageID: sql.NullString{String: d.ProviderMessageID, Valid: d.ProviderMessageID != ""},
+		Error:             sql.NullString{String: d.Error, Valid: d.Error != ""},
+	})
+	return mapError(err)
+}
+
+func (s *DeliveryImpl) List(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+	rows, err := s.q.ListDeliveries(ctx, ListDeliveriesParams{
+		SubscriptionID: int32(filter.SubscriptionID),
+		Status:         filter.Status.String(),
+		BeforeID:       filter.BeforeID,
+		RowLimit:       int32(filter.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.Delivery, 0, len(rows))
+	for _, row := range rows {
+		d := models.Delivery{
+			ID:                row.ID,
+			SubscriptionID:    int64(row.SubscriptionID),
+			Channel:           row.Channel,
+			ScheduledFor:      row.ScheduledFor,
+			Status:            row.Status,
+			ProviderMessageID: row.ProviderMessageID.String,
+			Error:             row.Error.String,
+			CreatedAt:         row.CreatedAt,
+		}
+		if row.SentAt.Valid {
+			d.SentAt = &row.SentAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+func (s *DeliveryImpl) LastSentAt(ctx context.Context, subscriptionID int64) (*time.Time, error) {
+	sentAt, err := s.q.LastSentAt(ctx, int32(subscriptionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !sentAt.Valid {
+		return nil, nil
+	}
+	return &sentAt.Time, nil
+}
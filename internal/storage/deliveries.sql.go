@@ -0,0 +1,112 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: deliveries.sql
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	enums "github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+const createDelivery = `-- name: CreateDelivery :exec
+INSERT INTO deliveries (subscription_id, channel, scheduled_for, sent_at, status, provider_message_id, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateDeliveryParams struct {
+	SubscriptionID    int32
+	Channel           enums.Channel
+	ScheduledFor      time.Time
+	SentAt            sql.NullTime
+	Status            enums.DeliveryStatus
+	ProviderMessageID sql.NullString
+	Error             sql.NullString
+}
+
+func (q *Queries) CreateDelivery(ctx context.Context, arg CreateDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createDelivery,
+		arg.SubscriptionID,
+		arg.Channel,
+		arg.ScheduledFor,
+		arg.SentAt,
+		arg.Status,
+		arg.ProviderMessageID,
+		arg.Error,
+	)
+	return err
+}
+
+const lastSentAt = `-- name: LastSentAt :one
+SELECT sent_at FROM deliveries
+WHERE subscription_id = $1 AND status = 'sent'
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) LastSentAt(ctx context.Context, subscriptionID int32) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, lastSentAt, subscriptionID)
+	var sent_at sql.NullTime
+	err := row.Scan(&sent_at)
+	return sent_at, err
+}
+
+const listDeliveries = `-- name: ListDeliveries :many
+SELECT id, subscription_id, channel, scheduled_for, sent_at, status, provider_message_id, error, created_at FROM deliveries
+WHERE ($1::int = 0 OR subscription_id = $1::int)
+  AND ($2::text = '' OR status = $2::text)
+  AND ($3::bigint = 0 OR id < $3::bigint)
+ORDER BY id DESC
+LIMIT $4
+`
+
+type ListDeliveriesParams struct {
+	SubscriptionID int32
+	Status         string
+	BeforeID       int64
+	RowLimit       int32
+}
+
+// Zero subscription_id, empty status and zero before_id disable the
+// corresponding filter. Pages are walked with before_id, newest first.
+func (q *Queries) ListDeliveries(ctx context.Context, arg ListDeliveriesParams) ([]Delivery, error) {
+	rows, err := q.db.QueryContext(ctx, listDeliveries,
+		arg.SubscriptionID,
+		arg.Status,
+		arg.BeforeID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Delivery
+	for rows.Next() {
+		var i Delivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.Channel,
+			&i.ScheduledFor,
+			&i.SentAt,
+			&i.Status,
+			&i.ProviderMessageID,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+)
+
+// Constraint violations are reported as these errors, wrapping the driver
+// error so that the constraint name stays in the message.
+var (
+	// ErrDuplicate is returned when a write would break a unique key.
+	ErrDuplicate = errors.New("duplicate key")
+	// ErrMissingReference is returned when a write refers to a row that
+	// does not exist.
+	ErrMissingReference = errors.New("referenced row does not exist")
+	// ErrInvalidValue is returned when a value breaks a check or not-null
+	// constraint.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// Postgres error codes, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// mapError translates Postgres constraint violations into storage errors.
+func mapError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		return fmt.Errorf("%w: %w", ErrDuplicate, err)
+	case pgForeignKeyViolation:
+		return fmt.Errorf("%w: %w", ErrMissingReference, err)
+	case pgCheckViolation, pgNotNullViolation:
+		return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+	return err
+}
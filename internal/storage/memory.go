@@ -0,0 +1,651 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryDB keeps the data of the memory storages, for development and
+// tests. It enforces the same unique keys, references and checks as the
+// database schema and returns the same errors as the SQL storages. Nothing
+// survives a restart, and it suits a single instance only.
+type MemoryDB struct {
+	// mu is nil for the copy a transaction works on, which is guarded by
+	// the lock of its database.
+	mu   *sync.Mutex
+	data *memoryData
+	now  func() time.Time
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		mu:   &sync.Mutex{},
+		data: newMemoryData(),
+		now:  time.Now,
+	}
+}
+
+func NewMemoryStores(db *MemoryDB) Stores {
+	return Stores{
+		Subscriptions:   NewMemorySubscriptionStorage(db),
+		APIKeys:         NewMemoryAPIKeyStorage(db),
+		Deliveries:      NewMemoryDeliveryStorage(db),
+		Suppressions:    NewMemorySuppressionStorage(db),
+		Schedule:        NewMemoryScheduleStorage(db),
+		WebhookAttempts: NewMemoryWebhookAttemptStorage(db),
+	}
+}
+
+func (db *MemoryDB) run(fn func(d *memoryData) error) error {
+	if db.mu != nil {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+	}
+	return fn(db.data)
+}
+
+// memoryData holds the rows of every table.
+type memoryData struct {
+	lastSubID int64
+	subs      map[int64]models.Subscription
+	leases    map[int64]memoryLease
+
+	lastKeyID int64
+	keys      map[int64]memoryAPIKey
+	usage     map[memoryUsageKey]int64
+
+	lastDeliveryID int64
+	// deliveries and attempts are ordered by ID.
+	deliveries    []models.Delivery
+	lastAttemptID int64
+	attempts      []models.WebhookAttempt
+
+	suppressions map[string]models.Suppression
+}
+
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type memoryAPIKey struct {
+	key  models.APIKey
+	hash string
+}
+
+type memoryUsageKey struct {
+	keyID int64
+	day   string
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		subs:         make(map[int64]models.Subscription),
+		leases:       make(map[int64]memoryLease),
+		keys:         make(map[int64]memoryAPIKey),
+		usage:        make(map[memoryUsageKey]int64),
+		suppressions: make(map[string]models.Suppression),
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := *d
+	c.subs = maps.Clone(d.subs)
+	c.leases = maps.Clone(d.leases)
+	c.keys = maps.Clone(d.keys)
+	c.usage = maps.Clone(d.usage)
+	c.deliveries = slices.Clone(d.deliveries)
+	c.attempts = slices.Clone(d.attempts)
+	c.suppressions = maps.Clone(d.suppressions)
+	return &c
+}
+
+var _ UnitOfWork = (*MemoryUnitOfWorkImpl)(nil)
+
+// MemoryUnitOfWorkImpl runs fn on a copy of the data, which replaces the
+// data of the database on commit. Transactions run one at a time and block
+// other calls, so fn must only use the stores it is given.
+type MemoryUnitOfWorkImpl struct {
+	db *MemoryDB
+}
+
+func NewMemoryUnitOfWork(db *MemoryDB) *MemoryUnitOfWorkImpl {
+	return &MemoryUnitOfWorkImpl{db: db}
+}
+
+func (u *MemoryUnitOfWorkImpl) InTx(_ context.Context, fn func(stores Stores) error) error {
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+
+	tx := &MemoryDB{data: u.db.data.clone(), now: u.db.now}
+	if err := fn(NewMemoryStores(tx)); err != nil {
+		return err
+	}
+	u.db.data = tx.data
+	return nil
+}
+
+var _ SubscriptionStorage = (*MemorySubImpl)(nil)
+
+type MemorySubImpl struct {
+	db *MemoryDB
+}
+
+func NewMemorySubscriptionStorage(db *MemoryDB) *MemorySubImpl {
+	return &MemorySubImpl{db: db}
+}
+
+func (s *MemorySubImpl) Create(_ context.Context, sub models.Subscription) (models.Subscription, error) {
+	if !sub.Frequency.IsValid() || !sub.Units.IsValid() {
+		return models.Subscription{}, ErrInvalidValue
+	}
+
+	err := s.db.run(func(d *memoryData) error {
+		for _, existing := range d.subs {
+			if existing.Token == sub.Token ||
+				existing.Channel == sub.Channel && existing.Contact == sub.Contact && existing.City == sub.City {
+				return ErrDuplicate
+			}
+		}
+
+		d.lastSubID++
+		sub.ID = d.lastSubID
+		sub.CreatedAt = s.db.now()
+		sub.NextRunAt = sub.CreatedAt
+		sub.LastSentAt = nil
+		d.subs[sub.ID] = sub
+		return nil
+	})
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (s *MemorySubImpl) IsAlreadySubscribed(_ context.Context, channel enums.Channel, contact, city string) (bool, error) {
+	var exists bool
+	err := s.db.run(func(d *memoryData) error {
+		for _, sub := range d.subs {
+			if sub.Channel == channel && sub.Contact == contact && sub.City == city {
+				exists = true
+				break
+			}
+		}
+		return nil
+	})
+	return exists, err
+}
+
+func (s *MemorySubImpl) GetByToken(_ context.Context, token string) (models.Subscription, error) {
+	var sub models.Subscription
+	err := s.db.run(func(d *memoryData) error {
+		id, ok := d.subByToken(token)
+		if !ok {
+			return sql.ErrNoRows
+		}
+		sub = d.subs[id]
+		return nil
+	})
+	return sub, err
+}
+
+func (s *MemorySubImpl) GetByID(_ context.Context, id int64) (models.Subscription, error) {
+	var sub models.Subscription
+	err := s.db.run(func(d *memoryData) error {
+		var ok bool
+		if sub, ok = d.subs[id]; !ok {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	return sub, err
+}
+
+func (s *MemorySubImpl) ListByContact(_ context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+	return s.list(func(sub models.Subscription) bool {
+		return sub.Channel == channel && sub.Contact == contact
+	})
+}
+
+func (s *MemorySubImpl) ListByEmail(_ context.Context, email string) ([]models.Subscription, error) {
+	return s.list(func(sub models.Subscription) bool {
+		return sub.Channel == enums.ChannelEmail && strings.EqualFold(sub.Contact, email)
+	})
+}
+
+func (s *MemorySubImpl) ListDue(_ context.Context, now time.Time, limit int) ([]models.Subscription, error) {
+	subs := make([]models.Subscription, 0)
+	if limit <= 0 {
+		return subs, nil
+	}
+
+	err := s.db.run(func(d *memoryData) error {
+		subs = d.dueSubs(func(sub models.Subscription) bool {
+			return !sub.NextRunAt.After(now)
+		}, limit)
+		return nil
+	})
+	return subs, err
+}
+
+func (s *MemorySubImpl) Confirm(_ context.Context, token string) error {
+	return s.db.run(func(d *memoryData) error {
+		if id, ok := d.subByToken(token); ok {
+			sub := d.subs[id]
+			sub.Confirmed = true
+			d.subs[id] = sub
+		}
+		return nil
+	})
+}
+
+// Unsubscribe also deletes the deliveries and webhook attempts of the
+// subscription, as the foreign keys of the schema do.
+func (s *MemorySubImpl) Unsubscribe(_ context.Context, token string) error {
+	return s.db.run(func(d *memoryData) error {
+		id, ok := d.subByToken(token)
+		if !ok {
+			return nil
+		}
+		delete(d.subs, id)
+		delete(d.leases, id)
+		d.deliveries = slices.DeleteFunc(d.deliveries, func(del models.Delivery) bool {
+			return del.SubscriptionID == id
+		})
+		d.attempts = slices.DeleteFunc(d.attempts, func(a models.WebhookAttempt) bool {
+			return a.SubscriptionID == id
+		})
+		return nil
+	})
+}
+
+// list returns the subscriptions matching keep, ordered by ID.
+func (s *MemorySubImpl) list(keep func(sub models.Subscription) bool) ([]models.Subscription, error) {
+	subs := make([]models.Subscription, 0)
+	err := s.db.run(func(d *memoryData) error {
+		for _, sub := range d.subs {
+			if keep(sub) {
+				subs = append(subs, sub)
+			}
+		}
+		return nil
+	})
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, err
+}
+
+func (d *memoryData) subByToken(token string) (int64, bool) {
+	for id, sub := range d.subs {
+		if sub.Token == token {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// dueSubs returns up to limit confirmed subscriptions matching due, ordered
+// by next run and ID.
+func (d *memoryData) dueSubs(due func(sub models.Subscription) bool, limit int) []models.Subscription {
+	subs := make([]models.Subscription, 0)
+	for _, sub := range d.subs {
+		if sub.Confirmed && due(sub) {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].NextRunAt.Equal(subs[j].NextRunAt) {
+			return subs[i].NextRunAt.Before(subs[j].NextRunAt)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	if len(subs) > limit {
+		subs = subs[:limit]
+	}
+	return subs
+}
+
+var _ ScheduleStorage = (*MemoryScheduleImpl)(nil)
+
+type MemoryScheduleImpl struct {
+	db *MemoryDB
+}
+
+func NewMemoryScheduleStorage(db *MemoryDB) *MemoryScheduleImpl {
+	return &MemoryScheduleImpl{db: db}
+}
+
+func (s *MemoryScheduleImpl) ClaimDue(_ context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+	subs := make([]models.Subscription, 0)
+	if limit <= 0 {
+		return subs, nil
+	}
+
+	err := s.db.run(func(d *memoryData) error {
+		now := s.db.now()
+		subs = d.dueSubs(func(sub models.Subscription) bool {
+			l, leased := d.leases[sub.ID]
+			return !sub.NextRunAt.After(now) && (!leased || l.expiresAt.Before(now))
+		}, limit)
+		for _, sub := range subs {
+			d.leases[sub.ID] = memoryLease{owner: owner, expiresAt: now.Add(lease)}
+		}
+		return nil
+	})
+	return subs, err
+}
+
+func (s *MemoryScheduleImpl) Complete(_ context.Context, owner string, id int64, nextRunAt time.Time) error {
+	return s.db.run(func(d *memoryData) error {
+		if l, ok := d.leases[id]; !ok || l.owner != owner {
+			return sql.ErrNoRows
+		}
+		sub := d.subs[id]
+		sub.NextRunAt = nextRunAt
+		d.subs[id] = sub
+		delete(d.leases, id)
+		return nil
+	})
+}
+
+func (s *MemoryScheduleImpl) Release(_ context.Context, owner string, ids []int64) error {
+	return s.db.run(func(d *memoryData) error {
+		for _, id := range ids {
+			if l, ok := d.leases[id]; ok && l.owner == owner {
+				delete(d.leases, id)
+			}
+		}
+		return nil
+	})
+}
+
+var _ APIKeyStorage = (*MemoryAPIKeyImpl)(nil)
+
+// MemoryAPIKeyImpl counts usage per UTC day.
+type MemoryAPIKeyImpl struct {
+	db *MemoryDB
+}
+
+func NewMemoryAPIKeyStorage(db *MemoryDB) *MemoryAPIKeyImpl {
+	return &MemoryAPIKeyImpl{db: db}
+}
+
+func (s *MemoryAPIKeyImpl) Create(_ context.Context, key models.APIKey, keyHash string) (models.APIKey, error) {
+	if key.DailyQuota < 0 {
+		return models.APIKey{}, ErrInvalidValue
+	}
+
+	err := s.db.run(func(d *memoryData) error {
+		for _, existing := range d.keys {
+			if existing.hash == keyHash {
+				return ErrDuplicate
+			}
+		}
+
+		d.lastKeyID++
+		key = models.APIKey{
+			ID:         d.lastKeyID,
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			DailyQuota: key.DailyQuota,
+			CreatedAt:  s.db.now(),
+		}
+		d.keys[key.ID] = memoryAPIKey{key: key, hash: keyHash}
+		return nil
+	})
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (s *MemoryAPIKeyImpl) GetByHash(_ context.Context, keyHash string) (models.APIKey, error) {
+	var key models.APIKey
+	err := s.db.run(func(d *memoryData) error {
+		for _, k := range d.keys {
+			if k.hash == keyHash {
+				key = k.key
+				return nil
+			}
+		}
+		return sql.ErrNoRows
+	})
+	return key, err
+}
+
+func (s *MemoryAPIKeyImpl) List(_ context.Context) ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0)
+	err := s.db.run(func(d *memoryData) error {
+		day := today(s.db.now())
+		for _, k := range d.keys {
+			key := k.key
+			key.RequestsToday = d.usage[memoryUsageKey{keyID: key.ID, day: day}]
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, err
+}
+
+// Revoke returns sql.ErrNoRows if the key does not exist or is already revoked.
+func (s *MemoryAPIKeyImpl) Revoke(_ context.Context, id int64) error {
+	return s.db.run(func(d *memoryData) error {
+		k, ok := d.keys[id]
+		if !ok || k.key.Revoked() {
+			return sql.ErrNoRows
+		}
+		revokedAt := s.db.now()
+		k.key.RevokedAt = &revokedAt
+		d.keys[id] = k
+		return nil
+	})
+}
+
+func (s *MemoryAPIKeyImpl) TrackUsage(_ context.Context, id int64) (int64, error) {
+	var used int64
+	err := s.db.run(func(d *memoryData) error {
+		k, ok := d.keys[id]
+		if !ok {
+			return ErrMissingReference
+		}
+		usedAt := s.db.now()
+		k.key.LastUsedAt = &usedAt
+		d.keys[id] = k
+
+		usage := memoryUsageKey{keyID: id, day: today(usedAt)}
+		if k.key.DailyQuota != 0 && d.usage[usage] >= int64(k.key.DailyQuota) {
+			return ErrQuotaExceeded
+		}
+		d.usage[usage]++
+		used = d.usage[usage]
+		return nil
+	})
+	return used, err
+}
+
+func today(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+var _ DeliveryStorage = (*MemoryDeliveryImpl)(nil)
+
+type MemoryDeliveryImpl struct {
+	db *MemoryDB
+}
+
+func NewMemoryDeliveryStorage(db *MemoryDB) *MemoryDeliveryImpl {
+	return &MemoryDeliveryImpl{db: db}
+}
+
+func (s *MemoryDeliveryImpl) Record(_ context.Context, del models.Delivery) error {
+	if !del.Status.IsValid() {
+		return ErrInvalidValue
+	}
+
+	return s.db.run(func(d *memoryData) error {
+		if _, ok := d.subs[del.SubscriptionID]; !ok {
+			return ErrMissingReference
+		}
+
+		d.lastDeliveryID++
+		del.ID = d.lastDeliveryID
+		del.CreatedAt = s.db.now()
+		d.deliveries = append(d.deliveries, del)
+		return nil
+	})
+}
+
+func (s *MemoryDeliveryImpl) List(_ context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+	deliveries := make([]models.Delivery, 0)
+	err := s.db.run(func(d *memoryData) error {
+		for i := len(d.deliveries) - 1; i >= 0 && len(deliveries) < filter.Limit; i-- {
+			del := d.deliveries[i]
+			if (filter.SubscriptionID == 0 || del.SubscriptionID == filter.SubscriptionID) &&
+				(filter.Status == "" || del.Status == filter.Status) &&
+				(filter.BeforeID == 0 || del.ID < filter.BeforeID) {
+				deliveries = append(deliveries, del)
+			}
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+func (s *MemoryDeliveryImpl) LastSentAt(_ context.Context, subscriptionID int64) (*time.Time, error) {
+	var sentAt *time.Time
+	err := s.db.run(func(d *memoryData) error {
+		for i := len(d.deliveries) - 1; i >= 0; i-- {
+			del := d.deliveries[i]
+			if del.SubscriptionID == subscriptionID && del.Status == enums.DeliveryStatusSent {
+				sentAt = del.SentAt
+				break
+			}
+		}
+		return nil
+	})
+	return sentAt, err
+}
+
+var _ SuppressionStorage = (*MemorySuppressionImpl)(nil)
+
+type MemorySuppressionImpl struct {
+	db *MemoryDB
+}
+
+func NewMemorySuppressionStorage(db *MemoryDB) *MemorySuppressionImpl {
+	return &MemorySuppressionImpl{db: db}
+}
+
+// Suppress stores the address lowercased. A complaint overrides an earlier
+// bounce.
+func (s *MemorySuppressionImpl) Suppress(_ context.Context, sup models.Suppression) error {
+	if sup.Reason != enums.SuppressionReasonHardBounce && sup.Reason != enums.SuppressionReasonComplaint {
+		return ErrInvalidValue
+	}
+
+	return s.db.run(func(d *memoryData) error {
+		email := strings.ToLower(sup.Email)
+		existing, ok := d.suppressions[email]
+		switch {
+		case !ok:
+			existing = models.Suppression{Email: email, CreatedAt: s.db.now()}
+		case existing.Reason == enums.SuppressionReasonComplaint:
+			return nil
+		}
+		existing.Reason = sup.Reason
+		existing.Detail = sup.Detail
+		d.suppressions[email] = existing
+		return nil
+	})
+}
+
+func (s *MemorySuppressionImpl) List(_ context.Context, limit int) ([]models.Suppression, error) {
+	suppressions := make([]models.Suppression, 0)
+	err := s.db.run(func(d *memoryData) error {
+		for _, sup := range d.suppressions {
+			suppressions = append(suppressions, sup)
+		}
+		return nil
+	})
+	sort.Slice(suppressions, func(i, j int) bool {
+		if !suppressions[i].CreatedAt.Equal(suppressions[j].CreatedAt) {
+			return suppressions[i].CreatedAt.After(suppressions[j].CreatedAt)
+		}
+		return suppressions[i].Email < suppressions[j].Email
+	})
+	if len(suppressions) > max(limit, 0) {
+		suppressions = suppressions[:max(limit, 0)]
+	}
+	return suppressions, err
+}
+
+func (s *MemorySuppressionImpl) Remove(_ context.Context, email string) error {
+	return s.db.run(func(d *memoryData) error {
+		email = strings.ToLower(email)
+		if _, ok := d.suppressions[email]; !ok {
+			return sql.ErrNoRows
+		}
+		delete(d.suppressions, email)
+		return nil
+	})
+}
+
+func (s *MemorySuppressionImpl) Suppressed(_ context.Context, emails []string) (map[string]bool, error) {
+	suppressed := make(map[string]bool)
+	err := s.db.run(func(d *memoryData) error {
+		for _, email := range emails {
+			email = strings.ToLower(email)
+			if _, ok := d.suppressions[email]; ok {
+				suppressed[email] = true
+			}
+		}
+		return nil
+	})
+	return suppressed, err
+}
+
+var _ WebhookAttemptStorage = (*MemoryWebhookAttemptImpl)(nil)
+
+type MemoryWebhookAttemptImpl struct {
+	db *MemoryDB
+}
+
+func NewMemoryWebhookAttemptStorage(db *MemoryDB) *MemoryWebhookAttemptImpl {
+	return &MemoryWebhookAttemptImpl{db: db}
+}
+
+func (s *MemoryWebhookAttemptImpl) LogAttempt(_ context.Context, a models.WebhookAttempt) error {
+	return s.db.run(func(d *memoryData) error {
+		if _, ok := d.subs[a.SubscriptionID]; !ok {
+			return ErrMissingReference
+		}
+
+		d.lastAttemptID++
+		a.ID = d.lastAttemptID
+		a.Duration = a.Duration.Truncate(time.Millisecond)
+		a.CreatedAt = s.db.now()
+		d.attempts = append(d.attempts, a)
+		return nil
+	})
+}
+
+func (s *MemoryWebhookAttemptImpl) ListAttempts(_ context.Context, subscriptionID int64, limit int) ([]models.WebhookAttempt, error) {
+	attempts := make([]models.WebhookAttempt, 0)
+	err := s.db.run(func(d *memoryData) error {
+		for i := len(d.attempts) - 1; i >= 0 && len(attempts) < limit; i-- {
+			if d.attempts[i].SubscriptionID == subscriptionID {
+				attempts = append(attempts, d.attempts[i])
+			}
+		}
+		return nil
+	})
+	return attempts, err
+}
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN units TEXT NOT NULL DEFAULT 'metric' CHECK (units IN ('metric', 'imperial')),
+    ADD COLUMN lang TEXT NOT NULL DEFAULT 'en';
+
+-- +goose Down
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS lang,
+    DROP COLUMN IF EXISTS units;
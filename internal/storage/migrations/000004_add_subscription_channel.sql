@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE subscriptions RENAME COLUMN email TO contact;
+ALTER TABLE subscriptions
+    ADD COLUMN channel TEXT NOT NULL DEFAULT 'email',
+    ADD CONSTRAINT subscriptions_channel_check CHECK (channel IN ('email', 'telegram')),
+    DROP CONSTRAINT subscriptions_email_city_key,
+    ADD CONSTRAINT subscriptions_channel_contact_city_key UNIQUE (channel, contact, city);
+
+-- +goose Down
+DELETE FROM subscriptions WHERE channel <> 'email';
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_channel_contact_city_key,
+    DROP CONSTRAINT subscriptions_channel_check,
+    DROP COLUMN channel;
+ALTER TABLE subscriptions RENAME COLUMN contact TO email;
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_email_city_key UNIQUE (email, city);
@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN webhook_secret TEXT,
+    DROP CONSTRAINT subscriptions_channel_check,
+    ADD CONSTRAINT subscriptions_channel_check CHECK (channel IN ('email', 'telegram', 'webhook'));
+
+CREATE TABLE webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    delivery_id TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_delivery_attempts_subscription_id_idx
+    ON webhook_delivery_attempts (subscription_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE webhook_delivery_attempts;
+DELETE FROM subscriptions WHERE channel = 'webhook';
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_channel_check,
+    ADD CONSTRAINT subscriptions_channel_check CHECK (channel IN ('email', 'telegram')),
+    DROP COLUMN webhook_secret;
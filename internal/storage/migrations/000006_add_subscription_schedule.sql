@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN next_run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN lease_owner TEXT,
+    ADD COLUMN lease_expires_at TIMESTAMPTZ;
+
+CREATE INDEX subscriptions_due_idx ON subscriptions (next_run_at) WHERE confirmed;
+
+-- +goose Down
+DROP INDEX subscriptions_due_idx;
+ALTER TABLE subscriptions
+    DROP COLUMN lease_expires_at,
+    DROP COLUMN lease_owner,
+    DROP COLUMN next_run_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed')),
+    provider_message_id TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX deliveries_subscription_id_idx ON deliveries (subscription_id, id DESC);
+
+-- +goose Down
+DROP TABLE deliveries;
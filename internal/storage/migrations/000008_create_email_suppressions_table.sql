@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE email_suppressions (
+    email TEXT PRIMARY KEY,
+    reason TEXT NOT NULL CHECK (reason IN ('hard_bounce', 'complaint')),
+    detail TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE email_suppressions;
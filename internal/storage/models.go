@@ -5,17 +5,72 @@
 package storage
 
 import (
+	"database/sql"
 	"time"
 
 	enums "github.com/serjnester/weather-subscription-service/domain/enums"
 )
 
-type Subscription struct {
-	ID        int32
+type ApiKey struct {
+	ID         int32
+	Name       string
+	Prefix     string
+	KeyHash    string
+	DailyQuota int32
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+}
+
+type ApiKeyUsage struct {
+	ApiKeyID     int32
+	Day          time.Time
+	RequestCount int64
+}
+
+type Delivery struct {
+	ID                int64
+	SubscriptionID    int32
+	Channel           enums.Channel
+	ScheduledFor      time.Time
+	SentAt            sql.NullTime
+	Status            enums.DeliveryStatus
+	ProviderMessageID sql.NullString
+	Error             sql.NullString
+	CreatedAt         time.Time
+}
+
+type EmailSuppression struct {
 	Email     string
-	City      string
-	Frequency enums.Frequency
-	Confirmed bool
-	Token     string
+	Reason    enums.SuppressionReason
+	Detail    sql.NullString
 	CreatedAt time.Time
 }
+
+type Subscription struct {
+	ID             int32
+	Contact        string
+	City           string
+	Frequency      enums.Frequency
+	Confirmed      bool
+	Token          string
+	CreatedAt      time.Time
+	Units          enums.Units
+	Lang           enums.Language
+	Channel        enums.Channel
+	WebhookSecret  sql.NullString
+	NextRunAt      time.Time
+	LeaseOwner     sql.NullString
+	LeaseExpiresAt sql.NullTime
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             int64
+	SubscriptionID int32
+	DeliveryID     string
+	Attempt        int32
+	StatusCode     sql.NullInt32
+	Error          sql.NullString
+	DurationMs     int32
+	CreatedAt      time.Time
+}
@@ -0,0 +1,36 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, daily_quota)
+VALUES ($1, $2, $3, $4)
+    RETURNING *;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1;
+
+-- name: ListAPIKeys :many
+SELECT k.id, k.name, k.prefix, k.daily_quota, k.last_used_at, k.revoked_at, k.created_at,
+       COALESCE(u.request_count, 0)::BIGINT AS requests_today
+FROM api_keys k
+    LEFT JOIN api_key_usage u ON u.api_key_id = k.id AND u.day = CURRENT_DATE
+ORDER BY k.id;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: TrackAPIKeyUsage :one
+-- Counts a request unless the key already made daily_quota requests today
+-- (0 is unlimited). No row is returned for a request over the quota.
+WITH touched AS (
+    UPDATE api_keys
+    SET last_used_at = now()
+    WHERE id = $1
+)
+INSERT INTO api_key_usage (api_key_id, day, request_count)
+VALUES ($1, CURRENT_DATE, 1)
+ON CONFLICT (api_key_id, day) DO UPDATE
+    SET request_count = api_key_usage.request_count + 1
+    WHERE (SELECT k.daily_quota = 0 OR api_key_usage.request_count < k.daily_quota
+           FROM api_keys k WHERE k.id = excluded.api_key_id)
+    RETURNING request_count;
@@ -0,0 +1,19 @@
+-- name: CreateDelivery :exec
+INSERT INTO deliveries (subscription_id, channel, scheduled_for, sent_at, status, provider_message_id, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListDeliveries :many
+-- Zero subscription_id, empty status and zero before_id disable the
+-- corresponding filter. Pages are walked with before_id, newest first.
+SELECT * FROM deliveries
+WHERE (sqlc.arg(subscription_id)::int = 0 OR subscription_id = sqlc.arg(subscription_id)::int)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+  AND (sqlc.arg(before_id)::bigint = 0 OR id < sqlc.arg(before_id)::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: LastSentAt :one
+SELECT sent_at FROM deliveries
+WHERE subscription_id = $1 AND status = 'sent'
+ORDER BY id DESC
+LIMIT 1;
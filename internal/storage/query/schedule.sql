@@ -0,0 +1,31 @@
+-- name: ClaimDueSubscriptions :many
+-- Leases up to batch_size due subscriptions to owner. SKIP LOCKED lets
+-- concurrent workers claim disjoint batches; expired leases are reclaimed.
+WITH due AS (
+    SELECT id FROM subscriptions
+    WHERE confirmed
+      AND next_run_at <= now()
+      AND (lease_expires_at IS NULL OR lease_expires_at < now())
+    ORDER BY next_run_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE subscriptions s
+SET lease_owner = sqlc.arg(owner)::text,
+    lease_expires_at = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+FROM due
+WHERE s.id = due.id
+RETURNING s.*;
+
+-- name: CompleteSubscriptionRun :execrows
+UPDATE subscriptions
+SET next_run_at = sqlc.arg(next_run_at),
+    lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = sqlc.arg(id) AND lease_owner = sqlc.arg(owner)::text;
+
+-- name: ReleaseSubscriptionLeases :exec
+UPDATE subscriptions
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = ANY(sqlc.arg(ids)::int[]) AND lease_owner = sqlc.arg(owner)::text;
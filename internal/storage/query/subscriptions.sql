@@ -1,12 +1,16 @@
 -- name: CreateSubscription :one
-INSERT INTO subscriptions (email, city, frequency, token)
-VALUES ($1, $2, $3, $4)
+INSERT INTO subscriptions (channel, contact, city, frequency, token, units, lang, confirmed, webhook_secret)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
     RETURNING *;
 
 -- name: GetSubscriptionByToken :one
 SELECT * FROM subscriptions
 WHERE token = $1;
 
+-- name: GetSubscriptionByID :one
+SELECT * FROM subscriptions
+WHERE id = $1;
+
 -- name: ConfirmSubscription :exec
 UPDATE subscriptions
 SET confirmed = true
@@ -18,4 +22,20 @@ WHERE token = $1;
 
 -- name: IsAlreadySubscribed :one
 SELECT COUNT(*) FROM subscriptions
-WHERE email = $1 AND city = $2;
\ No newline at end of file
+WHERE channel = $1 AND contact = $2 AND city = $3;
+
+-- name: ListSubscriptionsByContact :many
+SELECT * FROM subscriptions
+WHERE channel = $1 AND contact = $2
+ORDER BY id;
+
+-- name: ListSubscriptionsByEmail :many
+SELECT * FROM subscriptions
+WHERE channel = 'email' AND lower(contact) = lower(sqlc.arg(email))
+ORDER BY id;
+
+-- name: ListDueSubscriptions :many
+SELECT * FROM subscriptions
+WHERE confirmed AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at, id
+LIMIT sqlc.arg(batch_size);
@@ -0,0 +1,22 @@
+-- name: UpsertSuppression :exec
+-- Emails are stored lowercased. A complaint overrides an earlier bounce.
+INSERT INTO email_suppressions (email, reason, detail)
+VALUES (lower(sqlc.arg(email)::text), sqlc.arg(reason), sqlc.arg(detail))
+ON CONFLICT (email) DO UPDATE
+SET reason = EXCLUDED.reason,
+    detail = EXCLUDED.detail
+WHERE email_suppressions.reason <> 'complaint';
+
+-- name: ListSuppressions :many
+SELECT * FROM email_suppressions
+ORDER BY created_at DESC, email
+LIMIT $1;
+
+-- name: DeleteSuppression :execrows
+DELETE FROM email_suppressions
+WHERE email = lower(sqlc.arg(email)::text);
+
+-- name: SuppressedEmails :many
+-- Returns the lowercased emails among the given ones that are suppressed.
+SELECT email FROM email_suppressions
+WHERE email = ANY(sqlc.arg(emails)::text[]);
@@ -0,0 +1,9 @@
+-- name: LogWebhookAttempt :exec
+INSERT INTO webhook_delivery_attempts (subscription_id, delivery_id, attempt, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListWebhookAttempts :many
+SELECT * FROM webhook_delivery_attempts
+WHERE subscription_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"time"
+)
+
+// ScheduleStorage hands out due subscriptions to workers. A claimed
+// subscription is leased to its owner until Complete or Release; if the
+// owner dies, the lease expires and another worker claims it.
+type ScheduleStorage interface {
+	ClaimDue(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error)
+	// Complete schedules the next run and drops the lease. It returns
+	// sql.ErrNoRows if owner no longer holds the lease.
+	Complete(ctx context.Context, owner string, id int64, nextRunAt time.Time) error
+	// Release drops leases without rescheduling, so the subscriptions are
+	// claimed again on the next cycle.
+	Release(ctx context.Context, owner string, ids []int64) error
+}
+
+var _ ScheduleStorage = (*ScheduleImpl)(nil)
+
+type ScheduleImpl struct {
+	q *Queries
+}
+
+func NewScheduleStorage(q *Queries) *ScheduleImpl {
+	return &ScheduleImpl{q: q}
+}
+
+func (s *ScheduleImpl) ClaimDue(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+	dbSubs, err := s.q.ClaimDueSubscriptions(ctx, ClaimDueSubscriptionsParams{
+		Owner:        owner,
+		LeaseSeconds: int32(lease.Seconds()),
+		BatchSize:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *ScheduleImpl) Complete(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+	affected, err := s.q.CompleteSubscriptionRun(ctx, CompleteSubscriptionRunParams{
+		NextRunAt: nextRunAt,
+		ID:        int32(id),
+		Owner:     owner,
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *ScheduleImpl) Release(ctx context.Context, owner string, ids []int64) error {
+	dbIDs := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		dbIDs = append(dbIDs, int32(id))
+	}
+	return s.q.ReleaseSubscriptionLeases(ctx, ReleaseSubscriptionLeasesParams{
+		Ids:   dbIDs,
+		Owner: owner,
+	})
+}
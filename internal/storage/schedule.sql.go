@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: schedule.sql
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const claimDueSubscriptions = `-- name: ClaimDueSubscriptions :many
+WITH due AS (
+    SELECT id FROM subscriptions
+    WHERE confirmed
+      AND next_run_at <= now()
+      AND (lease_expires_at IS NULL OR lease_expires_at < now())
+    ORDER BY next_run_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE subscriptions s
+SET lease_owner = $1::text,
+    lease_expires_at = now() + $2::int * interval '1 second'
+FROM due
+WHERE s.id = due.id
+RETURNING s.id, s.contact, s.city, s.frequency, s.confirmed, s.token, s.created_at, s.units, s.lang, s.channel, s.webhook_secret, s.next_run_at, s.lease_owner, s.lease_expires_at
+`
+
+type ClaimDueSubscriptionsParams struct {
+	Owner        string
+	LeaseSeconds int32
+	BatchSize    int32
+}
+
+// Leases up to batch_size due subscriptions to owner. SKIP LOCKED lets
+// concurrent workers claim disjoint batches; expired leases are reclaimed.
+func (q *Queries) ClaimDueSubscriptions(ctx context.Context, arg ClaimDueSubscriptionsParams) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueSubscriptions, arg.Owner, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeSubscriptionRun = `-- name: CompleteSubscriptionRun :execrows
+UPDATE subscriptions
+SET next_run_at = $1,
+    lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = $2 AND lease_owner = $3::text
+`
+
+type CompleteSubscriptionRunParams struct {
+	NextRunAt time.Time
+	ID        int32
+	Owner     string
+}
+
+func (q *Queries) CompleteSubscriptionRun(ctx context.Context, arg CompleteSubscriptionRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, completeSubscriptionRun, arg.NextRunAt, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const releaseSubscriptionLeases = `-- name: ReleaseSubscriptionLeases :exec
+UPDATE subscriptions
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = ANY($1::int[]) AND lease_owner = $2::text
+`
+
+type ReleaseSubscriptionLeasesParams struct {
+	Ids   []int32
+	Owner string
+}
+
+func (q *Queries) ReleaseSubscriptionLeases(ctx context.Context, arg ReleaseSubscriptionLeasesParams) error {
+	_, err := q.db.ExecContext(ctx, releaseSubscriptionLeases, pq.Array(arg.Ids), arg.Owner)
+	return err
+}
@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"time"
+)
+
+var _ storage.APIKeyStorage = (*APIKeyImpl)(nil)
+
+// APIKeyImpl counts usage per UTC day.
+type APIKeyImpl struct {
+	q *Queries
+}
+
+func NewAPIKeyStorage(q *Queries) *APIKeyImpl {
+	return &APIKeyImpl{q: q}
+}
+
+func (s *APIKeyImpl) Create(ctx context.Context, key models.APIKey, keyHash string) (models.APIKey, error) {
+	dbKey, err := s.q.CreateAPIKey(ctx, CreateAPIKeyParams{
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    keyHash,
+		DailyQuota: int64(key.DailyQuota),
+		CreatedAt:  now(),
+	})
+	if err != nil {
+		return models.APIKey{}, mapError(err)
+	}
+
+	return apiKeyFromDB(dbKey), nil
+}
+
+func (s *APIKeyImpl) GetByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	dbKey, err := s.q.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	return apiKeyFromDB(dbKey), nil
+}
+
+func (s *APIKeyImpl) List(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := s.q.ListAPIKeys(ctx, now().Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]models.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, models.APIKey{
+			ID:            row.ID,
+			Name:          row.Name,
+			Prefix:        row.Prefix,
+			DailyQuota:    int(row.DailyQuota),
+			RequestsToday: row.RequestsToday,
+			LastUsedAt:    nullTime(row.LastUsedAt),
+			RevokedAt:     nullTime(row.RevokedAt),
+			CreatedAt:     row.CreatedAt,
+		})
+	}
+
+	return keys, nil
+}
+
+// Revoke returns sql.ErrNoRows if the key does not exist or is already revoked.
+func (s *APIKeyImpl) Revoke(ctx context.Context, id int64) error {
+	affected, err := s.q.RevokeAPIKey(ctx, RevokeAPIKeyParams{
+		Now: sql.NullTime{Time: now(), Valid: true},
+		ID:  id,
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *APIKeyImpl) TrackUsage(ctx context.Context, id int64) (int64, error) {
+	usedAt := now()
+	if err := s.q.TouchAPIKey(ctx, TouchAPIKeyParams{
+		Now: sql.NullTime{Time: usedAt, Valid: true},
+		ID:  id,
+	}); err != nil {
+		return 0, err
+	}
+	used, err := s.q.TrackAPIKeyUsage(ctx, TrackAPIKeyUsageParams{
+		ApiKeyID: id,
+		Day:      usedAt.Format(time.DateOnly),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, storage.ErrQuotaExceeded
+	}
+	return used, mapError(err)
+}
+
+func apiKeyFromDB(k ApiKey) models.APIKey {
+	return models.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		DailyQuota: int(k.DailyQuota),
+		LastUsedAt: nullTime(k.LastUsedAt),
+		RevokedAt:  nullTime(k.RevokedAt),
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
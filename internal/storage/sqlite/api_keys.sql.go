@@ -0,0 +1,179 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: api_keys.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, prefix, key_hash, daily_quota, created_at)
+VALUES (?, ?, ?, ?, ?)
+    RETURNING id, name, prefix, key_hash, daily_quota, last_used_at, revoked_at, created_at
+`
+
+type CreateAPIKeyParams struct {
+	Name       string
+	Prefix     string
+	KeyHash    string
+	DailyQuota int64
+	CreatedAt  time.Time
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, createAPIKey,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.DailyQuota,
+		arg.CreatedAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.DailyQuota,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
+SELECT id, name, prefix, key_hash, daily_quota, last_used_at, revoked_at, created_at FROM api_keys
+WHERE key_hash = ?
+`
+
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.DailyQuota,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT k.id, k.name, k.prefix, k.daily_quota, k.last_used_at, k.revoked_at, k.created_at,
+       CAST(COALESCE(u.request_count, 0) AS INTEGER) AS requests_today
+FROM api_keys k
+    LEFT JOIN api_key_usage u ON u.api_key_id = k.id AND u.day = ?1
+ORDER BY k.id
+`
+
+type ListAPIKeysRow struct {
+	ID            int64
+	Name          string
+	Prefix        string
+	DailyQuota    int64
+	LastUsedAt    sql.NullTime
+	RevokedAt     sql.NullTime
+	CreatedAt     time.Time
+	RequestsToday int64
+}
+
+func (q *Queries) ListAPIKeys(ctx context.Context, day string) ([]ListAPIKeysRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeys, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAPIKeysRow
+	for rows.Next() {
+		var i ListAPIKeysRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Prefix,
+			&i.DailyQuota,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+			&i.RequestsToday,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = ?1
+WHERE id = ?2 AND revoked_at IS NULL
+`
+
+type RevokeAPIKeyParams struct {
+	Now sql.NullTime
+	ID  int64
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeAPIKey, arg.Now, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = ?1
+WHERE id = ?2
+`
+
+type TouchAPIKeyParams struct {
+	Now sql.NullTime
+	ID  int64
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKey, arg.Now, arg.ID)
+	return err
+}
+
+const trackAPIKeyUsage = `-- name: TrackAPIKeyUsage :one
+INSERT INTO api_key_usage (api_key_id, day, request_count)
+VALUES (?, ?, 1)
+ON CONFLICT (api_key_id, day) DO UPDATE
+    SET request_count = api_key_usage.request_count + 1
+    WHERE (SELECT k.daily_quota = 0 OR api_key_usage.request_count < k.daily_quota
+           FROM api_keys k WHERE k.id = excluded.api_key_id)
+    RETURNING request_count
+`
+
+type TrackAPIKeyUsageParams struct {
+	ApiKeyID int64
+	Day      string
+}
+
+// Counts a request unless the key already made daily_quota requests today
+// (0 is unlimited). No row is returned for a request over the quota.
+func (q *Queries) TrackAPIKeyUsage(ctx context.Context, arg TrackAPIKeyUsageParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, trackAPIKeyUsage, arg.ApiKeyID, arg.Day)
+	var request_count int64
+	err := row.Scan(&request_count)
+	return request_count, err
+}
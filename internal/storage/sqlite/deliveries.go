@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"time"
+)
+
+var _ storage.DeliveryStorage = (*DeliveryImpl)(nil)
+
+type DeliveryImpl struct {
+	q *Queries
+}
+
+func NewDeliveryStorage(q *Queries) *DeliveryImpl {
+	return &DeliveryImpl{q: q}
+}
+
+func (s *DeliveryImpl) Record(ctx context.Context, d models.Delivery) error {
+	var sentAt sql.NullTime
+	if d.SentAt != nil {
+		sentAt = sql.NullTime{Time: d.SentAt.UTC(), Valid: true}
+	}
+	err := s.q.CreateDelivery(ctx, CreateDeliveryParams{
+		SubscriptionID:    d.SubscriptionID,
+		Channel:           d.Channel,
+		ScheduledFor:      d.ScheduledFor.UTC(),
+		SentAt:            sentAt,
+		Status:            d.Status,
+		ProviderMessageID: sql.NullString{String: d.ProviderMessageID, Valid: d.ProviderMessageID != ""},
+		Error:             sql.NullString{String: d.Error, Valid: d.Error != ""},
+		CreatedAt:         now(),
+	})
+	return mapError(err)
+}
+
+func (s *DeliveryImpl) List(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+	rows, err := s.q.ListDeliveries(ctx, ListDeliveriesParams{
+		SubscriptionID: filter.SubscriptionID,
+		Status:         filter.Status.String(),
+		BeforeID:       filter.BeforeID,
+		RowLimit:       int64(filter.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.Delivery, 0, len(rows))
+	for _, row := range rows {
+		d := models.Delivery{
+			ID:                row.ID,
+			SubscriptionID:    row.SubscriptionID,
+			Channel:           row.Channel,
+			ScheduledFor:      row.ScheduledFor,
+			Status:            row.Status,
+			ProviderMessageID: row.ProviderMessageID.String,
+			Error:             row.Error.String,
+			CreatedAt:         row.CreatedAt,
+		}
+		if row.SentAt.Valid {
+			d.SentAt = &row.SentAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+func (s *DeliveryImpl) LastSentAt(ctx context.Context, subscriptionID int64) (*time.Time, error) {
+	sentAt, err := s.q.LastSentAt(ctx, subscriptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !sentAt.Valid {
+		return nil, nil
+	}
+	return &sentAt.Time, nil
+}
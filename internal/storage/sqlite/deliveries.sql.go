@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: deliveries.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	enums "github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+const createDelivery = `-- name: CreateDelivery :exec
+INSERT INTO deliveries (subscription_id, channel, scheduled_for, sent_at, status, provider_message_id, error, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateDeliveryParams struct {
+	SubscriptionID    int64
+	Channel           enums.Channel
+	ScheduledFor      time.Time
+	SentAt            sql.NullTime
+	Status            enums.DeliveryStatus
+	ProviderMessageID sql.NullString
+	Error             sql.NullString
+	CreatedAt         time.Time
+}
+
+func (q *Queries) CreateDelivery(ctx context.Context, arg CreateDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, createDelivery,
+		arg.SubscriptionID,
+		arg.Channel,
+		arg.ScheduledFor,
+		arg.SentAt,
+		arg.Status,
+		arg.ProviderMessageID,
+		arg.Error,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const lastSentAt = `-- name: LastSentAt :one
+SELECT sent_at FROM deliveries
+WHERE subscription_id = ? AND status = 'sent'
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) LastSentAt(ctx context.Context, subscriptionID int64) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, lastSentAt, subscriptionID)
+	var sent_at sql.NullTime
+	err := row.Scan(&sent_at)
+	return sent_at, err
+}
+
+const listDeliveries = `-- name: ListDeliveries :many
+SELECT id, subscription_id, channel, scheduled_for, sent_at, status, provider_message_id, error, created_at FROM deliveries
+WHERE (CAST(?1 AS INTEGER) = 0 OR subscription_id = ?1)
+  AND (CAST(?2 AS TEXT) = '' OR status = ?2)
+  AND (CAST(?3 AS INTEGER) = 0 OR id < ?3)
+ORDER BY id DESC
+LIMIT ?4
+`
+
+type ListDeliveriesParams struct {
+	SubscriptionID int64
+	Status         string
+	BeforeID       int64
+	RowLimit       int64
+}
+
+// Zero subscription_id, empty status and zero before_id disable the
+// corresponding filter. Pages are walked with before_id, newest first.
+func (q *Queries) ListDeliveries(ctx context.Context, arg ListDeliveriesParams) ([]Delivery, error) {
+	rows, err := q.db.QueryContext(ctx, listDeliveries,
+		arg.SubscriptionID,
+		arg.Status,
+		arg.BeforeID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Delivery
+	for rows.Next() {
+		var i Delivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.Channel,
+			&i.ScheduledFor,
+			&i.SentAt,
+			&i.Status,
+			&i.ProviderMessageID,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
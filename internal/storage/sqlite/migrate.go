@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+const (
+	gooseUp   = "-- +goose Up"
+	gooseDown = "-- +goose Down"
+)
+
+// Migrate applies the embedded migrations newer than the database's
+// user_version, each in its own transaction. Files keep the goose layout
+// shared with the Postgres migrations; only the Up section is run.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	var current int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&current); err != nil {
+		return fmt.Errorf("[sqlite.Migrate] read version: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("[sqlite.Migrate] %w", err)
+	}
+	for _, name := range names {
+		version, err := strconv.Atoi(strings.SplitN(path.Base(name), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("[sqlite.Migrate] %s: bad version: %w", name, err)
+		}
+		if version <= current {
+			continue
+		}
+
+		content, err := migrations.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("[sqlite.Migrate] %w", err)
+		}
+		if err := migrate(ctx, db, version, upSection(string(content))); err != nil {
+			return fmt.Errorf("[sqlite.Migrate] %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func migrate(ctx context.Context, db *sql.DB, version int, statements string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return err
+	}
+	// PRAGMA does not take parameters; version is an integer.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// upSection returns the part of a goose migration between the Up and Down
+// annotations.
+func upSection(content string) string {
+	if i := strings.Index(content, gooseUp); i >= 0 {
+		content = content[i+len(gooseUp):]
+	}
+	if i := strings.Index(content, gooseDown); i >= 0 {
+		content = content[:i]
+	}
+	return content
+}
@@ -0,0 +1,83 @@
+-- +goose Up
+-- Times are stored as UTC text in the format written by the driver, so
+-- they compare correctly as strings.
+CREATE TABLE subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    contact TEXT NOT NULL,
+    city TEXT NOT NULL,
+    frequency TEXT NOT NULL CHECK (frequency IN ('hourly', 'daily')),
+    confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    token TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    units TEXT NOT NULL DEFAULT 'metric' CHECK (units IN ('metric', 'imperial')),
+    lang TEXT NOT NULL DEFAULT 'en',
+    channel TEXT NOT NULL DEFAULT 'email' CHECK (channel IN ('email', 'telegram', 'webhook')),
+    webhook_secret TEXT,
+    next_run_at DATETIME NOT NULL,
+    lease_owner TEXT,
+    lease_expires_at DATETIME,
+    UNIQUE (channel, contact, city)
+);
+
+CREATE INDEX subscriptions_due_idx ON subscriptions (next_run_at) WHERE confirmed;
+
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    daily_quota INTEGER NOT NULL DEFAULT 0 CHECK (daily_quota >= 0),
+    last_used_at DATETIME,
+    revoked_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE api_key_usage (
+    api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day TEXT NOT NULL,
+    request_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
+
+CREATE TABLE webhook_delivery_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    delivery_id TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX webhook_delivery_attempts_subscription_id_idx
+    ON webhook_delivery_attempts (subscription_id, created_at DESC);
+
+CREATE TABLE deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    scheduled_for DATETIME NOT NULL,
+    sent_at DATETIME,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed')),
+    provider_message_id TEXT,
+    error TEXT,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX deliveries_subscription_id_idx ON deliveries (subscription_id, id DESC);
+
+CREATE TABLE email_suppressions (
+    email TEXT PRIMARY KEY,
+    reason TEXT NOT NULL CHECK (reason IN ('hard_bounce', 'complaint')),
+    detail TEXT,
+    created_at DATETIME NOT NULL
+);
+
+-- +goose Down
+DROP TABLE email_suppressions;
+DROP TABLE deliveries;
+DROP TABLE webhook_delivery_attempts;
+DROP TABLE api_key_usage;
+DROP TABLE api_keys;
+DROP TABLE subscriptions;
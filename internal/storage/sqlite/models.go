@@ -0,0 +1,76 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	enums "github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+type ApiKey struct {
+	ID         int64
+	Name       string
+	Prefix     string
+	KeyHash    string
+	DailyQuota int64
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+}
+
+type ApiKeyUsage struct {
+	ApiKeyID     int64
+	Day          string
+	RequestCount int64
+}
+
+type Delivery struct {
+	ID                int64
+	SubscriptionID    int64
+	Channel           enums.Channel
+	ScheduledFor      time.Time
+	SentAt            sql.NullTime
+	Status            enums.DeliveryStatus
+	ProviderMessageID sql.NullString
+	Error             sql.NullString
+	CreatedAt         time.Time
+}
+
+type EmailSuppression struct {
+	Email     string
+	Reason    enums.SuppressionReason
+	Detail    sql.NullString
+	CreatedAt time.Time
+}
+
+type Subscription struct {
+	ID             int64
+	Contact        string
+	City           string
+	Frequency      enums.Frequency
+	Confirmed      bool
+	Token          string
+	CreatedAt      time.Time
+	Units          enums.Units
+	Lang           enums.Language
+	Channel        enums.Channel
+	WebhookSecret  sql.NullString
+	NextRunAt      time.Time
+	LeaseOwner     sql.NullString
+	LeaseExpiresAt sql.NullTime
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             int64
+	SubscriptionID int64
+	DeliveryID     string
+	Attempt        int64
+	StatusCode     sql.NullInt64
+	Error          sql.NullString
+	DurationMs     int64
+	CreatedAt      time.Time
+}
@@ -0,0 +1,19 @@
+-- name: CreateDelivery :exec
+INSERT INTO deliveries (subscription_id, channel, scheduled_for, sent_at, status, provider_message_id, error, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListDeliveries :many
+-- Zero subscription_id, empty status and zero before_id disable the
+-- corresponding filter. Pages are walked with before_id, newest first.
+SELECT * FROM deliveries
+WHERE (CAST(sqlc.arg(subscription_id) AS INTEGER) = 0 OR subscription_id = sqlc.arg(subscription_id))
+  AND (CAST(sqlc.arg(status) AS TEXT) = '' OR status = sqlc.arg(status))
+  AND (CAST(sqlc.arg(before_id) AS INTEGER) = 0 OR id < sqlc.arg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: LastSentAt :one
+SELECT sent_at FROM deliveries
+WHERE subscription_id = ? AND status = 'sent'
+ORDER BY id DESC
+LIMIT 1;
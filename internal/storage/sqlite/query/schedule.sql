@@ -0,0 +1,29 @@
+-- name: ClaimDueSubscriptions :many
+-- Leases up to batch_size due subscriptions to owner. SQLite runs one
+-- write at a time, so concurrent workers claim disjoint batches; expired
+-- leases are reclaimed.
+UPDATE subscriptions
+SET lease_owner = CAST(sqlc.arg(owner) AS TEXT),
+    lease_expires_at = sqlc.arg(lease_expires_at)
+WHERE id IN (
+    SELECT due.id FROM subscriptions due
+    WHERE due.confirmed
+      AND due.next_run_at <= sqlc.arg(now)
+      AND (due.lease_expires_at IS NULL OR due.lease_expires_at < sqlc.arg(now))
+    ORDER BY due.next_run_at
+    LIMIT sqlc.arg(batch_size)
+)
+RETURNING *;
+
+-- name: CompleteSubscriptionRun :execrows
+UPDATE subscriptions
+SET next_run_at = sqlc.arg(next_run_at),
+    lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = sqlc.arg(id) AND lease_owner = CAST(sqlc.arg(owner) AS TEXT);
+
+-- name: ReleaseSubscriptionLeases :exec
+UPDATE subscriptions
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE lease_owner = CAST(sqlc.arg(owner) AS TEXT) AND id IN (sqlc.slice(ids));
@@ -0,0 +1,41 @@
+-- name: CreateSubscription :one
+INSERT INTO subscriptions (channel, contact, city, frequency, token, units, lang, confirmed, webhook_secret, created_at, next_run_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, sqlc.arg(now), sqlc.arg(now))
+    RETURNING *;
+
+-- name: GetSubscriptionByToken :one
+SELECT * FROM subscriptions
+WHERE token = ?;
+
+-- name: GetSubscriptionByID :one
+SELECT * FROM subscriptions
+WHERE id = ?;
+
+-- name: ConfirmSubscription :exec
+UPDATE subscriptions
+SET confirmed = true
+WHERE token = ?;
+
+-- name: Unsubscribe :exec
+DELETE FROM subscriptions
+WHERE token = ?;
+
+-- name: IsAlreadySubscribed :one
+SELECT COUNT(*) FROM subscriptions
+WHERE channel = ? AND contact = ? AND city = ?;
+
+-- name: ListSubscriptionsByContact :many
+SELECT * FROM subscriptions
+WHERE channel = ? AND contact = ?
+ORDER BY id;
+
+-- name: ListSubscriptionsByEmail :many
+SELECT * FROM subscriptions
+WHERE channel = 'email' AND lower(contact) = lower(sqlc.arg(email))
+ORDER BY id;
+
+-- name: ListDueSubscriptions :many
+SELECT * FROM subscriptions
+WHERE confirmed AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at, id
+LIMIT sqlc.arg(batch_size);
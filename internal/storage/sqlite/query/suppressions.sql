@@ -0,0 +1,22 @@
+-- name: UpsertSuppression :exec
+-- Emails are stored lowercased. A complaint overrides an earlier bounce.
+INSERT INTO email_suppressions (email, reason, detail, created_at)
+VALUES (lower(sqlc.arg(email)), sqlc.arg(reason), sqlc.arg(detail), sqlc.arg(created_at))
+ON CONFLICT (email) DO UPDATE
+SET reason = excluded.reason,
+    detail = excluded.detail
+WHERE email_suppressions.reason <> 'complaint';
+
+-- name: ListSuppressions :many
+SELECT * FROM email_suppressions
+ORDER BY created_at DESC, email
+LIMIT ?;
+
+-- name: DeleteSuppression :execrows
+DELETE FROM email_suppressions
+WHERE email = lower(sqlc.arg(email));
+
+-- name: SuppressedEmails :many
+-- Returns the lowercased emails among the given ones that are suppressed.
+SELECT email FROM email_suppressions
+WHERE email IN (sqlc.slice(emails));
@@ -0,0 +1,9 @@
+-- name: LogWebhookAttempt :exec
+INSERT INTO webhook_delivery_attempts (subscription_id, delivery_id, attempt, status_code, error, duration_ms, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListWebhookAttempts :many
+SELECT * FROM webhook_delivery_attempts
+WHERE subscription_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"time"
+)
+
+var _ storage.ScheduleStorage = (*ScheduleImpl)(nil)
+
+type ScheduleImpl struct {
+	q *Queries
+}
+
+func NewScheduleStorage(q *Queries) *ScheduleImpl {
+	return &ScheduleImpl{q: q}
+}
+
+func (s *ScheduleImpl) ClaimDue(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+	claimedAt := now()
+	dbSubs, err := s.q.ClaimDueSubscriptions(ctx, ClaimDueSubscriptionsParams{
+		Owner:          owner,
+		LeaseExpiresAt: sql.NullTime{Time: claimedAt.Add(lease.Truncate(time.Second)), Valid: true},
+		Now:            claimedAt,
+		BatchSize:      int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *ScheduleImpl) Complete(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+	affected, err := s.q.CompleteSubscriptionRun(ctx, CompleteSubscriptionRunParams{
+		NextRunAt: nextRunAt.UTC(),
+		ID:        id,
+		Owner:     owner,
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *ScheduleImpl) Release(ctx context.Context, owner string, ids []int64) error {
+	return s.q.ReleaseSubscriptionLeases(ctx, ReleaseSubscriptionLeasesParams{
+		Owner: owner,
+		Ids:   ids,
+	})
+}
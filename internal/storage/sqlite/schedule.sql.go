@@ -0,0 +1,131 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: schedule.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const claimDueSubscriptions = `-- name: ClaimDueSubscriptions :many
+UPDATE subscriptions
+SET lease_owner = CAST(?1 AS TEXT),
+    lease_expires_at = ?2
+WHERE id IN (
+    SELECT due.id FROM subscriptions due
+    WHERE due.confirmed
+      AND due.next_run_at <= ?3
+      AND (due.lease_expires_at IS NULL OR due.lease_expires_at < ?3)
+    ORDER BY due.next_run_at
+    LIMIT ?4
+)
+RETURNING id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at
+`
+
+type ClaimDueSubscriptionsParams struct {
+	Owner          string
+	LeaseExpiresAt sql.NullTime
+	Now            time.Time
+	BatchSize      int64
+}
+
+// Leases up to batch_size due subscriptions to owner. SQLite runs one
+// write at a time, so concurrent workers claim disjoint batches; expired
+// leases are reclaimed.
+func (q *Queries) ClaimDueSubscriptions(ctx context.Context, arg ClaimDueSubscriptionsParams) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueSubscriptions,
+		arg.Owner,
+		arg.LeaseExpiresAt,
+		arg.Now,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeSubscriptionRun = `-- name: CompleteSubscriptionRun :execrows
+UPDATE subscriptions
+SET next_run_at = ?1,
+    lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = ?2 AND lease_owner = CAST(?3 AS TEXT)
+`
+
+type CompleteSubscriptionRunParams struct {
+	NextRunAt time.Time
+	ID        int64
+	Owner     string
+}
+
+func (q *Queries) CompleteSubscriptionRun(ctx context.Context, arg CompleteSubscriptionRunParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, completeSubscriptionRun, arg.NextRunAt, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const releaseSubscriptionLeases = `-- name: ReleaseSubscriptionLeases :exec
+UPDATE subscriptions
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE lease_owner = CAST(?1 AS TEXT) AND id IN (/*SLICE:ids*/?)
+`
+
+type ReleaseSubscriptionLeasesParams struct {
+	Owner string
+	Ids   []int64
+}
+
+func (q *Queries) ReleaseSubscriptionLeases(ctx context.Context, arg ReleaseSubscriptionLeasesParams) error {
+	query := releaseSubscriptionLeases
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.Owner)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/XSAM/otelsql"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+	"net/url"
+	"time"
+)
+
+// Open opens the SQLite database at conf.Path, creating it if needed, and
+// applies pending migrations. Foreign keys are enforced, and writers wait
+// for each other instead of failing with SQLITE_BUSY.
+func Open(ctx context.Context, conf configs.DB) (*sql.DB, error) {
+	query := url.Values{
+		"_pragma": []string{
+			"foreign_keys(1)",
+			"busy_timeout(5000)",
+			"journal_mode(WAL)",
+		},
+		"_time_format": []string{"sqlite"},
+		"_txlock":      []string{"immediate"},
+	}
+	dsn := (&url.URL{Scheme: "file", Opaque: conf.Path, RawQuery: query.Encode()}).String()
+
+	db, err := otelsql.Open("sqlite", dsn,
+		otelsql.WithAttributes(semconv.DBSystemSqlite),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[sqlite.Open] %w", err)
+	}
+
+	db.SetMaxOpenConns(conf.MaxOpenConns)
+	db.SetMaxIdleConns(conf.MaxIdleConns)
+	db.SetConnMaxLifetime(conf.ConnMaxLifetime)
+
+	if err := Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// now returns the current time in UTC. Times are stored as UTC text, so
+// every time written must be in UTC for comparisons to hold.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// mapError translates SQLite constraint violations into storage errors,
+// as storage does for Postgres.
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return fmt.Errorf("%w: %w", storage.ErrDuplicate, err)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%w: %w", storage.ErrMissingReference, err)
+	case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		return fmt.Errorf("%w: %w", storage.ErrInvalidValue, err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"time"
+)
+
+var _ storage.SubscriptionStorage = (*SubImpl)(nil)
+
+type SubImpl struct {
+	q *Queries
+}
+
+func NewSubscriptionStorage(q *Queries) *SubImpl {
+	return &SubImpl{q: q}
+}
+
+func (s *SubImpl) Create(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+	dbSub, err := s.q.CreateSubscription(ctx, CreateSubscriptionParams{
+		Channel:   sub.Channel,
+		Contact:   sub.Contact,
+		City:      sub.City,
+		Token:     sub.Token,
+		Frequency: sub.Frequency,
+		Units:     sub.Units,
+		Lang:      sub.Lang,
+		Confirmed: sub.Confirmed,
+		WebhookSecret: sql.NullString{
+			String: sub.WebhookSecret,
+			Valid:  sub.WebhookSecret != "",
+		},
+		Now: now(),
+	})
+	if err != nil {
+		return models.Subscription{}, mapError(err)
+	}
+
+	return subscriptionFromDB(dbSub), nil
+}
+
+func (s *SubImpl) IsAlreadySubscribed(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+	count, err := s.q.IsAlreadySubscribed(ctx, IsAlreadySubscribedParams{
+		Channel: channel,
+		Contact: contact,
+		City:    city,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *SubImpl) GetByToken(ctx context.Context, token string) (models.Subscription, error) {
+	dbSub, err := s.q.GetSubscriptionByToken(ctx, token)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	return subscriptionFromDB(dbSub), nil
+}
+
+func (s *SubImpl) GetByID(ctx context.Context, id int64) (models.Subscription, error) {
+	dbSub, err := s.q.GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	return subscriptionFromDB(dbSub), nil
+}
+
+func (s *SubImpl) ListByContact(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+	dbSubs, err := s.q.ListSubscriptionsByContact(ctx, ListSubscriptionsByContactParams{
+		Channel: channel,
+		Contact: contact,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *SubImpl) ListByEmail(ctx context.Context, email string) ([]models.Subscription, error) {
+	dbSubs, err := s.q.ListSubscriptionsByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *SubImpl) ListDue(ctx context.Context, now time.Time, limit int) ([]models.Subscription, error) {
+	// Postgres rejects a negative LIMIT and SQLite treats it as no limit.
+	if limit <= 0 {
+		return []models.Subscription{}, nil
+	}
+
+	dbSubs, err := s.q.ListDueSubscriptions(ctx, ListDueSubscriptionsParams{
+		Now:       now.UTC(),
+		BatchSize: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *SubImpl) Confirm(ctx context.Context, token string) error {
+	return s.q.ConfirmSubscription(ctx, token)
+}
+
+func (s *SubImpl) Unsubscribe(ctx context.Context, token string) error {
+	return s.q.Unsubscribe(ctx, token)
+}
+
+func subscriptionFromDB(dbSub Subscription) models.Subscription {
+	return models.Subscription{
+		ID:        dbSub.ID,
+		Channel:   dbSub.Channel,
+		Contact:   dbSub.Contact,
+		City:      dbSub.City,
+		Frequency: dbSub.Frequency,
+		Units:     dbSub.Units,
+		Lang:      dbSub.Lang,
+		Token:     dbSub.Token,
+		Confirmed: dbSub.Confirmed,
+
+		WebhookSecret: dbSub.WebhookSecret.String,
+		NextRunAt:     dbSub.NextRunAt,
+		CreatedAt:     dbSub.CreatedAt,
+	}
+}
@@ -0,0 +1,302 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: subscriptions.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	enums "github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+const confirmSubscription = `-- name: ConfirmSubscription :exec
+UPDATE subscriptions
+SET confirmed = true
+WHERE token = ?
+`
+
+func (q *Queries) ConfirmSubscription(ctx context.Context, token string) error {
+	_, err := q.db.ExecContext(ctx, confirmSubscription, token)
+	return err
+}
+
+const createSubscription = `-- name: CreateSubscription :one
+INSERT INTO subscriptions (channel, contact, city, frequency, token, units, lang, confirmed, webhook_secret, created_at, next_run_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?10, ?10)
+    RETURNING id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at
+`
+
+type CreateSubscriptionParams struct {
+	Channel       enums.Channel
+	Contact       string
+	City          string
+	Frequency     enums.Frequency
+	Token         string
+	Units         enums.Units
+	Lang          enums.Language
+	Confirmed     bool
+	WebhookSecret sql.NullString
+	Now           time.Time
+}
+
+func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, createSubscription,
+		arg.Channel,
+		arg.Contact,
+		arg.City,
+		arg.Frequency,
+		arg.Token,
+		arg.Units,
+		arg.Lang,
+		arg.Confirmed,
+		arg.WebhookSecret,
+		arg.Now,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.Contact,
+		&i.City,
+		&i.Frequency,
+		&i.Confirmed,
+		&i.Token,
+		&i.CreatedAt,
+		&i.Units,
+		&i.Lang,
+		&i.Channel,
+		&i.WebhookSecret,
+		&i.NextRunAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const getSubscriptionByID = `-- name: GetSubscriptionByID :one
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE id = ?
+`
+
+func (q *Queries) GetSubscriptionByID(ctx context.Context, id int64) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, getSubscriptionByID, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.Contact,
+		&i.City,
+		&i.Frequency,
+		&i.Confirmed,
+		&i.Token,
+		&i.CreatedAt,
+		&i.Units,
+		&i.Lang,
+		&i.Channel,
+		&i.WebhookSecret,
+		&i.NextRunAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const getSubscriptionByToken = `-- name: GetSubscriptionByToken :one
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE token = ?
+`
+
+func (q *Queries) GetSubscriptionByToken(ctx context.Context, token string) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, getSubscriptionByToken, token)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.Contact,
+		&i.City,
+		&i.Frequency,
+		&i.Confirmed,
+		&i.Token,
+		&i.CreatedAt,
+		&i.Units,
+		&i.Lang,
+		&i.Channel,
+		&i.WebhookSecret,
+		&i.NextRunAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const isAlreadySubscribed = `-- name: IsAlreadySubscribed :one
+SELECT COUNT(*) FROM subscriptions
+WHERE channel = ? AND contact = ? AND city = ?
+`
+
+type IsAlreadySubscribedParams struct {
+	Channel enums.Channel
+	Contact string
+	City    string
+}
+
+func (q *Queries) IsAlreadySubscribed(ctx context.Context, arg IsAlreadySubscribedParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, isAlreadySubscribed, arg.Channel, arg.Contact, arg.City)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const listDueSubscriptions = `-- name: ListDueSubscriptions :many
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE confirmed AND next_run_at <= ?1
+ORDER BY next_run_at, id
+LIMIT ?2
+`
+
+type ListDueSubscriptionsParams struct {
+	Now       time.Time
+	BatchSize int64
+}
+
+func (q *Queries) ListDueSubscriptions(ctx context.Context, arg ListDueSubscriptionsParams) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, listDueSubscriptions, arg.Now, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionsByContact = `-- name: ListSubscriptionsByContact :many
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE channel = ? AND contact = ?
+ORDER BY id
+`
+
+type ListSubscriptionsByContactParams struct {
+	Channel enums.Channel
+	Contact string
+}
+
+func (q *Queries) ListSubscriptionsByContact(ctx context.Context, arg ListSubscriptionsByContactParams) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, listSubscriptionsByContact, arg.Channel, arg.Contact)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionsByEmail = `-- name: ListSubscriptionsByEmail :many
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE channel = 'email' AND lower(contact) = lower(?1)
+ORDER BY id
+`
+
+func (q *Queries) ListSubscriptionsByEmail(ctx context.Context, email string) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, listSubscriptionsByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unsubscribe = `-- name: Unsubscribe :exec
+DELETE FROM subscriptions
+WHERE token = ?
+`
+
+func (q *Queries) Unsubscribe(ctx context.Context, token string) error {
+	_, err := q.db.ExecContext(ctx, unsubscribe, token)
+	return err
+}
@@ -0,0 +1,128 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: suppressions.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	enums "github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+const deleteSuppression = `-- name: DeleteSuppression :execrows
+DELETE FROM email_suppressions
+WHERE email = lower(?1)
+`
+
+func (q *Queries) DeleteSuppression(ctx context.Context, email string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSuppression, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listSuppressions = `-- name: ListSuppressions :many
+SELECT email, reason, detail, created_at FROM email_suppressions
+ORDER BY created_at DESC, email
+LIMIT ?
+`
+
+func (q *Queries) ListSuppressions(ctx context.Context, limit int64) ([]EmailSuppression, error) {
+	rows, err := q.db.QueryContext(ctx, listSuppressions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailSuppression
+	for rows.Next() {
+		var i EmailSuppression
+		if err := rows.Scan(
+			&i.Email,
+			&i.Reason,
+			&i.Detail,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const suppressedEmails = `-- name: SuppressedEmails :many
+SELECT email FROM email_suppressions
+WHERE email IN (/*SLICE:emails*/?)
+`
+
+// Returns the lowercased emails among the given ones that are suppressed.
+func (q *Queries) SuppressedEmails(ctx context.Context, emails []string) ([]string, error) {
+	query := suppressedEmails
+	var queryParams []interface{}
+	if len(emails) > 0 {
+		for _, v := range emails {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:emails*/?", strings.Repeat(",?", len(emails))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:emails*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSuppression = `-- name: UpsertSuppression :exec
+INSERT INTO email_suppressions (email, reason, detail, created_at)
+VALUES (lower(?1), ?2, ?3, ?4)
+ON CONFLICT (email) DO UPDATE
+SET reason = excluded.reason,
+    detail = excluded.detail
+WHERE email_suppressions.reason <> 'complaint'
+`
+
+type UpsertSuppressionParams struct {
+	Email     string
+	Reason    enums.SuppressionReason
+	Detail    sql.NullString
+	CreatedAt time.Time
+}
+
+// Emails are stored lowercased. A complaint overrides an earlier bounce.
+func (q *Queries) UpsertSuppression(ctx context.Context, arg UpsertSuppressionParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSuppression,
+		arg.Email,
+		arg.Reason,
+		arg.Detail,
+		arg.CreatedAt,
+	)
+	return err
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+)
+
+func NewStores(q *Queries) storage.Stores {
+	return storage.Stores{
+		Subscriptions:   NewSubscriptionStorage(q),
+		APIKeys:         NewAPIKeyStorage(q),
+		Deliveries:      NewDeliveryStorage(q),
+		Suppressions:    NewSuppressionStorage(q),
+		Schedule:        NewScheduleStorage(q),
+		WebhookAttempts: NewWebhookAttemptStorage(q),
+	}
+}
+
+var _ storage.UnitOfWork = (*UnitOfWorkImpl)(nil)
+
+// UnitOfWorkImpl transactions take the write lock when they begin (see
+// _txlock in Open), so they run one at a time.
+type UnitOfWorkImpl struct {
+	db *sql.DB
+	q  *Queries
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWorkImpl {
+	return &UnitOfWorkImpl{db: db, q: New(db)}
+}
+
+func (u *UnitOfWorkImpl) InTx(ctx context.Context, fn func(stores storage.Stores) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[UnitOfWork.InTx] begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(NewStores(u.q.WithTx(tx))); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[UnitOfWork.InTx] commit: %w", mapError(err))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"time"
+)
+
+var _ storage.WebhookAttemptStorage = (*WebhookAttemptImpl)(nil)
+
+type WebhookAttemptImpl struct {
+	q *Queries
+}
+
+func NewWebhookAttemptStorage(q *Queries) *WebhookAttemptImpl {
+	return &WebhookAttemptImpl{q: q}
+}
+
+func (s *WebhookAttemptImpl) LogAttempt(ctx context.Context, a models.WebhookAttempt) error {
+	err := s.q.LogWebhookAttempt(ctx, LogWebhookAttemptParams{
+		SubscriptionID: a.SubscriptionID,
+		DeliveryID:     a.DeliveryID,
+		Attempt:        int64(a.Attempt),
+		StatusCode:     sql.NullInt64{Int64: int64(a.StatusCode), Valid: a.StatusCode != 0},
+		Error:          sql.NullString{String: a.Error, Valid: a.Error != ""},
+		DurationMs:     a.Duration.Milliseconds(),
+		CreatedAt:      now(),
+	})
+	return mapError(err)
+}
+
+func (s *WebhookAttemptImpl) ListAttempts(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookAttempt, error) {
+	rows, err := s.q.ListWebhookAttempts(ctx, ListWebhookAttemptsParams{
+		SubscriptionID: subscriptionID,
+		Limit:          int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]models.WebhookAttempt, 0, len(rows))
+	for _, row := range rows {
+		attempts = append(attempts, models.WebhookAttempt{
+			ID:             row.ID,
+			SubscriptionID: row.SubscriptionID,
+			DeliveryID:     row.DeliveryID,
+			Attempt:        int(row.Attempt),
+			StatusCode:     int(row.StatusCode.Int64),
+			Error:          row.Error.String,
+			Duration:       time.Duration(row.DurationMs) * time.Millisecond,
+			CreatedAt:      row.CreatedAt,
+		})
+	}
+	return attempts, nil
+}
@@ -0,0 +1,84 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: webhook_attempts.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const listWebhookAttempts = `-- name: ListWebhookAttempts :many
+SELECT id, subscription_id, delivery_id, attempt, status_code, error, duration_ms, created_at FROM webhook_delivery_attempts
+WHERE subscription_id = ?
+ORDER BY created_at DESC, id DESC
+LIMIT ?
+`
+
+type ListWebhookAttemptsParams struct {
+	SubscriptionID int64
+	Limit          int64
+}
+
+func (q *Queries) ListWebhookAttempts(ctx context.Context, arg ListWebhookAttemptsParams) ([]WebhookDeliveryAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookAttempts, arg.SubscriptionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeliveryAttempt
+	for rows.Next() {
+		var i WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.DeliveryID,
+			&i.Attempt,
+			&i.StatusCode,
+			&i.Error,
+			&i.DurationMs,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logWebhookAttempt = `-- name: LogWebhookAttempt :exec
+INSERT INTO webhook_delivery_attempts (subscription_id, delivery_id, attempt, status_code, error, duration_ms, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type LogWebhookAttemptParams struct {
+	SubscriptionID int64
+	DeliveryID     string
+	Attempt        int64
+	StatusCode     sql.NullInt64
+	Error          sql.NullString
+	DurationMs     int64
+	CreatedAt      time.Time
+}
+
+func (q *Queries) LogWebhookAttempt(ctx context.Context, arg LogWebhookAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, logWebhookAttempt,
+		arg.SubscriptionID,
+		arg.DeliveryID,
+		arg.Attempt,
+		arg.StatusCode,
+		arg.Error,
+		arg.DurationMs,
+		arg.CreatedAt,
+	)
+	return err
+}
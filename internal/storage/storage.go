@@ -2,13 +2,30 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
 	"github.com/serjnester/weather-subscription-service/domain/models"
+	"time"
 )
 
+// SubscriptionStorage lookups of missing subscriptions return sql.ErrNoRows;
+// Confirm and Unsubscribe of an unknown token are no-ops.
 type SubscriptionStorage interface {
-	Create(ctx context.Context, sub models.Subscription) error
-	IsAlreadySubscribed(ctx context.Context, email, city string) (bool, error)
+	// Create returns the stored subscription with its ID, or ErrDuplicate
+	// if the token or the channel, contact and city are already taken.
+	Create(ctx context.Context, sub models.Subscription) (models.Subscription, error)
+	IsAlreadySubscribed(ctx context.Context, channel enums.Channel, contact, city string) (bool, error)
 	GetByToken(ctx context.Context, token string) (models.Subscription, error)
+	GetByID(ctx context.Context, id int64) (models.Subscription, error)
+	ListByContact(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error)
+	// ListByEmail returns the email subscriptions of the address, compared
+	// case-insensitively, ordered by ID.
+	ListByEmail(ctx context.Context, email string) ([]models.Subscription, error)
+	// ListDue returns up to limit confirmed subscriptions whose next run is
+	// at or before now, earliest first; a limit of zero or less returns
+	// none. Unlike ScheduleStorage.ClaimDue it takes no leases, so it is
+	// only fit for reads.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.Subscription, error)
 	Confirm(ctx context.Context, token string) error
 	Unsubscribe(ctx context.Context, token string) error
 }
@@ -23,20 +40,33 @@ func NewSubscriptionStorage(q *Queries) *SubImpl {
 	return &SubImpl{q: q}
 }
 
-func (s *SubImpl) Create(ctx context.Context, sub models.Subscription) error {
-	_, err := s.q.CreateSubscription(ctx, CreateSubscriptionParams{
-		Email:     sub.Email,
+func (s *SubImpl) Create(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
+	dbSub, err := s.q.CreateSubscription(ctx, CreateSubscriptionParams{
+		Channel:   sub.Channel,
+		Contact:   sub.Contact,
 		City:      sub.City,
 		Token:     sub.Token,
 		Frequency: sub.Frequency,
+		Units:     sub.Units,
+		Lang:      sub.Lang,
+		Confirmed: sub.Confirmed,
+		WebhookSecret: sql.NullString{
+			String: sub.WebhookSecret,
+			Valid:  sub.WebhookSecret != "",
+		},
 	})
-	return err
+	if err != nil {
+		return models.Subscription{}, mapError(err)
+	}
+
+	return subscriptionFromDB(dbSub), nil
 }
 
-func (s *SubImpl) IsAlreadySubscribed(ctx context.Context, email, city string) (bool, error) {
+func (s *SubImpl) IsAlreadySubscribed(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
 	count, err := s.q.IsAlreadySubscribed(ctx, IsAlreadySubscribedParams{
-		Email: email,
-		City:  city,
+		Channel: channel,
+		Contact: contact,
+		City:    city,
 	})
 	if err != nil {
 		return false, err
@@ -50,12 +80,66 @@ func (s *SubImpl) GetByToken(ctx context.Context, token string) (models.Subscrip
 		return models.Subscription{}, err
 	}
 
-	return models.Subscription{
-		Email:     dbSub.Email,
-		City:      dbSub.City,
-		Token:     dbSub.Token,
-		Confirmed: dbSub.Confirmed,
-	}, nil
+	return subscriptionFromDB(dbSub), nil
+}
+
+func (s *SubImpl) GetByID(ctx context.Context, id int64) (models.Subscription, error) {
+	dbSub, err := s.q.GetSubscriptionByID(ctx, int32(id))
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	return subscriptionFromDB(dbSub), nil
+}
+
+func (s *SubImpl) ListByContact(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+	dbSubs, err := s.q.ListSubscriptionsByContact(ctx, ListSubscriptionsByContactParams{
+		Channel: channel,
+		Contact: contact,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *SubImpl) ListByEmail(ctx context.Context, email string) ([]models.Subscription, error) {
+	dbSubs, err := s.q.ListSubscriptionsByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
+}
+
+func (s *SubImpl) ListDue(ctx context.Context, now time.Time, limit int) ([]models.Subscription, error) {
+	// Postgres rejects a negative LIMIT and SQLite treats it as no limit.
+	if limit <= 0 {
+		return []models.Subscription{}, nil
+	}
+
+	dbSubs, err := s.q.ListDueSubscriptions(ctx, ListDueSubscriptionsParams{
+		Now:       now,
+		BatchSize: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(dbSubs))
+	for _, dbSub := range dbSubs {
+		subs = append(subs, subscriptionFromDB(dbSub))
+	}
+	return subs, nil
 }
 
 func (s *SubImpl) Confirm(ctx context.Context, token string) error {
@@ -65,3 +149,21 @@ func (s *SubImpl) Confirm(ctx context.Context, token string) error {
 func (s *SubImpl) Unsubscribe(ctx context.Context, token string) error {
 	return s.q.Unsubscribe(ctx, token)
 }
+
+func subscriptionFromDB(dbSub Subscription) models.Subscription {
+	return models.Subscription{
+		ID:        int64(dbSub.ID),
+		Channel:   dbSub.Channel,
+		Contact:   dbSub.Contact,
+		City:      dbSub.City,
+		Frequency: dbSub.Frequency,
+		Units:     dbSub.Units,
+		Lang:      dbSub.Lang,
+		Token:     dbSub.Token,
+		Confirmed: dbSub.Confirmed,
+
+		WebhookSecret: dbSub.WebhookSecret.String,
+		NextRunAt:     dbSub.NextRunAt,
+		CreatedAt:     dbSub.CreatedAt,
+	}
+}
@@ -2,29 +2,51 @@ package storage
 
 import (
 	"context"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
 	"github.com/serjnester/weather-subscription-service/domain/models"
+	"time"
 )
 
 type MockStorage struct {
-	CreateFn              func(ctx context.Context, sub models.Subscription) error
-	IsAlreadySubscribedFn func(ctx context.Context, email, city string) (bool, error)
+	CreateFn              func(ctx context.Context, sub models.Subscription) (models.Subscription, error)
+	IsAlreadySubscribedFn func(ctx context.Context, channel enums.Channel, contact, city string) (bool, error)
 	GetByTokenFn          func(ctx context.Context, token string) (models.Subscription, error)
+	GetByIDFn             func(ctx context.Context, id int64) (models.Subscription, error)
+	ListByContactFn       func(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error)
+	ListByEmailFn         func(ctx context.Context, email string) ([]models.Subscription, error)
+	ListDueFn             func(ctx context.Context, now time.Time, limit int) ([]models.Subscription, error)
 	ConfirmFn             func(ctx context.Context, token string) error
 	UnsubscribeFn         func(ctx context.Context, token string) error
 }
 
-func (m *MockStorage) Create(ctx context.Context, sub models.Subscription) error {
+func (m *MockStorage) Create(ctx context.Context, sub models.Subscription) (models.Subscription, error) {
 	return m.CreateFn(ctx, sub)
 }
 
-func (m *MockStorage) IsAlreadySubscribed(ctx context.Context, email, city string) (bool, error) {
-	return m.IsAlreadySubscribedFn(ctx, email, city)
+func (m *MockStorage) IsAlreadySubscribed(ctx context.Context, channel enums.Channel, contact, city string) (bool, error) {
+	return m.IsAlreadySubscribedFn(ctx, channel, contact, city)
 }
 
 func (m *MockStorage) GetByToken(ctx context.Context, token string) (models.Subscription, error) {
 	return m.GetByTokenFn(ctx, token)
 }
 
+func (m *MockStorage) GetByID(ctx context.Context, id int64) (models.Subscription, error) {
+	return m.GetByIDFn(ctx, id)
+}
+
+func (m *MockStorage) ListByContact(ctx context.Context, channel enums.Channel, contact string) ([]models.Subscription, error) {
+	return m.ListByContactFn(ctx, channel, contact)
+}
+
+func (m *MockStorage) ListByEmail(ctx context.Context, email string) ([]models.Subscription, error) {
+	return m.ListByEmailFn(ctx, email)
+}
+
+func (m *MockStorage) ListDue(ctx context.Context, now time.Time, limit int) ([]models.Subscription, error) {
+	return m.ListDueFn(ctx, now, limit)
+}
+
 func (m *MockStorage) Confirm(ctx context.Context, token string) error {
 	return m.ConfirmFn(ctx, token)
 }
@@ -32,3 +54,111 @@ func (m *MockStorage) Confirm(ctx context.Context, token string) error {
 func (m *MockStorage) Unsubscribe(ctx context.Context, token string) error {
 	return m.UnsubscribeFn(ctx, token)
 }
+
+type MockAPIKeyStorage struct {
+	CreateFn     func(ctx context.Context, key models.APIKey, keyHash string) (models.APIKey, error)
+	GetByHashFn  func(ctx context.Context, keyHash string) (models.APIKey, error)
+	ListFn       func(ctx context.Context) ([]models.APIKey, error)
+	RevokeFn     func(ctx context.Context, id int64) error
+	TrackUsageFn func(ctx context.Context, id int64) (int64, error)
+}
+
+func (m *MockAPIKeyStorage) Create(ctx context.Context, key models.APIKey, keyHash string) (models.APIKey, error) {
+	return m.CreateFn(ctx, key, keyHash)
+}
+
+func (m *MockAPIKeyStorage) GetByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	return m.GetByHashFn(ctx, keyHash)
+}
+
+func (m *MockAPIKeyStorage) List(ctx context.Context) ([]models.APIKey, error) {
+	return m.ListFn(ctx)
+}
+
+func (m *MockAPIKeyStorage) Revoke(ctx context.Context, id int64) error {
+	return m.RevokeFn(ctx, id)
+}
+
+func (m *MockAPIKeyStorage) TrackUsage(ctx context.Context, id int64) (int64, error) {
+	return m.TrackUsageFn(ctx, id)
+}
+
+type MockWebhookAttemptStorage struct {
+	LogAttemptFn   func(ctx context.Context, attempt models.WebhookAttempt) error
+	ListAttemptsFn func(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookAttempt, error)
+}
+
+func (m *MockWebhookAttemptStorage) LogAttempt(ctx context.Context, attempt models.WebhookAttempt) error {
+	return m.LogAttemptFn(ctx, attempt)
+}
+
+func (m *MockWebhookAttemptStorage) ListAttempts(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookAttempt, error) {
+	return m.ListAttemptsFn(ctx, subscriptionID, limit)
+}
+
+type MockScheduleStorage struct {
+	ClaimDueFn func(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error)
+	CompleteFn func(ctx context.Context, owner string, id int64, nextRunAt time.Time) error
+	ReleaseFn  func(ctx context.Context, owner string, ids []int64) error
+}
+
+func (m *MockScheduleStorage) ClaimDue(ctx context.Context, owner string, limit int, lease time.Duration) ([]models.Subscription, error) {
+	return m.ClaimDueFn(ctx, owner, limit, lease)
+}
+
+func (m *MockScheduleStorage) Complete(ctx context.Context, owner string, id int64, nextRunAt time.Time) error {
+	return m.CompleteFn(ctx, owner, id, nextRunAt)
+}
+
+func (m *MockScheduleStorage) Release(ctx context.Context, owner string, ids []int64) error {
+	return m.ReleaseFn(ctx, owner, ids)
+}
+
+type MockDeliveryStorage struct {
+	RecordFn     func(ctx context.Context, d models.Delivery) error
+	ListFn       func(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error)
+	LastSentAtFn func(ctx context.Context, subscriptionID int64) (*time.Time, error)
+}
+
+func (m *MockDeliveryStorage) Record(ctx context.Context, d models.Delivery) error {
+	return m.RecordFn(ctx, d)
+}
+
+func (m *MockDeliveryStorage) List(ctx context.Context, filter models.DeliveryFilter) ([]models.Delivery, error) {
+	return m.ListFn(ctx, filter)
+}
+
+func (m *MockDeliveryStorage) LastSentAt(ctx context.Context, subscriptionID int64) (*time.Time, error) {
+	return m.LastSentAtFn(ctx, subscriptionID)
+}
+
+type MockSuppressionStorage struct {
+	SuppressFn   func(ctx context.Context, s models.Suppression) error
+	ListFn       func(ctx context.Context, limit int) ([]models.Suppression, error)
+	RemoveFn     func(ctx context.Context, email string) error
+	SuppressedFn func(ctx context.Context, emails []string) (map[string]bool, error)
+}
+
+func (m *MockSuppressionStorage) Suppress(ctx context.Context, s models.Suppression) error {
+	return m.SuppressFn(ctx, s)
+}
+
+func (m *MockSuppressionStorage) List(ctx context.Context, limit int) ([]models.Suppression, error) {
+	return m.ListFn(ctx, limit)
+}
+
+func (m *MockSuppressionStorage) Remove(ctx context.Context, email string) error {
+	return m.RemoveFn(ctx, email)
+}
+
+func (m *MockSuppressionStorage) Suppressed(ctx context.Context, emails []string) (map[string]bool, error) {
+	return m.SuppressedFn(ctx, emails)
+}
+
+type MockUnitOfWork struct {
+	InTxFn func(ctx context.Context, fn func(stores Stores) error) error
+}
+
+func (m *MockUnitOfWork) InTx(ctx context.Context, fn func(stores Stores) error) error {
+	return m.InTxFn(ctx, fn)
+}
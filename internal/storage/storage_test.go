@@ -3,9 +3,13 @@ package storage_test
 import (
 	"context"
 	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
 	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
 	"github.com/serjnester/weather-subscription-service/internal/storage"
+	"github.com/serjnester/weather-subscription-service/internal/storage/sqlite"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -71,37 +75,148 @@ func TestSubscriptionStorage(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	subStorage := storage.NewSubscriptionStorage(storage.New(db))
+	testSubscriptionStorage(t, storage.NewSubscriptionStorage(storage.New(db)))
+}
 
-	ctx := context.Background()
-	sub := models.Subscription{
-		Email:     "test@example.com",
-		City:      "Kyiv",
-		Token:     "abc123",
-		Frequency: "daily",
-	}
+func TestMemoryStorage(t *testing.T) {
+	t.Run("subscriptions", func(t *testing.T) {
+		testSubscriptionStorage(t, storage.NewMemorySubscriptionStorage(storage.NewMemoryDB()))
+	})
+	t.Run("api keys", func(t *testing.T) {
+		testAPIKeyStorage(t, storage.NewMemoryAPIKeyStorage(storage.NewMemoryDB()))
+	})
+	t.Run("webhook attempts", func(t *testing.T) {
+		db := storage.NewMemoryDB()
+		testWebhookAttemptStorage(t, storage.NewMemorySubscriptionStorage(db), storage.NewMemoryWebhookAttemptStorage(db))
+	})
+	t.Run("schedule", func(t *testing.T) {
+		db := storage.NewMemoryDB()
+		testScheduleStorage(t, storage.NewMemorySubscriptionStorage(db), storage.NewMemoryScheduleStorage(db))
+	})
+	t.Run("deliveries", func(t *testing.T) {
+		db := storage.NewMemoryDB()
+		testDeliveryStorage(t, storage.NewMemorySubscriptionStorage(db), storage.NewMemoryDeliveryStorage(db))
+	})
+	t.Run("suppressions", func(t *testing.T) {
+		testSuppressionStorage(t, storage.NewMemorySuppressionStorage(storage.NewMemoryDB()))
+	})
+	t.Run("unit of work", func(t *testing.T) {
+		db := storage.NewMemoryDB()
+		testUnitOfWork(t, storage.NewMemoryUnitOfWork(db), storage.NewMemorySubscriptionStorage(db))
+	})
+}
 
-	// Insert
-	err := subStorage.Create(ctx, sub)
-	require.NoError(t, err)
+func TestAPIKeyStorage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
 
-	// GetByToken
-	got, err := subStorage.GetByToken(ctx, sub.Token)
-	require.NoError(t, err)
-	require.Equal(t, sub.Email, got.Email)
+	testAPIKeyStorage(t, storage.NewAPIKeyStorage(storage.New(db)))
+}
+
+func TestWebhookAttemptStorage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	q := storage.New(db)
+	testWebhookAttemptStorage(t, storage.NewSubscriptionStorage(q), storage.NewWebhookAttemptStorage(q))
+}
+
+func TestScheduleStorage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	q := storage.New(db)
+	testScheduleStorage(t, storage.NewSubscriptionStorage(q), storage.NewScheduleStorage(q))
+}
+
+func TestDeliveryStorage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	q := storage.New(db)
+	testDeliveryStorage(t, storage.NewSubscriptionStorage(q), storage.NewDeliveryStorage(q))
+}
+
+func TestSuppressionStorage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
 
-	// Confirm
-	err = subStorage.Confirm(ctx, sub.Token)
+	testSuppressionStorage(t, storage.NewSuppressionStorage(storage.New(db)))
+}
+
+func TestUnitOfWork(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	testUnitOfWork(t, storage.NewUnitOfWork(db), storage.NewSubscriptionStorage(storage.New(db)))
+}
+
+func setupSQLite(t *testing.T) *sqlite.Queries {
+	return sqlite.New(openSQLite(t))
+}
+
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sqlite.Open(context.Background(), configs.DB{
+		Path:         filepath.Join(t.TempDir(), "weather.db"),
+		MaxOpenConns: 5,
+		MaxIdleConns: 5,
+	})
 	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
 
-	confirmed, err := subStorage.GetByToken(ctx, sub.Token)
+func TestSQLiteStorage(t *testing.T) {
+	t.Run("subscriptions", func(t *testing.T) {
+		testSubscriptionStorage(t, sqlite.NewSubscriptionStorage(setupSQLite(t)))
+	})
+	t.Run("api keys", func(t *testing.T) {
+		testAPIKeyStorage(t, sqlite.NewAPIKeyStorage(setupSQLite(t)))
+	})
+	t.Run("webhook attempts", func(t *testing.T) {
+		q := setupSQLite(t)
+		testWebhookAttemptStorage(t, sqlite.NewSubscriptionStorage(q), sqlite.NewWebhookAttemptStorage(q))
+	})
+	t.Run("schedule", func(t *testing.T) {
+		q := setupSQLite(t)
+		testScheduleStorage(t, sqlite.NewSubscriptionStorage(q), sqlite.NewScheduleStorage(q))
+	})
+	t.Run("deliveries", func(t *testing.T) {
+		q := setupSQLite(t)
+		testDeliveryStorage(t, sqlite.NewSubscriptionStorage(q), sqlite.NewDeliveryStorage(q))
+	})
+	t.Run("suppressions", func(t *testing.T) {
+		testSuppressionStorage(t, sqlite.NewSuppressionStorage(setupSQLite(t)))
+	})
+	t.Run("unit of work", func(t *testing.T) {
+		db := openSQLite(t)
+		testUnitOfWork(t, sqlite.NewUnitOfWork(db), sqlite.NewSubscriptionStorage(sqlite.New(db)))
+	})
+}
+
+func TestSQLiteMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weather.db")
+	conf := configs.DB{Path: path, MaxOpenConns: 1}
+
+	db, err := sqlite.Open(context.Background(), conf)
 	require.NoError(t, err)
-	require.True(t, confirmed.Confirmed)
+	_, err = sqlite.NewSubscriptionStorage(sqlite.New(db)).Create(context.Background(), models.Subscription{
+		Channel: enums.ChannelEmail, Contact: "test@example.com", City: "Kyiv", Token: "token",
+		Frequency: enums.FrequencyDaily, Units: enums.UnitsMetric, Lang: enums.LanguageEnglish,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
 
-	// Unsubscribe
-	err = subStorage.Unsubscribe(ctx, sub.Token)
+	// Reopening applies nothing twice and keeps the data.
+	db, err = sqlite.Open(context.Background(), conf)
 	require.NoError(t, err)
+	defer db.Close()
+
+	var version int
+	require.NoError(t, db.QueryRow("PRAGMA user_version").Scan(&version))
+	require.Equal(t, 1, version)
 
-	_, err = subStorage.GetByToken(ctx, sub.Token)
-	require.Error(t, err)
+	_, err = sqlite.NewSubscriptionStorage(sqlite.New(db)).GetByToken(context.Background(), "token")
+	require.NoError(t, err)
 }
@@ -7,6 +7,8 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	enums "github.com/serjnester/weather-subscription-service/domain/enums"
 )
@@ -23,40 +25,84 @@ func (q *Queries) ConfirmSubscription(ctx context.Context, token string) error {
 }
 
 const createSubscription = `-- name: CreateSubscription :one
-INSERT INTO subscriptions (email, city, frequency, token)
-VALUES ($1, $2, $3, $4)
-    RETURNING id, email, city, frequency, confirmed, token, created_at
+INSERT INTO subscriptions (channel, contact, city, frequency, token, units, lang, confirmed, webhook_secret)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at
 `
 
 type CreateSubscriptionParams struct {
-	Email     string
-	City      string
-	Frequency enums.Frequency
-	Token     string
+	Channel       enums.Channel
+	Contact       string
+	City          string
+	Frequency     enums.Frequency
+	Token         string
+	Units         enums.Units
+	Lang          enums.Language
+	Confirmed     bool
+	WebhookSecret sql.NullString
 }
 
 func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
 	row := q.db.QueryRowContext(ctx, createSubscription,
-		arg.Email,
+		arg.Channel,
+		arg.Contact,
 		arg.City,
 		arg.Frequency,
 		arg.Token,
+		arg.Units,
+		arg.Lang,
+		arg.Confirmed,
+		arg.WebhookSecret,
 	)
 	var i Subscription
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
+		&i.Contact,
 		&i.City,
 		&i.Frequency,
 		&i.Confirmed,
 		&i.Token,
 		&i.CreatedAt,
+		&i.Units,
+		&i.Lang,
+		&i.Channel,
+		&i.WebhookSecret,
+		&i.NextRunAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const getSubscriptionByID = `-- name: GetSubscriptionByID :one
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) GetSubscriptionByID(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, getSubscriptionByID, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.Contact,
+		&i.City,
+		&i.Frequency,
+		&i.Confirmed,
+		&i.Token,
+		&i.CreatedAt,
+		&i.Units,
+		&i.Lang,
+		&i.Channel,
+		&i.WebhookSecret,
+		&i.NextRunAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
 	)
 	return i, err
 }
 
 const getSubscriptionByToken = `-- name: GetSubscriptionByToken :one
-SELECT id, email, city, frequency, confirmed, token, created_at FROM subscriptions
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
 WHERE token = $1
 `
 
@@ -65,33 +111,184 @@ func (q *Queries) GetSubscriptionByToken(ctx context.Context, token string) (Sub
 	var i Subscription
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
+		&i.Contact,
 		&i.City,
 		&i.Frequency,
 		&i.Confirmed,
 		&i.Token,
 		&i.CreatedAt,
+		&i.Units,
+		&i.Lang,
+		&i.Channel,
+		&i.WebhookSecret,
+		&i.NextRunAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
 	)
 	return i, err
 }
 
 const isAlreadySubscribed = `-- name: IsAlreadySubscribed :one
 SELECT COUNT(*) FROM subscriptions
-WHERE email = $1 AND city = $2
+WHERE channel = $1 AND contact = $2 AND city = $3
 `
 
 type IsAlreadySubscribedParams struct {
-	Email string
-	City  string
+	Channel enums.Channel
+	Contact string
+	City    string
 }
 
 func (q *Queries) IsAlreadySubscribed(ctx context.Context, arg IsAlreadySubscribedParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, isAlreadySubscribed, arg.Email, arg.City)
+	row := q.db.QueryRowContext(ctx, isAlreadySubscribed, arg.Channel, arg.Contact, arg.City)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const listDueSubscriptions = `-- name: ListDueSubscriptions :many
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE confirmed AND next_run_at <= $1
+ORDER BY next_run_at, id
+LIMIT $2
+`
+
+type ListDueSubscriptionsParams struct {
+	Now       time.Time
+	BatchSize int32
+}
+
+func (q *Queries) ListDueSubscriptions(ctx context.Context, arg ListDueSubscriptionsParams) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, listDueSubscriptions, arg.Now, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionsByContact = `-- name: ListSubscriptionsByContact :many
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE channel = $1 AND contact = $2
+ORDER BY id
+`
+
+type ListSubscriptionsByContactParams struct {
+	Channel enums.Channel
+	Contact string
+}
+
+func (q *Queries) ListSubscriptionsByContact(ctx context.Context, arg ListSubscriptionsByContactParams) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, listSubscriptionsByContact, arg.Channel, arg.Contact)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionsByEmail = `-- name: ListSubscriptionsByEmail :many
+SELECT id, contact, city, frequency, confirmed, token, created_at, units, lang, channel, webhook_secret, next_run_at, lease_owner, lease_expires_at FROM subscriptions
+WHERE channel = 'email' AND lower(contact) = lower($1)
+ORDER BY id
+`
+
+func (q *Queries) ListSubscriptionsByEmail(ctx context.Context, email string) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, listSubscriptionsByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Contact,
+			&i.City,
+			&i.Frequency,
+			&i.Confirmed,
+			&i.Token,
+			&i.CreatedAt,
+			&i.Units,
+			&i.Lang,
+			&i.Channel,
+			&i.WebhookSecret,
+			&i.NextRunAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const unsubscribe = `-- name: Unsubscribe :exec
 DELETE FROM subscriptions
 WHERE token = $1
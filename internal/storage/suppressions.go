@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"strings"
+)
+
+// SuppressionStorage keeps the email suppression list. Addresses are
+// compared case-insensitively.
+type SuppressionStorage interface {
+	Suppress(ctx context.Context, s models.Suppression) error
+	List(ctx context.Context, limit int) ([]models.Suppression, error)
+	// Remove returns sql.ErrNoRows if the address is not suppressed.
+	Remove(ctx context.Context, email string) error
+	// Suppressed returns the lowercased addresses among emails that are
+	// suppressed.
+	Suppressed(ctx context.Context, emails []string) (map[string]bool, error)
+}
+
+var _ SuppressionStorage = (*SuppressionImpl)(nil)
+
+type SuppressionImpl struct {
+	q *Queries
+}
+
+func NewSuppressionStorage(q *Queries) *SuppressionImpl {
+	return &SuppressionImpl{q: q}
+}
+
+func (s *SuppressionImpl) Suppress(ctx context.Context, sup models.Suppression) error {
+	err := s.q.UpsertSuppression(ctx, UpsertSuppressionParams{
+		Email:  sup.Email,
+		Reason: sup.Reason,
+		Detail: sql.NullString{String: sup.Detail, Valid: sup.Detail != ""},
+	})
+	return mapError(err)
+}
+
+func (s *SuppressionImpl) List(ctx context.Context, limit int) ([]models.Suppression, error) {
+	rows, err := s.q.ListSuppressions(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	suppressions := make([]models.Suppression, 0, len(rows))
+	for _, row := range rows {
+		suppressions = append(suppressions, models.Suppression{
+			Email:     row.Email,
+			Reason:    row.Reason,
+			Detail:    row.Detail.String,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return suppressions, nil
+}
+
+func (s *SuppressionImpl) Remove(ctx context.Context, email string) error {
+	affected, err := s.q.DeleteSuppression(ctx, email)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *SuppressionImpl) Suppressed(ctx context.Context, emails []string) (map[string]bool, error) {
+	if len(emails) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	lower := make([]string, 0, len(emails))
+	for _, email := range emails {
+		lower = append(lower, strings.ToLower(email))
+	}
+
+	rows, err := s.q.SuppressedEmails(ctx, lower)
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed := make(map[string]bool, len(rows))
+	for _, email := range rows {
+		suppressed[email] = true
+	}
+	return suppressed, nil
+}
@@ -0,0 +1,111 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: suppressions.sql
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	enums "github.com/serjnester/weather-subscription-service/domain/enums"
+)
+
+const deleteSuppression = `-- name: DeleteSuppression :execrows
+DELETE FROM email_suppressions
+WHERE email = lower($1::text)
+`
+
+func (q *Queries) DeleteSuppression(ctx context.Context, email string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSuppression, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listSuppressions = `-- name: ListSuppressions :many
+SELECT email, reason, detail, created_at FROM email_suppressions
+ORDER BY created_at DESC, email
+LIMIT $1
+`
+
+func (q *Queries) ListSuppressions(ctx context.Context, limit int32) ([]EmailSuppression, error) {
+	rows, err := q.db.QueryContext(ctx, listSuppressions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EmailSuppression
+	for rows.Next() {
+		var i EmailSuppression
+		if err := rows.Scan(
+			&i.Email,
+			&i.Reason,
+			&i.Detail,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const suppressedEmails = `-- name: SuppressedEmails :many
+SELECT email FROM email_suppressions
+WHERE email = ANY($1::text[])
+`
+
+// Returns the lowercased emails among the given ones that are suppressed.
+func (q *Queries) SuppressedEmails(ctx context.Context, emails []string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, suppressedEmails, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSuppression = `-- name: UpsertSuppression :exec
+INSERT INTO email_suppressions (email, reason, detail)
+VALUES (lower($1::text), $2, $3)
+ON CONFLICT (email) DO UPDATE
+SET reason = EXCLUDED.reason,
+    detail = EXCLUDED.detail
+WHERE email_suppressions.reason <> 'complaint'
+`
+
+type UpsertSuppressionParams struct {
+	Email  string
+	Reason enums.SuppressionReason
+	Detail sql.NullString
+}
+
+// Emails are stored lowercased. A complaint overrides an earlier bounce.
+func (q *Queries) UpsertSuppression(ctx context.Context, arg UpsertSuppressionParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSuppression, arg.Email, arg.Reason, arg.Detail)
+	return err
+}
@@ -1,10 +1,73 @@
 CREATE TABLE subscriptions (
     id SERIAL PRIMARY KEY,
-    email TEXT NOT NULL,
+    contact TEXT NOT NULL,
     city TEXT NOT NULL,
     frequency TEXT NOT NULL CHECK (frequency IN ('hourly', 'daily')),
     confirmed BOOLEAN NOT NULL DEFAULT FALSE,
     token TEXT NOT NULL UNIQUE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    UNIQUE(email, city)
+    units TEXT NOT NULL DEFAULT 'metric' CHECK (units IN ('metric', 'imperial')),
+    lang TEXT NOT NULL DEFAULT 'en',
+    channel TEXT NOT NULL DEFAULT 'email',
+    webhook_secret TEXT,
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    lease_owner TEXT,
+    lease_expires_at TIMESTAMPTZ,
+    CONSTRAINT subscriptions_channel_check CHECK (channel IN ('email', 'telegram', 'webhook')),
+    CONSTRAINT subscriptions_channel_contact_city_key UNIQUE (channel, contact, city)
+);
+
+CREATE INDEX subscriptions_due_idx ON subscriptions (next_run_at) WHERE confirmed;
+
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    daily_quota INTEGER NOT NULL DEFAULT 0 CHECK (daily_quota >= 0),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE api_key_usage (
+    api_key_id INTEGER NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
+
+CREATE TABLE webhook_delivery_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    delivery_id TEXT NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_delivery_attempts_subscription_id_idx
+    ON webhook_delivery_attempts (subscription_id, created_at DESC);
+
+CREATE TABLE deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed')),
+    provider_message_id TEXT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX deliveries_subscription_id_idx ON deliveries (subscription_id, id DESC);
+
+CREATE TABLE email_suppressions (
+    email TEXT PRIMARY KEY,
+    reason TEXT NOT NULL CHECK (reason IN ('hard_bounce', 'complaint')),
+    detail TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
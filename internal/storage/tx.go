@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Stores are the storages of one database, or of one transaction.
+type Stores struct {
+	Subscriptions   SubscriptionStorage
+	APIKeys         APIKeyStorage
+	Deliveries      DeliveryStorage
+	Suppressions    SuppressionStorage
+	Schedule        ScheduleStorage
+	WebhookAttempts WebhookAttemptStorage
+}
+
+func NewStores(q *Queries) Stores {
+	return Stores{
+		Subscriptions:   NewSubscriptionStorage(q),
+		APIKeys:         NewAPIKeyStorage(q),
+		Deliveries:      NewDeliveryStorage(q),
+		Suppressions:    NewSuppressionStorage(q),
+		Schedule:        NewScheduleStorage(q),
+		WebhookAttempts: NewWebhookAttemptStorage(q),
+	}
+}
+
+// UnitOfWork runs a group of storage calls in one transaction.
+type UnitOfWork interface {
+	// InTx calls fn with stores bound to a new transaction, which is
+	// committed if fn returns nil and rolled back otherwise. The error of
+	// fn is returned unchanged.
+	InTx(ctx context.Context, fn func(stores Stores) error) error
+}
+
+var _ UnitOfWork = (*UnitOfWorkImpl)(nil)
+
+type UnitOfWorkImpl struct {
+	db *sql.DB
+	q  *Queries
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWorkImpl {
+	return &UnitOfWorkImpl{db: db, q: New(db)}
+}
+
+func (u *UnitOfWorkImpl) InTx(ctx context.Context, fn func(stores Stores) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("[UnitOfWork.InTx] begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(NewStores(u.q.WithTx(tx))); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("[UnitOfWork.InTx] commit: %w", mapError(err))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"time"
+)
+
+type WebhookAttemptStorage interface {
+	LogAttempt(ctx context.Context, attempt models.WebhookAttempt) error
+	// ListAttempts returns the latest attempts for a subscription, newest first.
+	ListAttempts(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookAttempt, error)
+}
+
+var _ WebhookAttemptStorage = (*WebhookAttemptImpl)(nil)
+
+type WebhookAttemptImpl struct {
+	q *Queries
+}
+
+func NewWebhookAttemptStorage(q *Queries) *WebhookAttemptImpl {
+	return &WebhookAttemptImpl{q: q}
+}
+
+func (s *WebhookAttemptImpl) LogAttempt(ctx context.Context, a models.WebhookAttempt) error {
+	err := s.q.LogWebhookAttempt(ctx, LogWebhookAttemptParams{
+		SubscriptionID: int32(a.SubscriptionID),
+		DeliveryID:     a.DeliveryID,
+		Attempt:        int32(a.Attempt),
+		StatusCode:     sql.NullInt32{Int32: int32(a.StatusCode), Valid: a.StatusCode != 0},
+		Error:          sql.NullString{String: a.Error, Valid: a.Error != ""},
+		DurationMs:     int32(a.Duration.Milliseconds()),
+	})
+	return mapError(err)
+}
+
+func (s *WebhookAttemptImpl) ListAttempts(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookAttempt, error) {
+	rows, err := s.q.ListWebhookAttempts(ctx, ListWebhookAttemptsParams{
+		SubscriptionID: int32(subscriptionID),
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]models.WebhookAttempt, 0, len(rows))
+	for _, row := range rows {
+		attempts = append(attempts, models.WebhookAttempt{
+			ID:             row.ID,
+			SubscriptionID: int64(row.SubscriptionID),
+			DeliveryID:     row.DeliveryID,
+			Attempt:        int(row.Attempt),
+			StatusCode:     int(row.StatusCode.Int32),
+			Error:          row.Error.String,
+			Duration:       time.Duration(row.DurationMs) * time.Millisecond,
+			CreatedAt:      row.CreatedAt,
+		})
+	}
+	return attempts, nil
+}
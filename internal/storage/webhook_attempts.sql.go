@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: webhook_attempts.sql
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+)
+
+const listWebhookAttempts = `-- name: ListWebhookAttempts :many
+SELECT id, subscription_id, delivery_id, attempt, status_code, error, duration_ms, created_at FROM webhook_delivery_attempts
+WHERE subscription_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type ListWebhookAttemptsParams struct {
+	SubscriptionID int32
+	Limit          int32
+}
+
+func (q *Queries) ListWebhookAttempts(ctx context.Context, arg ListWebhookAttemptsParams) ([]WebhookDeliveryAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookAttempts, arg.SubscriptionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDeliveryAttempt
+	for rows.Next() {
+		var i WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.DeliveryID,
+			&i.Attempt,
+			&i.StatusCode,
+			&i.Error,
+			&i.DurationMs,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logWebhookAttempt = `-- name: LogWebhookAttempt :exec
+INSERT INTO webhook_delivery_attempts (subscription_id, delivery_id, attempt, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type LogWebhookAttemptParams struct {
+	SubscriptionID int32
+	DeliveryID     string
+	Attempt        int32
+	StatusCode     sql.NullInt32
+	Error          sql.NullString
+	DurationMs     int32
+}
+
+func (q *Queries) LogWebhookAttempt(ctx context.Context, arg LogWebhookAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, logWebhookAttempt,
+		arg.SubscriptionID,
+		arg.DeliveryID,
+		arg.Attempt,
+		arg.StatusCode,
+		arg.Error,
+		arg.DurationMs,
+	)
+	return err
+}
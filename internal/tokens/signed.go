@@ -0,0 +1,160 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minKeyLength is the minimum HMAC-SHA256 secret length in bytes.
+const minKeyLength = 32
+
+// Key is an HMAC secret identified in token headers by ID.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseKeys parses "id:secret" pairs.
+func ParseKeys(pairs []string) ([]Key, error) {
+	keys := make([]Key, 0, len(pairs))
+	for _, pair := range pairs {
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" {
+			return nil, errors.New("token key must be in the id:secret format")
+		}
+		keys = append(keys, Key{ID: id, Secret: []byte(secret)})
+	}
+	return keys, nil
+}
+
+var _ Manager = (*SignedManager)(nil)
+
+// SignedManager issues stateless tokens in the JWT compact format, signed
+// with HS256 and carrying the purpose, the subscription id and the expiry.
+// New tokens are signed with the first key; any key verifies, so a key is
+// rotated by putting a new one first and dropping the old one once the
+// tokens it signed have expired.
+type SignedManager struct {
+	signing Key
+	keys    map[string][]byte
+	ttls    map[enums.TokenPurpose]time.Duration
+	now     func() time.Time
+}
+
+// NewSignedManager creates a manager issuing tokens that expire after the
+// TTL of their purpose; a zero TTL means no expiry.
+func NewSignedManager(keys []Key, ttls map[enums.TokenPurpose]time.Duration) (*SignedManager, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("signed tokens require at least one key")
+	}
+
+	m := &SignedManager{
+		signing: keys[0],
+		keys:    make(map[string][]byte, len(keys)),
+		ttls:    ttls,
+		now:     time.Now,
+	}
+	for _, key := range keys {
+		if len(key.Secret) < minKeyLength {
+			return nil, fmt.Errorf("token key %q must be at least %d bytes", key.ID, minKeyLength)
+		}
+		if _, ok := m.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate token key %q", key.ID)
+		}
+		m.keys[key.ID] = key.Secret
+	}
+	return m, nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid"`
+}
+
+type payload struct {
+	Sub string             `json:"sub"`
+	Pur enums.TokenPurpose `json:"pur"`
+	Exp int64              `json:"exp,omitempty"`
+}
+
+const algorithm = "HS256"
+
+var encoding = base64.RawURLEncoding
+
+func (m *SignedManager) Issue(sub models.Subscription, purpose enums.TokenPurpose) string {
+	claims := payload{Sub: strconv.FormatInt(sub.ID, 10), Pur: purpose}
+	if ttl := m.ttls[purpose]; ttl > 0 {
+		// Rounding up to the hour keeps the token stable for an hour, so
+		// content embedding it, such as feeds, stays cacheable.
+		claims.Exp = m.now().Add(ttl).Truncate(time.Hour).Add(time.Hour).Unix()
+	}
+
+	// Neither struct can fail to marshal.
+	h, _ := json.Marshal(header{Alg: algorithm, Typ: "JWT", Kid: m.signing.ID})
+	p, _ := json.Marshal(claims)
+
+	signed := encoding.EncodeToString(h) + "." + encoding.EncodeToString(p)
+	return signed + "." + encoding.EncodeToString(sign(m.signing.Secret, signed))
+}
+
+func (m *SignedManager) Parse(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var h header
+	if err := decodePart(parts[0], &h); err != nil || h.Alg != algorithm {
+		return Claims{}, ErrInvalidToken
+	}
+	secret, ok := m.keys[h.Kid]
+	if !ok {
+		return Claims{}, fmt.Errorf("%w: unknown key", ErrInvalidToken)
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0]+"."+parts[1])) {
+		return Claims{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var p payload
+	if err := decodePart(parts[1], &p); err != nil || !p.Pur.IsValid() {
+		return Claims{}, ErrInvalidToken
+	}
+	id, err := strconv.ParseInt(p.Sub, 10, 64)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	claims := Claims{Purpose: p.Pur, SubscriptionID: id}
+	if p.Exp != 0 {
+		claims.ExpiresAt = time.Unix(p.Exp, 0)
+		if !m.now().Before(claims.ExpiresAt) {
+			return Claims{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+		}
+	}
+	return claims, nil
+}
+
+func decodePart(part string, v any) error {
+	data, err := encoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func sign(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
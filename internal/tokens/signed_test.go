@@ -0,0 +1,134 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = Key{ID: "2025-01", Secret: []byte(strings.Repeat("a", 32))}
+	newKey = Key{ID: "2025-06", Secret: []byte(strings.Repeat("b", 32))}
+)
+
+func newTestManager(t *testing.T, now time.Time, keys ...Key) *SignedManager {
+	m, err := NewSignedManager(keys, map[enums.TokenPurpose]time.Duration{
+		enums.TokenPurposeConfirm:     24 * time.Hour,
+		enums.TokenPurposeUnsubscribe: 30 * 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func TestSignedManager(t *testing.T) {
+	now := time.Date(2025, 5, 20, 8, 30, 0, 0, time.UTC)
+	sub := models.Subscription{ID: 42, Token: "stored"}
+
+	t.Run("round trip", func(t *testing.T) {
+		m := newTestManager(t, now, oldKey)
+
+		claims, err := m.Parse(m.Issue(sub, enums.TokenPurposeConfirm))
+		require.NoError(t, err)
+		require.Equal(t, Claims{
+			Purpose:        enums.TokenPurposeConfirm,
+			SubscriptionID: 42,
+			ExpiresAt:      time.Date(2025, 5, 21, 9, 0, 0, 0, time.UTC).Local(),
+		}, claims)
+		require.True(t, claims.Grants(enums.TokenPurposeConfirm))
+		require.False(t, claims.Grants(enums.TokenPurposeUnsubscribe))
+	})
+
+	t.Run("without ttl the token does not expire", func(t *testing.T) {
+		m := newTestManager(t, now, oldKey)
+		token := m.Issue(sub, enums.TokenPurposeManage)
+
+		m.now = func() time.Time { return now.AddDate(10, 0, 0) }
+		claims, err := m.Parse(token)
+		require.NoError(t, err)
+		require.True(t, claims.ExpiresAt.IsZero())
+		require.True(t, claims.Grants(enums.TokenPurposeUnsubscribe))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		m := newTestManager(t, now, oldKey)
+		token := m.Issue(sub, enums.TokenPurposeConfirm)
+
+		m.now = func() time.Time { return now.Add(25 * time.Hour) }
+		_, err := m.Parse(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("stable within the hour", func(t *testing.T) {
+		m := newTestManager(t, now, oldKey)
+		token := m.Issue(sub, enums.TokenPurposeUnsubscribe)
+
+		m.now = func() time.Time { return now.Add(20 * time.Minute) }
+		require.Equal(t, token, m.Issue(sub, enums.TokenPurposeUnsubscribe))
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		issuedWithOld := newTestManager(t, now, oldKey).Issue(sub, enums.TokenPurposeUnsubscribe)
+
+		rotated := newTestManager(t, now, newKey, oldKey)
+		_, err := rotated.Parse(issuedWithOld)
+		require.NoError(t, err)
+
+		issuedWithNew := rotated.Issue(sub, enums.TokenPurposeUnsubscribe)
+		_, err = newTestManager(t, now, newKey).Parse(issuedWithNew)
+		require.NoError(t, err)
+
+		_, err = newTestManager(t, now, newKey).Parse(issuedWithOld)
+		require.ErrorIs(t, err, ErrInvalidToken, "retired key")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		m := newTestManager(t, now, oldKey)
+		parts := strings.Split(m.Issue(sub, enums.TokenPurposeConfirm), ".")
+
+		other := strings.Split(m.Issue(models.Subscription{ID: 43}, enums.TokenPurposeManage), ".")
+
+		for _, token := range []string{
+			"",
+			"stored",
+			parts[0] + "." + other[1] + "." + parts[2],
+			parts[0] + "." + parts[1] + ".",
+			parts[0] + "." + parts[1] + "." + strings.Repeat("A", 43),
+			newTestManager(t, now, Key{ID: oldKey.ID, Secret: []byte(strings.Repeat("c", 32))}).Issue(sub, enums.TokenPurposeConfirm),
+		} {
+			_, err := m.Parse(token)
+			require.ErrorIs(t, err, ErrInvalidToken, token)
+		}
+	})
+}
+
+func TestNewManager(t *testing.T) {
+	m, err := NewManager(configs.Tokens{Strategy: StrategyRandom})
+	require.NoError(t, err)
+	require.Equal(t, "stored", m.Issue(models.Subscription{ID: 1, Token: "stored"}, enums.TokenPurposeConfirm))
+	claims, err := m.Parse("stored")
+	require.NoError(t, err)
+	require.Equal(t, Claims{Token: "stored"}, claims)
+	require.True(t, claims.Grants(enums.TokenPurposeConfirm))
+
+	_, err = NewManager(configs.Tokens{Strategy: StrategySigned, Keys: []string{"k1:short"}})
+	require.Error(t, err)
+
+	_, err = NewManager(configs.Tokens{Strategy: StrategySigned})
+	require.Error(t, err)
+
+	_, err = NewManager(configs.Tokens{Strategy: StrategySigned, Keys: []string{"k1:" + strings.Repeat("x", 32), "k1:" + strings.Repeat("y", 32)}})
+	require.Error(t, err, "duplicate key id")
+
+	_, err = NewManager(configs.Tokens{Strategy: "jwt"})
+	require.Error(t, err)
+
+	m, err = NewManager(configs.Tokens{Strategy: StrategySigned, Keys: []string{"k1:" + strings.Repeat("x", 32)}})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(strings.Split(m.Issue(models.Subscription{ID: 1}, enums.TokenPurposeManage), ".")))
+}
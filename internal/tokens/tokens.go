@@ -0,0 +1,83 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"github.com/serjnester/weather-subscription-service/domain/enums"
+	"github.com/serjnester/weather-subscription-service/domain/models"
+	"github.com/serjnester/weather-subscription-service/internal/configs"
+	"time"
+)
+
+// Manager issues the tokens handed out in links and API responses and
+// resolves them back to a subscription.
+type Manager interface {
+	Issue(sub models.Subscription, purpose enums.TokenPurpose) string
+	Parse(token string) (Claims, error)
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims is what a token grants. A signed token identifies its subscription
+// by SubscriptionID, a random one is the stored subscription Token.
+type Claims struct {
+	// Purpose is empty for random tokens, which grant every action.
+	Purpose        enums.TokenPurpose
+	SubscriptionID int64
+	Token          string
+	// ExpiresAt is zero for tokens that do not expire.
+	ExpiresAt time.Time
+}
+
+// Grants reports whether the token authorizes purpose. A manage token also
+// authorizes unsubscribing.
+func (c Claims) Grants(purpose enums.TokenPurpose) bool {
+	return c.Purpose == "" || c.Purpose == purpose ||
+		(c.Purpose == enums.TokenPurposeManage && purpose == enums.TokenPurposeUnsubscribe)
+}
+
+const (
+	StrategyRandom = "random"
+	StrategySigned = "signed"
+)
+
+// NewManager creates the manager selected by cfg.Strategy.
+func NewManager(cfg configs.Tokens) (Manager, error) {
+	switch cfg.Strategy {
+	case StrategyRandom, "":
+		return NewRandomManager(), nil
+	case StrategySigned:
+		keys, err := ParseKeys(cfg.Keys)
+		if err != nil {
+			return nil, err
+		}
+		return NewSignedManager(keys, map[enums.TokenPurpose]time.Duration{
+			enums.TokenPurposeConfirm:     cfg.ConfirmTTL,
+			enums.TokenPurposeUnsubscribe: cfg.UnsubscribeTTL,
+			enums.TokenPurposeManage:      cfg.ManageTTL,
+		})
+	}
+	return nil, fmt.Errorf("unknown token strategy %q", cfg.Strategy)
+}
+
+var _ Manager = (*RandomManager)(nil)
+
+// RandomManager hands out the random token stored with the subscription for
+// every purpose. Such tokens never expire and are revoked only by deleting
+// the subscription.
+type RandomManager struct{}
+
+func NewRandomManager() *RandomManager {
+	return &RandomManager{}
+}
+
+func (m *RandomManager) Issue(sub models.Subscription, _ enums.TokenPurpose) string {
+	return sub.Token
+}
+
+func (m *RandomManager) Parse(token string) (Claims, error) {
+	if token == "" {
+		return Claims{}, ErrInvalidToken
+	}
+	return Claims{Token: token}, nil
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/url"
+)
+
+const restyTracerName = "github.com/serjnester/weather-subscription-service/internal/tracing/resty"
+
+// attemptKey marks a request context that already carries a client span,
+// holding the context the span was started from.
+type attemptKey struct{}
+
+// InstrumentResty starts a client span for every request made by c and
+// propagates the trace context in the request headers. Only the method,
+// host, path and status are recorded: query strings carry API keys and
+// user input.
+func InstrumentResty(c *resty.Client) *resty.Client {
+	tracer := otel.Tracer(restyTracerName)
+
+	return c.
+		OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+			ctx := r.Context()
+			// A retry starts a new attempt from the original context.
+			if parent, ok := ctx.Value(attemptKey{}).(context.Context); ok {
+				trace.SpanFromContext(ctx).End()
+				ctx = parent
+			}
+
+			u, err := url.Parse(r.URL)
+			if err != nil {
+				u = &url.URL{}
+			}
+			spanCtx, _ := tracer.Start(ctx, r.Method+" "+u.Path,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.ServerAddress(u.Hostname()),
+					semconv.URLPath(u.Path),
+				),
+			)
+			otel.GetTextMapPropagator().Inject(spanCtx, propagation.HeaderCarrier(r.Header))
+			r.SetContext(context.WithValue(spanCtx, attemptKey{}, ctx))
+			return nil
+		}).
+		OnAfterResponse(func(_ *resty.Client, resp *resty.Response) error {
+			span := trace.SpanFromContext(resp.Request.Context())
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode()))
+			if resp.StatusCode() >= 500 {
+				span.SetStatus(codes.Error, resp.Status())
+			}
+			span.End()
+			return nil
+		}).
+		OnError(func(r *resty.Request, err error) {
+			err = withoutQuery(err)
+			span := trace.SpanFromContext(r.Context())
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
+		})
+}
+
+// withoutQuery strips the query from the URL quoted by transport errors.
+func withoutQuery(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return urlErr.Err
+	}
+	u.RawQuery = ""
+	return &url.Error{Op: urlErr.Op, URL: u.String(), Err: urlErr.Err}
+}
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestInstrumentResty(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := InstrumentResty(resty.New()).R().SetContext(ctx).
+		SetQueryParam("key", "secret").
+		Get(server.URL + "/forecast.json")
+	parent.End()
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	require.Equal(t, "GET /forecast.json", span.Name())
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	require.Contains(t, traceparent, span.SpanContext().SpanID().String())
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusTeapot))
+	require.Contains(t, span.Attributes(), attribute.String("url.path", "/forecast.json"))
+	for _, attr := range span.Attributes() {
+		require.NotContains(t, attr.Value.Emit(), "secret")
+	}
+}
+
+func TestInstrumentResty_TransportError(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := InstrumentResty(resty.New()).R().
+		SetQueryParam("key", "secret").
+		Get(server.URL + "/forecast.json")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.NotContains(t, spans[0].Status().Description, "secret")
+	for _, event := range spans[0].Events() {
+		for _, attr := range event.Attributes {
+			require.NotContains(t, attr.Value.Emit(), "secret")
+		}
+	}
+}
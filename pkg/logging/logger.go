@@ -1,18 +1,58 @@
 package logging
 
 import (
+	"fmt"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"time"
 )
 
-func NewLogger() (*zap.Logger, error) {
-	cfg := zap.NewDevelopmentConfig()
-	cfg.Encoding = "json"
-	cfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+// Options configure NewLogger. Level is the initial level and Format is
+// "json" or "console". When SamplingInitial is set, only the first
+// SamplingInitial entries with the same level and message per second are
+// logged, and then every SamplingThereafter-th.
+type Options struct {
+	Level              string
+	Format             string
+	SamplingInitial    int
+	SamplingThereafter int
+}
+
+// NewLogger builds the application logger. The returned level controls
+// the logger at runtime. Every entry is passed through redactor (see
+// redactCore), which masks the secrets added to it wherever they appear in
+// messages and string fields.
+func NewLogger(opts Options, redactor *Redactor) (*zap.Logger, zap.AtomicLevel, error) {
+	level, err := zap.ParseAtomicLevel(opts.Level)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, fmt.Errorf("[logging.NewLogger] %w", err)
+	}
+
+	zapCfg := zap.NewProductionConfig()
+	zapCfg.Level = level
+	zapCfg.Sampling = nil
+	switch opts.Format {
+	case "json":
+		zapCfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	case "console":
+		zapCfg.Encoding = "console"
+		zapCfg.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	default:
+		return nil, zap.AtomicLevel{}, fmt.Errorf("[logging.NewLogger] unknown format %q", opts.Format)
+	}
 
-	logger, err := cfg.Build()
+	logger, err := zapCfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		core = newRedactCore(core, redactor)
+		if opts.SamplingInitial > 0 {
+			// The sampler must see each entry first, so it wraps the
+			// redacting core rather than the other way round.
+			core = zapcore.NewSamplerWithOptions(core, time.Second, opts.SamplingInitial, opts.SamplingThereafter)
+		}
+		return core
+	}))
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 
-	return logger, nil
+	return logger, level, nil
 }
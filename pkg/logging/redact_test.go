@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactCore(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(newRedactCore(core, NewRedactor("db-password", "wx-key", "")))
+
+	logger.With(zap.String("dsn", "postgres://user:db-password@db:5432/weather")).Info(
+		"sent to user@example.com",
+		zap.String("url", "https://api.weatherapi.com/v1/forecast.json?key=wx-key&q=Kyiv"),
+		zap.String("path", "/api/v2/unsubscribe/0123456789abcdef"),
+		zap.String("link", "/feeds/eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOjF9.c2ln.ics"),
+		zap.String("webhook_secret", "plain"),
+		zap.Int64("subscription_id", 3),
+		zap.Error(errors.New(`Get "https://example.com/?token=abc": dial tcp: timeout`)),
+		zap.String("request_id", "0123456789abcdef0123456789abcdef"),
+	)
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	require.Equal(t, "sent to u***@example.com", entries[0].Message)
+	require.Equal(t, map[string]any{
+		"dsn":             "postgres://[REDACTED]@db:5432/weather",
+		"url":             "https://api.weatherapi.com/v1/forecast.json?key=[REDACTED]&q=Kyiv",
+		"path":            "/api/v2/unsubscribe/[REDACTED]",
+		"link":            "/feeds/[REDACTED]",
+		"webhook_secret":  "[REDACTED]",
+		"subscription_id": int64(3),
+		"error":           `Get "https://example.com/?token=[REDACTED]": dial tcp: timeout`,
+		"request_id":      "0123456789abcdef0123456789abcdef",
+	}, entries[0].ContextMap())
+}
+
+func TestRedactor_KnownSecrets(t *testing.T) {
+	r := NewRedactor("db-password", "abc")
+	require.Equal(t, "auth failed for [REDACTED], abc kept", r.String("auth failed for db-password, abc kept"))
+
+	r.AddSecret("rotated-password")
+	require.Equal(t, "[REDACTED] replaced [REDACTED]", r.String("rotated-password replaced db-password"))
+}
+
+type nilStringer struct{ name string }
+
+func (s *nilStringer) String() string { return s.name }
+
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("boom") }
+
+func TestRedactCore_NilStringer(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(newRedactCore(core, NewRedactor()))
+
+	var stringer *nilStringer
+	var err *nilError
+	require.NotPanics(t, func() {
+		logger.Info("nil values",
+			zap.Stringer("stringer", stringer),
+			zap.Stringer("panics", panicStringer{}),
+			zap.NamedError("error", err),
+		)
+	})
+
+	require.Equal(t, map[string]any{
+		"stringer": "<nil>",
+		"panics":   "PANIC=boom",
+		"error":    "<nil>",
+	}, logs.TakeAll()[0].ContextMap())
+}